@@ -0,0 +1,18 @@
+package check
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check captures against the expectations of DBC files.",
+	}
+
+	cmd.AddCommand(
+		newTimingCommand(),
+	)
+
+	return cmd
+}
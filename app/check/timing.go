@@ -0,0 +1,283 @@
+package check
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/BIwashi/candecode/pkg/capture"
+	"github.com/BIwashi/candecode/pkg/cli"
+	"github.com/BIwashi/candecode/pkg/dbc"
+	"github.com/BIwashi/candecode/pkg/timing"
+)
+
+type timingChecker struct {
+	dbcFile         string
+	dbcMappings     []string
+	inputFile       string
+	inputFormat     string
+	ascChannels     []string
+	timeoutFactor   float64
+	jitterTolerance float64
+	reportFile      string
+	strict          bool
+}
+
+func newTimingCommand() *cobra.Command {
+	s := &timingChecker{
+		dbcFile:         "",
+		dbcMappings:     nil,
+		inputFile:       "",
+		inputFormat:     capture.FormatAuto,
+		ascChannels:     nil,
+		timeoutFactor:   3,
+		jitterTolerance: 0.5,
+		reportFile:      "",
+		strict:          false,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "timing",
+		Short: "Check message inter-arrival times against the DBC cycle times.",
+		Long: `
+Check message inter-arrival times against the DBC cycle times (GenMsgCycleTime).
+
+Messages with a cycle time whose GenMsgSendType is not event driven are checked on every bus
+they are defined for:
+
+  - missing: the message never appears on a bus mapped to its DBC with --dbc bus=path, or on a
+    bus with other frames of that DBC (a default DBC is not expected on unrelated buses)
+  - timeout: no frame for longer than --timeout-factor times the cycle time, including the
+    time between the last frame and the end of the capture
+  - jitter: an interval deviates from the cycle time by more than --jitter-tolerance times the
+    cycle time (and is no timeout)
+
+A summary per message is printed; --report writes it with every violation as JSON.
+Use convert --check-timing to put the violations on the /diagnostics/timing channel of the MCAP output.`,
+		Example: `
+# Check a capture
+candecode check timing --dbc-file reference.dbc --pcapng-file capture.pcapng
+
+# Stricter limits, fail when anything is found
+candecode check timing --dbc-file reference.dbc --pcapng-file capture.pcapng --timeout-factor 2 --jitter-tolerance 0.2 --strict
+
+# Check a candump log
+candecode check timing --dbc-file reference.dbc --input-file candump-2023-10-11_160000.log
+
+# Per-bus DBC files and a JSON report
+candecode check timing --dbc can0=toyota_nodsu_pt.dbc --dbc can2=toyota_adas.dbc --pcapng-file capture.pcapng --report timing.json`,
+		RunE: cli.WithContext(s.run),
+	}
+
+	cmd.Flags().StringVar(&s.dbcFile, "dbc-file", s.dbcFile, "Default DBC file, used for buses without a --dbc mapping")
+	cmd.Flags().StringArrayVar(&s.dbcMappings, "dbc", s.dbcMappings, "Per-bus DBC file as bus=path (repeatable)")
+	cmd.Flags().StringVar(&s.inputFile, "input-file", s.inputFile, "Capture file: PCAPNG, candump log or Vector ASC trace")
+	cmd.Flags().StringVar(&s.inputFile, "pcapng-file", s.inputFile, "Capture file (alias of --input-file)")
+	cmd.Flags().StringVar(&s.inputFormat, "input-format", s.inputFormat,
+		"Format of the capture file. Available values: auto (detected from the contents), pcapng, candump, asc")
+	cmd.Flags().StringArrayVar(&s.ascChannels, "asc-channel", s.ascChannels,
+		"Bus name of a Vector ASC channel as channel=bus, e.g. 1=can0 (repeatable; default CAN<channel>)")
+	cmd.Flags().Float64Var(&s.timeoutFactor, "timeout-factor", s.timeoutFactor, "Report gaps longer than this many cycle times as timeouts")
+	cmd.Flags().Float64Var(&s.jitterTolerance, "jitter-tolerance", s.jitterTolerance,
+		"Report intervals deviating from the cycle time by more than this fraction of it as jitter")
+	cmd.Flags().StringVar(&s.reportFile, "report", s.reportFile, "Write the summary and every violation as JSON to this file")
+	cmd.Flags().BoolVar(&s.strict, "strict", s.strict, "Exit non-zero when any violation is found")
+
+	return cmd
+}
+
+func (s *timingChecker) run(ctx context.Context, input cli.Input) error {
+	if s.inputFile == "" {
+		return errors.New("--input-file (or --pcapng-file) is required")
+	}
+	if s.dbcFile == "" && len(s.dbcMappings) == 0 {
+		return errors.New("either --dbc-file or --dbc bus=path is required")
+	}
+	if s.timeoutFactor <= 1 {
+		return fmt.Errorf("invalid --timeout-factor %g, must be greater than 1", s.timeoutFactor)
+	}
+	if s.jitterTolerance <= 0 {
+		return fmt.Errorf("invalid --jitter-tolerance %g, must be positive", s.jitterTolerance)
+	}
+
+	decoder, _, err := dbc.LoadDecoder(s.dbcFile, s.dbcMappings)
+	if err != nil {
+		return err
+	}
+
+	inputFile, err := os.Open(s.inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer inputFile.Close() //nolint:errcheck
+
+	channels, err := capture.ParseChannels(s.ascChannels)
+	if err != nil {
+		return err
+	}
+	reader, inputFormat, err := capture.NewReader(bufio.NewReader(inputFile), append(channels, capture.WithFormat(s.inputFormat))...)
+	if err != nil {
+		return err
+	}
+
+	var (
+		checker = timing.NewChecker(decoder,
+			timing.WithTimeoutFactor(s.timeoutFactor),
+			timing.WithJitterTolerance(s.jitterTolerance),
+		)
+		report = &timingReport{
+			TimeoutFactor:   s.timeoutFactor,
+			JitterTolerance: s.jitterTolerance,
+			Messages:        []*timingMessage{},
+			Violations:      []*timingViolation{},
+		}
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "timing check cancelled")
+		default:
+		}
+
+		frame, err := reader.ReadFrame()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("failed to read frame: %w", err)
+		}
+		report.Frames++
+		report.addViolations(checker.Observe(frame))
+	}
+	report.addViolations(checker.Finish())
+	report.addStats(checker.Stats())
+
+	input.Logger.Debug("Checked message timing", "input_file", s.inputFile, "input_format", inputFormat, "frames", report.Frames, "violations", len(report.Violations))
+
+	if err := report.printTable(os.Stdout); err != nil {
+		return err
+	}
+	if s.reportFile != "" {
+		if err := report.writeJSON(s.reportFile); err != nil {
+			return err
+		}
+	}
+
+	if s.strict && len(report.Violations) > 0 {
+		return errors.Newf("found %d timing violation(s)", len(report.Violations))
+	}
+	return nil
+}
+
+// timingReport is the JSON report of a timing check.
+type timingReport struct {
+	Frames          uint64             `json:"frames"`
+	TimeoutFactor   float64            `json:"timeout_factor"`
+	JitterTolerance float64            `json:"jitter_tolerance"`
+	Messages        []*timingMessage   `json:"messages"`
+	Violations      []*timingViolation `json:"violations"`
+}
+
+// timingMessage summarizes the intervals of one cyclic message on one bus.
+type timingMessage struct {
+	Bus            string  `json:"bus"`
+	CanID          string  `json:"can_id"`
+	Message        string  `json:"message"`
+	CycleTimeMs    float64 `json:"cycle_time_ms"`
+	Count          uint64  `json:"count"`
+	MinGapMs       float64 `json:"min_gap_ms"`
+	MeanGapMs      float64 `json:"mean_gap_ms"`
+	MaxGapMs       float64 `json:"max_gap_ms"`
+	Missing        bool    `json:"missing"`
+	Timeouts       uint64  `json:"timeouts"`
+	JitterOutliers uint64  `json:"jitter_outliers"`
+}
+
+type timingViolation struct {
+	Kind        string    `json:"kind"`
+	Bus         string    `json:"bus"`
+	CanID       string    `json:"can_id"`
+	Message     string    `json:"message"`
+	Timestamp   time.Time `json:"timestamp"`
+	CycleTimeMs float64   `json:"cycle_time_ms"`
+	GapMs       float64   `json:"gap_ms"`
+}
+
+func (r *timingReport) addViolations(violations []timing.Violation) {
+	for _, v := range violations {
+		r.Violations = append(r.Violations, &timingViolation{
+			Kind:        v.Kind.String(),
+			Bus:         v.Bus,
+			CanID:       fmt.Sprintf("0x%X", v.Message.ID),
+			Message:     v.Message.Name,
+			Timestamp:   v.Timestamp,
+			CycleTimeMs: milliseconds(v.Message.CycleTime),
+			GapMs:       milliseconds(v.Gap),
+		})
+	}
+}
+
+func (r *timingReport) addStats(stats []timing.MessageStats) {
+	for _, st := range stats {
+		r.Messages = append(r.Messages, &timingMessage{
+			Bus:            st.Bus,
+			CanID:          fmt.Sprintf("0x%X", st.Message.ID),
+			Message:        st.Message.Name,
+			CycleTimeMs:    milliseconds(st.Message.CycleTime),
+			Count:          st.Count,
+			MinGapMs:       milliseconds(st.MinGap),
+			MeanGapMs:      milliseconds(st.MeanGap),
+			MaxGapMs:       milliseconds(st.MaxGap),
+			Missing:        st.Count == 0,
+			Timeouts:       st.Timeouts,
+			JitterOutliers: st.JitterOutliers,
+		})
+	}
+}
+
+// printTable writes the per message summary as a table.
+func (r *timingReport) printTable(w io.Writer) error {
+	fmt.Fprintf(w, "%d frames, %d cyclic messages, %d violations\n", r.Frames, len(r.Messages), len(r.Violations)) //nolint:errcheck
+	if len(r.Messages) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BUS\tCAN ID\tMESSAGE\tCYCLE (MS)\tCOUNT\tMIN (MS)\tMEAN (MS)\tMAX (MS)\tTIMEOUTS\tJITTER\tSTATUS") //nolint:errcheck
+	for _, m := range r.Messages {
+		status := "ok"
+		switch {
+		case m.Missing:
+			status = "missing"
+		case m.Timeouts > 0 || m.JitterOutliers > 0:
+			status = "violated"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.1f\t%d\t%.2f\t%.2f\t%.2f\t%d\t%d\t%s\n", //nolint:errcheck
+			m.Bus, m.CanID, m.Message, m.CycleTimeMs, m.Count, m.MinGapMs, m.MeanGapMs, m.MaxGapMs, m.Timeouts, m.JitterOutliers, status)
+	}
+	return tw.Flush()
+}
+
+// writeJSON writes the report as JSON to path.
+func (r *timingReport) writeJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal timing report")
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return errors.Wrap(err, "write timing report")
+	}
+	return nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -1,131 +1,323 @@
 package convert
 
 import (
+	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/spf13/cobra"
-	"google.golang.org/protobuf/types/known/timestamppb"
+	"go.einride.tech/can/pkg/descriptor"
 
+	"github.com/BIwashi/candecode/pkg/can"
+	"github.com/BIwashi/candecode/pkg/capture"
 	"github.com/BIwashi/candecode/pkg/cli"
 	"github.com/BIwashi/candecode/pkg/dbc"
 	mcapwriter "github.com/BIwashi/candecode/pkg/mcap"
-	"github.com/BIwashi/candecode/pkg/pcapng"
 	candecodeproto "github.com/BIwashi/candecode/pkg/proto"
+	"github.com/BIwashi/candecode/pkg/timing"
+	"github.com/BIwashi/candecode/pkg/version"
 )
 
 type converter struct {
-	dbcFile    string
-	pcapngFile string
+	dbcFile     string
+	dbcMappings []string
+	inputFile   string
+	inputFormat string
+	ascChannels []string
+	output      string
+	outputDir   string
+	force       bool
+	compression string
+	chunkSize   int64
+	unchunked   bool
+	channelMode string
+	compact     bool
+	encoding    string
+	raw         bool
+	publishTime string
+	reportFile  string
+	dlcPolicy   string
+	checkTiming bool
+	validate    bool
+	checksum    string
+	dropRange   bool
+	clamp       bool
+
+	// outputDirSet is set when --output-dir is given explicitly
+	outputDirSet bool
 }
 
-func NewCommand() *cobra.Command {
-	s := &converter{
-		dbcFile:    "",
-		pcapngFile: "",
+// newConverter returns a converter with the flag defaults.
+func newConverter() *converter {
+	return &converter{
+		dbcFile:     "",
+		dbcMappings: nil,
+		inputFile:   "",
+		inputFormat: capture.FormatAuto,
+		ascChannels: nil,
+		output:      "",
+		outputDir:   defaultOutputDir,
+		force:       false,
+		compression: "zstd",
+		chunkSize:   100 * 1024 * 1024,
+		unchunked:   false,
+		channelMode: channelModeSignal,
+		compact:     false,
+		encoding:    string(mcapwriter.EncodingProtobuf),
+		raw:         false,
+		publishTime: string(mcapwriter.PublishTimeCapture),
+		reportFile:  "",
+		dlcPolicy:   dbc.LengthPolicyStrict.String(),
+		checkTiming: false,
+		validate:    false,
+		checksum:    checksumAuto,
+		dropRange:   false,
+		clamp:       false,
 	}
+}
+
+func NewCommand() *cobra.Command {
+	s := newConverter()
 
 	cmd := &cobra.Command{
 		Use:   "convert",
-		Short: "Convert CAN data captured with pcapng to MCAP using a DBC file.",
+		Short: "Convert CAN data captured with pcapng, candump or Vector tools to MCAP using a DBC file.",
 		Long: `
-Convert PCAPNG files captured from CAN bus to MCAP format.
+Convert PCAPNG files, candump logs or Vector ASC traces captured from CAN bus to MCAP format.
+
+This command reads CAN frames from a PCAPNG file, a candump log (candump -l / -L) or a Vector
+ASC trace (CANoe / CANalyzer), decodes them using a DBC file, and writes the decoded messages
+to an MCAP file with protobuf schema.
+The input format is detected from the file contents unless --input-format is given.
+
+Each bus (pcapng interface, candump interface name, ASC channel named with --asc-channel) can be
+decoded with its own DBC file using --dbc bus=path.
+Buses without a mapping are decoded with the default DBC given by --dbc-file.
+
+By default the output is written to <output-dir>/<input-basename>.mcap. Existing files are
+only replaced with --force, and the file is written to a temp file that is renamed into place
+once the conversion succeeds.
 
-This command reads CAN frames from a PCAPNG file, decodes them using a DBC file,
-and writes the decoded messages to an MCAP file with protobuf schema.`,
+The provenance metadata records the conversion time. Set SOURCE_DATE_EPOCH (seconds since the
+Unix epoch) to record a fixed time instead, so converting the same input twice produces the same
+bytes.`,
 		Example: `
 # Convert PCAPNG to MCAP
-candecode convert --dbc-file reference.dbc --pcapng-file capture.pcapng --mcap-file output.mcap`,
-		RunE: cli.WithContext(s.run),
-	}
+candecode convert --dbc-file reference.dbc --pcapng-file capture.pcapng -o output.mcap
 
-	cmd.Flags().StringVar(&s.dbcFile, "dbc-file", s.dbcFile, "DBC file")
-	cmd.Flags().StringVar(&s.pcapngFile, "pcapng-file", s.pcapngFile, "PCAPNG file")
+# Convert a candump log (candump -L) the same way
+candecode convert --dbc-file reference.dbc --input-file candump-2023-10-11_160000.log
 
-	if err := cmd.MarkFlagRequired("dbc-file"); err != nil {
-		fmt.Printf("failed to mark flag as required, err: %v", err)
+# Convert a Vector ASC trace, naming channels 1 and 2 as the buses of the --dbc mappings
+candecode convert --dbc can0=toyota_nodsu_pt.dbc --dbc can1=toyota_adas.dbc --input-file trace.asc --asc-channel 1=can0 --asc-channel 2=can1
 
-		return nil
-	}
-	if err := cmd.MarkFlagRequired("pcapng-file"); err != nil {
-		fmt.Printf("failed to mark flag as required, err: %v", err)
+# Fast LZ4 compression with small chunks for streaming previews
+candecode convert --dbc-file reference.dbc --pcapng-file capture.pcapng --compression lz4 --chunk-size 1048576
+
+# One channel per DBC message (/can/<bus>/<Message>) instead of one per signal
+candecode convert --dbc-file reference.dbc --pcapng-file capture.pcapng --channel-mode message
+
+# Typed per-message schema generated from the DBC (plot /can/<bus>/<Message>.<Signal>)
+candecode convert --dbc-file reference.dbc --pcapng-file capture.pcapng --channel-mode typed
+
+# Compact value-only records (definitions stored once as metadata)
+candecode convert --dbc-file reference.dbc --pcapng-file capture.pcapng --compact
+
+# JSON encoded records with JSON Schemas, for tools without protobuf support
+candecode convert --dbc-file reference.dbc --pcapng-file capture.pcapng --encoding json
 
-		return nil
+# Also keep every captured frame (decoded or not) on /can/<bus>/raw
+candecode convert --dbc-file reference.dbc --pcapng-file capture.pcapng --raw
+
+# Decode the signals that fit in truncated frames instead of skipping them
+candecode convert --dbc-file reference.dbc --pcapng-file capture.pcapng --dlc-policy lenient
+
+# Clamp physical values to the DBC [min, max] range (out_of_range stays set)
+candecode convert --dbc-file reference.dbc --pcapng-file capture.pcapng --clamp
+
+# Validate opendbc CHECKSUM / COUNTER signals and flag corrupt or replayed frames
+candecode convert --dbc-file honda_civic_touring_2016_can_generated.dbc --pcapng-file capture.pcapng --validate
+
+# Mark messages deviating from their DBC cycle time on /diagnostics/timing
+candecode convert --dbc-file reference.dbc --pcapng-file capture.pcapng --check-timing
+
+# Write the summary of undecoded CAN IDs as JSON
+candecode convert --dbc-file reference.dbc --pcapng-file capture.pcapng --report undecoded.json
+
+# Stream MCAP to stdout
+candecode convert --dbc-file reference.dbc --pcapng-file capture.pcapng -o - | mcap info -
+
+# Decode each bus with its own DBC
+candecode convert --dbc can0=toyota_nodsu_pt.dbc --dbc can2=toyota_adas.dbc --pcapng-file capture.pcapng`,
+		PreRun: func(cmd *cobra.Command, _ []string) {
+			s.outputDirSet = cmd.Flags().Changed("output-dir")
+		},
+		RunE: cli.WithContext(s.run),
 	}
 
+	cmd.Flags().StringVar(&s.dbcFile, "dbc-file", s.dbcFile, "Default DBC file, used for buses without a --dbc mapping")
+	cmd.Flags().StringArrayVar(&s.dbcMappings, "dbc", s.dbcMappings, "Per-bus DBC file as bus=path (repeatable)")
+	cmd.Flags().StringVar(&s.inputFile, "input-file", s.inputFile, "Capture file: PCAPNG, candump log or Vector ASC trace")
+	cmd.Flags().StringVar(&s.inputFile, "pcapng-file", s.inputFile, "Capture file (alias of --input-file)")
+	cmd.Flags().StringVar(&s.inputFormat, "input-format", s.inputFormat,
+		"Format of the capture file. Available values: auto (detected from the contents), pcapng, candump, asc")
+	cmd.Flags().StringArrayVar(&s.ascChannels, "asc-channel", s.ascChannels,
+		"Bus name of a Vector ASC channel as channel=bus, e.g. 1=can0 (repeatable; default CAN<channel>)")
+	cmd.Flags().StringVarP(&s.output, "output", "o", s.output, "MCAP output file, or - for stdout (default <output-dir>/<input-basename>.mcap)")
+	cmd.Flags().StringVar(&s.outputDir, "output-dir", s.outputDir, "Directory for the MCAP output file")
+	cmd.Flags().BoolVar(&s.force, "force", s.force, "Overwrite an existing output file")
+	cmd.Flags().StringVar(&s.compression, "compression", s.compression, "MCAP chunk compression. Available values: zstd, lz4, none")
+	cmd.Flags().Int64Var(&s.chunkSize, "chunk-size", s.chunkSize, "MCAP chunk size in bytes")
+	cmd.Flags().BoolVar(&s.unchunked, "unchunked", s.unchunked, "Write an unchunked MCAP file (no compression)")
+	cmd.Flags().StringVar(&s.channelMode, "channel-mode", s.channelMode,
+		"MCAP channel layout. Available values: signal (one channel per signal), message (one channel and record per CAN frame), "+
+			"typed (like message, with a protobuf schema generated from the DBC)")
+	cmd.Flags().BoolVar(&s.compact, "compact", s.compact,
+		"Write value-only records; signal definitions are stored once in channel and message metadata")
+	cmd.Flags().BoolVar(&s.raw, "raw", s.raw, "Also write every captured frame, decoded or not, as RawFrame on /can/<bus>/raw")
+	cmd.Flags().StringVar(&s.dlcPolicy, "dlc-policy", s.dlcPolicy,
+		"Handling of frames whose length differs from the DBC. Available values: strict (skip), "+
+			"lenient (decode signals within the received bytes, mark the rest missing), ignore-length (decode all, zero padded)")
+	cmd.Flags().BoolVar(&s.checkTiming, "check-timing", s.checkTiming,
+		"Write cycle time violations (timeouts, jitter, missing messages; see check timing) as TimingViolation on /diagnostics/timing")
+	cmd.Flags().BoolVar(&s.dropRange, "drop-out-of-range", s.dropRange,
+		"Leave signals whose physical value lies outside the DBC [min, max] range out of the output")
+	cmd.Flags().BoolVar(&s.clamp, "clamp", s.clamp,
+		"Clamp physical values to the DBC [min, max] range; out_of_range is still set")
+	cmd.Flags().BoolVar(&s.validate, "validate", s.validate,
+		"Validate checksum and rolling counter signals and add a validity flag to every decoded record")
+	cmd.Flags().StringVar(&s.checksum, "checksum", s.checksum,
+		"Checksum algorithm for --validate. Available values: auto (detected from the DBC file name), none, "+strings.Join(dbc.ChecksumNames(), ", "))
+	cmd.Flags().StringVar(&s.reportFile, "report", s.reportFile, "Write the decode summary (undecoded CAN IDs with counts, DLCs and first/last timestamps) as JSON to this file")
+	cmd.Flags().StringVar(&s.publishTime, "publish-time", s.publishTime,
+		"MCAP publish time of records. Available values: capture (the capture time), wallclock (the write time). "+
+			"The conversion time in the provenance metadata is the wall clock unless SOURCE_DATE_EPOCH is set")
+	cmd.Flags().StringVar(&s.encoding, "encoding", s.encoding, "MCAP message encoding. Available values: protobuf, json")
+
 	return cmd
 }
 
 func (s *converter) run(ctx context.Context, input cli.Input) error {
 	logger := input.Logger
 
-	input.Logger.Info("Starting PCAPNG to MCAP conversion",
+	input.Logger.Info("Starting MCAP conversion",
 		"dbc_file", s.dbcFile,
-		"pcapng_file", s.pcapngFile,
+		"dbc", s.dbcMappings,
+		"input_file", s.inputFile,
 	)
 
-	// Open PCAPNG file
-	logger.Info("Opening PCAPNG file...")
-	pcapFile, err := os.Open(s.pcapngFile)
+	if s.inputFile == "" {
+		return errors.New("--input-file (or --pcapng-file) is required")
+	}
+	if s.dbcFile == "" && len(s.dbcMappings) == 0 {
+		return errors.New("either --dbc-file or --dbc bus=path is required")
+	}
+
+	switch s.channelMode {
+	case channelModeSignal, channelModeMessage:
+	case channelModeTyped:
+		if s.compact {
+			return errors.New("--compact cannot be used with --channel-mode typed (typed records are already value-only)")
+		}
+	default:
+		return fmt.Errorf("invalid --channel-mode %q, expected %s, %s or %s",
+			s.channelMode, channelModeSignal, channelModeMessage, channelModeTyped)
+	}
+
+	if s.dropRange && s.clamp {
+		return errors.New("--drop-out-of-range and --clamp cannot be used together")
+	}
+
+	validator, err := s.newValidator()
 	if err != nil {
-		return fmt.Errorf("failed to open PCAPNG file: %w", err)
+		return err
 	}
-	defer pcapFile.Close() //nolint:errcheck
 
-	// Create PCAPNG reader
-	reader, err := pcapng.NewReader(pcapFile)
+	writerOpts, err := s.writerOptions()
 	if err != nil {
-		return fmt.Errorf("failed to create PCAPNG reader: %w", err)
+		return err
 	}
 
-	// Create DBC compiler
-	compiler, err := dbc.NewCompiler(s.dbcFile)
+	// Open capture file
+	logger.Info("Opening input file...")
+	inputFile, err := os.Open(s.inputFile)
 	if err != nil {
-		return fmt.Errorf("failed to create DBC compiler: %w", err)
+		return fmt.Errorf("failed to open input file: %w", err)
 	}
-	decoder := dbc.NewDecoder(compiler)
+	defer inputFile.Close() //nolint:errcheck
 
-	// Prepare MCAP output path: /mcap/<pcapng-basename-with-.mcap>
+	// Create the reader of the detected (or given) format
+	// The capture is hashed while it is read, for the provenance record
 	var (
-		base      = filepath.Base(s.pcapngFile)
-		baseNoExt = strings.TrimSuffix(base, filepath.Ext(base))
-		outDir    = "mcap"
-		mcapExt   = ".mcap"
-		outPath   = filepath.Join(outDir, baseNoExt+mcapExt)
+		inputHash   = sha256.New()
+		inputSource = bufio.NewReader(io.TeeReader(inputFile, inputHash))
 	)
-	if err := os.MkdirAll(outDir, 0o755); err != nil {
-		return fmt.Errorf("failed to create mcap output dir: %w", err)
+	reader, inputFormat, err := s.newFrameReader(inputSource)
+	if err != nil {
+		return err
 	}
+	logger.Info("Reading input file", "input_format", inputFormat)
 
-	logger.Info("Opening MCAP output file...", "path", outPath)
+	// Create DBC decoder (default DBC + per-bus mappings)
+	decoder, dbcFiles, err := s.newDecoder()
+	if err != nil {
+		return err
+	}
 
-	mcapFile, err := os.Create(outPath)
+	// Open MCAP output (temp file renamed into place on success, or stdout)
+	out, err := s.openOutput()
 	if err != nil {
-		return fmt.Errorf("failed to create MCAP file: %w", err)
+		return err
 	}
-	defer func() {
-		_ = mcapFile.Close()
-	}()
+	defer out.Abort()
+
+	logger.Info("Opening MCAP output file...", "path", out.path)
 
-	mw, err := mcapwriter.NewWriter(mcapFile)
+	mw, err := mcapwriter.NewWriter(out, writerOpts...)
 	if err != nil {
 		return fmt.Errorf("failed to init MCAP writer: %w", err)
 	}
-	defer func() {
-		_ = mw.Close()
-	}()
 
 	// Process frames
 	logger.Info("Converting CAN frames...")
 	var (
-		frameCount    = 0
-		messageCount  = 0
+		report        = newDecodeReport()
 		signalRecords = 0
+		rawRecords    = 0
+		violations    = 0
+		checker       *timing.Checker
+		definitions   = make(map[string]*candecodeproto.MessageDefinition) // key: bus:canID
 	)
+	if s.checkTiming {
+		checker = timing.NewChecker(decoder)
+	}
+	// messageDefinition returns the definition of msgDesc on the bus of frame, built once per (bus, message)
+	messageDefinition := func(compiler *dbc.Compiler, frame *can.TimedFrame, msgDesc *descriptor.Message) *candecodeproto.MessageDefinition {
+		defKey := fmt.Sprintf("%s:0x%X", frame.Interface, frame.ID)
+		def, ok := definitions[defKey]
+		if !ok {
+			def = newMessageDefinition(compiler, frame, msgDesc)
+			definitions[defKey] = def
+		}
+		return def
+	}
+	writeViolations := func(vs []timing.Violation) {
+		for _, v := range vs {
+			if err := mw.WriteTimingViolation(newTimingViolation(v)); err != nil {
+				logger.Error("failed to write timing violation", "error", err, "message", v.Message.Name)
+				continue
+			}
+			violations++
+		}
+	}
 
 	for {
 		// Check context cancellation
@@ -138,117 +330,291 @@ func (s *converter) run(ctx context.Context, input cli.Input) error {
 
 		frame, err := reader.ReadFrame()
 		if err != nil {
-			if err.Error() == "EOF" {
+			if errors.Is(err, io.EOF) {
 				break
 			}
 			return fmt.Errorf("failed to read frame: %w", err)
 		}
 
+		report.Frames++
+		if report.Frames%1000 == 0 {
+			logger.Info("Progress", "frames", report.Frames, "signals", signalRecords)
+		}
+
+		if s.raw {
+			if err := mw.WriteRawFrame(newRawFrame(frame)); err != nil {
+				logger.Error("failed to write raw frame", "error", err, "can_id", fmt.Sprintf("0x%X", frame.ID))
+			} else {
+				rawRecords++
+			}
+		}
+		if checker != nil {
+			writeViolations(checker.Observe(frame))
+		}
+
 		decodedSignals, err := decoder.Decode(frame)
 		if err != nil {
-			// Skip frames that can't be decoded (unknown message, shape mismatch, etc.)
+			// Frames that can't be decoded (unknown message, shape mismatch, etc.) are counted per ID
+			logger.Debug("frame not decoded", "error", err, "bus", frame.Interface)
+			report.addUndecoded(frame, err)
 			continue
 		}
+		report.Decoded++
 
 		// Retrieve message descriptor for message name & units
+		compiler, _ := decoder.Compiler(frame.Interface)
 		msgDesc, ok := compiler.Message(frame.ID)
 		messageName := fmt.Sprintf("0x%X", frame.ID)
 		if ok {
 			messageName = msgDesc.Name
 		}
+		outcome := decoder.LengthOutcome(frame, msgDesc)
+		var validity *candecodeproto.Validity
+		if validator != nil {
+			v := validator.Validate(compiler, frame, msgDesc)
+			report.addValidity(v)
+			validity = newValidity(v)
+		}
 
-		// For each signal produce one DecodedSignal proto and write to MCAP
-		for sigName, sig := range decodedSignals {
-			ds := &candecodeproto.DecodedSignal{
-				MessageName: messageName,
-				Name:        sigName,
-				Timestamp:   timestamppb.New(sig.Timestamp),
-				CanId:       frame.ID,
-				IsExtended:  frame.IsExtended,
-				FrameBytes:  make([]byte, frame.Length),
-				Signal: &candecodeproto.Signal{
-					Name:             sig.Signal.Name,
-					Start:            uint32(sig.Signal.Start),
-					Length:           uint32(sig.Signal.Length),
-					IsBigEndian:      sig.Signal.IsBigEndian,
-					IsSigned:         sig.Signal.IsSigned,
-					IsFloat:          sig.Signal.IsFloat,
-					IsMultiplexer:    sig.Signal.IsMultiplexer,
-					IsMultiplexed:    sig.Signal.IsMultiplexed,
-					MultiplexerValue: uint32(sig.Signal.MultiplexerValue),
-					Offset:           sig.Signal.Offset,
-					Scale:            sig.Signal.Scale,
-					Min:              sig.Signal.Min,
-					Max:              sig.Signal.Max,
-					Unit:             sig.Signal.Unit,
-					Description:      sig.Signal.Description,
-					DefaultValue:     int32(sig.Signal.DefaultValue),
-					SourceFile:       compiler.SourceFile(),
-				},
+		switch {
+		case s.channelMode == channelModeTyped:
+			// Typed record of the schema generated from the DBC; real field names and enums
+			def := messageDefinition(compiler, frame, msgDesc)
+			schema, err := compiler.Schema()
+			if err != nil {
+				return fmt.Errorf("failed to generate protobuf schema from DBC: %w", err)
 			}
-			// ValueDescriptions
-			for _, vd := range sig.Signal.ValueDescriptions {
-				ds.Signal.ValueDescriptions = append(ds.Signal.ValueDescriptions, &candecodeproto.ValueDescription{
-					Value:       vd.Value,
-					Description: vd.Description,
-				})
+			msg, err := schema.NewMessage(msgDesc, frame.Timestamp, outcome, validity, decodedSignals)
+			if err != nil {
+				logger.Error("failed to build typed message", "error", err, "message", messageName)
+				break
 			}
-			// Receiver nodes
-			for _, rn := range sig.Signal.ReceiverNodes {
-				ds.Signal.ReceiverNodes = append(ds.Signal.ReceiverNodes, rn)
+			if err := mw.WriteTypedMessage(def, frame.Timestamp, msg); err != nil {
+				logger.Error("failed to write typed message", "error", err, "message", messageName)
+				break
 			}
-
-			// Physical
-			if sig.Physical != nil {
-				ds.Physical = sig.Physical
+			signalRecords += len(decodedSignals)
+		case s.compact:
+			// Compact value-only records; the definition is written once per (bus, message)
+			def := messageDefinition(compiler, frame, msgDesc)
+			if s.channelMode == channelModeMessage {
+				sample := newMessageSample(frame, msgDesc, decodedSignals, outcome)
+				sample.Validity = validity
+				if err := mw.WriteMessageSample(def, sample); err != nil {
+					logger.Error("failed to write message sample", "error", err, "message", messageName)
+					break
+				}
+				signalRecords += len(sample.Signals)
+				break
 			}
-			// Description (value description matched)
-			if sig.Description != "" {
-				ds.Description = sig.Description
+			for _, sigDesc := range msgDesc.Signals {
+				sigName := sigDesc.Name
+				sig, ok := decodedSignals[sigName]
+				if !ok || sig.Missing {
+					continue
+				}
+				sample, ok := newSignalSample(sig, outcome)
+				if !ok {
+					// Fallback: skip if unknown raw type
+					continue
+				}
+				sample.Validity = validity
+				if err := mw.WriteSignalSample(def, sigName, sample); err != nil {
+					logger.Error("failed to write signal sample", "error", err, "signal", sigName)
+					continue
+				}
+				signalRecords++
 			}
-
-			// Raw oneof
-			switch v := sig.Raw.(type) {
-			case bool:
-				ds.Raw = &candecodeproto.DecodedSignal_RawB{RawB: v}
-			case int64:
-				ds.Raw = &candecodeproto.DecodedSignal_RawS{RawS: v}
-			case uint64:
-				ds.Raw = &candecodeproto.DecodedSignal_RawU{RawU: v}
-			case float64:
-				ds.Raw = &candecodeproto.DecodedSignal_RawF{RawF: v}
-			case []byte:
-				ds.Raw = &candecodeproto.DecodedSignal_RawBytes{RawBytes: v}
-			default:
-				// Fallback: skip if unknown type
-				continue
+		case s.channelMode == channelModeMessage:
+			// One DecodedMessage proto holding every signal of the frame
+			def := messageDefinition(compiler, frame, msgDesc)
+			dm := newDecodedMessage(frame, messageName, msgDesc, decodedSignals, outcome)
+			dm.Validity = validity
+			if err := mw.WriteDecodedMessage(def, dm); err != nil {
+				logger.Error("failed to write decoded message", "error", err, "message", messageName)
+				break
 			}
-
-			copy(ds.FrameBytes, frame.Data[:frame.Length])
-
-			if err := mw.WriteDecodedSignal(ds); err != nil {
-				logger.Error("failed to write decoded signal", "error", err, "signal", sigName)
-				continue
+			signalRecords += len(dm.Signals)
+		default:
+			// For each signal produce one DecodedSignal proto and write to MCAP, in DBC order
+			for _, sigDesc := range msgDesc.Signals {
+				sigName := sigDesc.Name
+				sig, ok := decodedSignals[sigName]
+				if !ok || sig.Missing {
+					continue
+				}
+				ds, ok := newDecodedSignal(compiler, frame, messageName, sigName, sig, outcome)
+				if !ok {
+					// Fallback: skip if unknown raw type
+					continue
+				}
+				ds.Validity = validity
+
+				if err := mw.WriteDecodedSignal(ds); err != nil {
+					logger.Error("failed to write decoded signal", "error", err, "signal", sigName)
+					continue
+				}
+				signalRecords++
 			}
-			signalRecords++
 		}
+	}
+	if checker != nil {
+		writeViolations(checker.Finish())
+	}
+	report.finish(reader.GetSkippedCount())
 
-		frameCount++
-		if ok {
-			messageCount++
-		}
+	// Hash any trailing bytes the reader did not consume
+	if _, err := io.Copy(io.Discard, inputSource); err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+	convertedAt, err := conversionTime()
+	if err != nil {
+		return err
+	}
+	if err := mw.WriteProvenance(mcapwriter.Provenance{
+		Version:     version.String(),
+		Args:        os.Args[1:],
+		ConvertedAt: convertedAt,
+		InputFile:   s.inputFile,
+		InputFormat: inputFormat,
+		InputSHA256: hex.EncodeToString(inputHash.Sum(nil)),
+		DBCs:        dbcFiles,
+	}); err != nil {
+		return fmt.Errorf("failed to write provenance: %w", err)
+	}
 
-		if frameCount%1000 == 0 {
-			logger.Info("Progress", "frames", frameCount, "signals", signalRecords)
-		}
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize MCAP file: %w", err)
+	}
+	if err := out.Commit(); err != nil {
+		return fmt.Errorf("failed to write MCAP file: %w", err)
 	}
 
 	logger.Info("Conversion complete",
-		"frames", frameCount,
-		"messages_decoded", messageCount,
+		"frames", report.Frames,
+		"messages_decoded", report.Decoded,
+		"frames_undecoded", report.Undecoded,
 		"signals_written", signalRecords,
-		"output_mcap", outPath,
+		"raw_frames_written", rawRecords,
+		"timing_violations", violations,
+		"output_mcap", out.path,
 	)
 
+	// Summary of undecoded frames; stderr keeps stdout free for MCAP streaming
+	if err := report.printTable(os.Stderr); err != nil {
+		return fmt.Errorf("failed to print decode summary: %w", err)
+	}
+	if s.reportFile != "" {
+		if err := report.writeJSON(s.reportFile); err != nil {
+			return err
+		}
+		logger.Info("Decode report written", "path", s.reportFile)
+	}
+
 	return nil
 }
+
+// writerOptions builds the MCAP writer options from the compression, chunking, encoding and publish time flags.
+func (s *converter) writerOptions() ([]mcapwriter.WriterOption, error) {
+	compression, err := mcapwriter.ParseCompression(s.compression)
+	if err != nil {
+		return nil, err
+	}
+	if s.chunkSize <= 0 {
+		return nil, fmt.Errorf("invalid --chunk-size %d, must be positive", s.chunkSize)
+	}
+
+	encoding, err := mcapwriter.ParseEncoding(s.encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	publishTime, err := mcapwriter.ParsePublishTime(s.publishTime)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []mcapwriter.WriterOption{
+		mcapwriter.WithCompression(compression),
+		mcapwriter.WithChunkSize(s.chunkSize),
+		mcapwriter.WithEncoding(encoding),
+		mcapwriter.WithPublishTime(publishTime),
+	}
+	if s.unchunked {
+		opts = append(opts, mcapwriter.WithUnchunked())
+	}
+	return opts, nil
+}
+
+// conversionTime returns the conversion time recorded in the provenance metadata: the wall clock,
+// or SOURCE_DATE_EPOCH (seconds) when set, for reproducible output.
+func conversionTime() (time.Time, error) {
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		sec, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", epoch, err)
+		}
+		return time.Unix(sec, 0), nil
+	}
+	return time.Now(), nil
+}
+
+// Values of --checksum besides the built-in algorithm names.
+const (
+	// checksumAuto detects the algorithm from the DBC file name.
+	checksumAuto = "auto"
+	// checksumNone validates counters only.
+	checksumNone = "none"
+)
+
+// newValidator returns the checksum and counter validator, or nil without --validate.
+func (s *converter) newValidator() (*dbc.Validator, error) {
+	if !s.validate {
+		return nil, nil
+	}
+	switch s.checksum {
+	case checksumAuto:
+		return dbc.NewValidator(), nil
+	case checksumNone:
+		return dbc.NewValidator(dbc.WithChecksum(nil)), nil
+	}
+	checksum, err := dbc.ParseChecksum(s.checksum)
+	if err != nil {
+		return nil, err
+	}
+	return dbc.NewValidator(dbc.WithChecksum(checksum)), nil
+}
+
+// newDecoder compiles the default DBC and every bus=path mapping (see dbc.LoadDecoder).
+// It also returns the DBC files in order of first reference, for provenance.
+func (s *converter) newDecoder() (*dbc.Decoder, []mcapwriter.DBCFile, error) {
+	lengthPolicy, err := dbc.ParseLengthPolicy(s.dlcPolicy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rangePolicy := dbc.RangePolicyKeep
+	switch {
+	case s.dropRange:
+		rangePolicy = dbc.RangePolicyDrop
+	case s.clamp:
+		rangePolicy = dbc.RangePolicyClamp
+	}
+
+	decoder, loaded, err := dbc.LoadDecoder(s.dbcFile, s.dbcMappings,
+		dbc.WithLengthPolicy(lengthPolicy), dbc.WithRangePolicy(rangePolicy))
+	if err != nil {
+		return nil, nil, err
+	}
+	files := make([]mcapwriter.DBCFile, 0, len(loaded))
+	for _, f := range loaded {
+		files = append(files, mcapwriter.DBCFile{
+			Path:       f.Path,
+			Buses:      f.Buses,
+			Version:    f.Compiler.Version(),
+			Data:       f.Compiler.Source(),
+			Attributes: attributeMap(f.Compiler.NetworkAttributes()),
+		})
+	}
+	return decoder, files, nil
+}
@@ -0,0 +1,94 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BIwashi/candecode/pkg/cli"
+	mcapwriter "github.com/BIwashi/candecode/pkg/mcap"
+)
+
+const testDBC = `VERSION ""
+
+BU_: ECU
+
+BO_ 256 ENGINE: 8 ECU
+ SG_ RPM : 0|16@1+ (0.25,0) [0|16383.75] "rpm" Vector__XXX
+ SG_ GEAR : 16|4@1+ (1,0) [0|15] "" Vector__XXX
+
+BO_ 512 BRAKE: 2 ECU
+ SG_ PRESSURE : 0|8@1+ (1,0) [0|255] "bar" Vector__XXX
+
+BA_DEF_ BO_ "GenMsgCycleTime" INT 0 65535;
+BA_DEF_DEF_ "GenMsgCycleTime" 0;
+BA_ "GenMsgCycleTime" BO_ 256 100;
+
+VAL_ 256 GEAR 0 "P" 1 "R" 2 "N" 3 "D" ;
+`
+
+const testCandumpLog = `(1697040000.000000) can0 100#1027030000000000
+(1697040000.010000) can1 200#2A00
+(1697040000.050000) can0 7FF#0102
+(1697040000.100000) can0 100#2027020000000000
+(1697040000.110000) can1 200#2B00
+(1697040000.500000) can0 100#3027010000000000
+`
+
+// TestRunReproducible converts the same capture twice per output mode and expects identical bytes.
+func TestRunReproducible(t *testing.T) {
+	dir := t.TempDir()
+	dbcFile := filepath.Join(dir, "test.dbc")
+	logFile := filepath.Join(dir, "capture.log")
+	if err := os.WriteFile(dbcFile, []byte(testDBC), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logFile, []byte(testCandumpLog), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+
+	tests := []struct {
+		name  string
+		flags func(s *converter)
+	}{
+		{name: "signal", flags: func(*converter) {}},
+		{name: "message", flags: func(s *converter) { s.channelMode = channelModeMessage }},
+		{name: "typed", flags: func(s *converter) { s.channelMode = channelModeTyped }},
+		{name: "compact", flags: func(s *converter) { s.compact = true }},
+		{name: "json", flags: func(s *converter) { s.encoding = string(mcapwriter.EncodingJSON) }},
+		{name: "raw and timing", flags: func(s *converter) { s.raw = true; s.checkTiming = true }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			convert := func(output string) []byte {
+				s := newConverter()
+				s.dbcFile = dbcFile
+				s.inputFile = logFile
+				s.output = filepath.Join(dir, output)
+				tt.flags(s)
+				if err := s.run(context.Background(), cli.Input{Logger: *slog.New(slog.NewTextHandler(io.Discard, nil))}); err != nil {
+					t.Fatalf("run() error = %v", err)
+				}
+				data, err := os.ReadFile(s.output)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return data
+			}
+
+			first := convert(tt.name + "-1.mcap")
+			second := convert(tt.name + "-2.mcap")
+			if len(first) == 0 {
+				t.Fatal("empty output")
+			}
+			if !bytes.Equal(first, second) {
+				t.Errorf("converting twice produced different output (%d and %d bytes)", len(first), len(second))
+			}
+		})
+	}
+}
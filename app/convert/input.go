@@ -0,0 +1,17 @@
+package convert
+
+import (
+	"bufio"
+
+	"github.com/BIwashi/candecode/pkg/capture"
+)
+
+// newFrameReader creates the reader of the --input-format of r, with the --asc-channel mappings.
+// It also returns the format.
+func (s *converter) newFrameReader(r *bufio.Reader) (capture.Reader, string, error) {
+	opts, err := capture.ParseChannels(s.ascChannels)
+	if err != nil {
+		return nil, "", err
+	}
+	return capture.NewReader(r, append(opts, capture.WithFormat(s.inputFormat))...)
+}
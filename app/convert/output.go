@@ -0,0 +1,112 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	defaultOutputDir = "mcap"
+	mcapExt          = ".mcap"
+	stdoutPath       = "-"
+)
+
+// output is the destination of the MCAP stream.
+//
+// Files are written to a temp file next to the target and renamed into place on Commit,
+// so an interrupted run never leaves a truncated MCAP that looks valid.
+type output struct {
+	io.Writer
+	path string
+	tmp  *os.File
+}
+
+// outputPath resolves the MCAP output path from --output / --output-dir.
+// Without --output the file is <output-dir>/<input-basename>.mcap.
+func (s *converter) outputPath() (string, error) {
+	if s.output != "" {
+		if s.outputDirSet {
+			return "", errors.New("--output and --output-dir are mutually exclusive")
+		}
+		return s.output, nil
+	}
+
+	var (
+		base      = filepath.Base(s.inputFile)
+		baseNoExt = strings.TrimSuffix(base, filepath.Ext(base))
+	)
+	return filepath.Join(s.outputDir, baseNoExt+mcapExt), nil
+}
+
+// openOutput opens the MCAP destination. Existing files are only replaced with --force.
+func (s *converter) openOutput() (*output, error) {
+	path, err := s.outputPath()
+	if err != nil {
+		return nil, err
+	}
+	if path == stdoutPath {
+		return &output{Writer: os.Stdout, path: path}, nil
+	}
+
+	if _, err := os.Stat(path); err == nil && !s.force {
+		return nil, fmt.Errorf("output file already exists: %s (use --force to overwrite)", path)
+	} else if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to stat output file: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mcap output dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MCAP file: %w", err)
+	}
+
+	return &output{Writer: tmp, path: path, tmp: tmp}, nil
+}
+
+// Commit flushes the temp file and atomically renames it to the output path.
+func (o *output) Commit() error {
+	if o.tmp == nil {
+		return nil
+	}
+	tmp := o.tmp
+	o.tmp = nil
+
+	if err := tmp.Chmod(0o644); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return errors.Wrap(err, "chmod MCAP file")
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return errors.Wrap(err, "sync MCAP file")
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrap(err, "close MCAP file")
+	}
+	if err := os.Rename(tmp.Name(), o.path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrap(err, "rename MCAP file into place")
+	}
+	return nil
+}
+
+// Abort discards the temp file. It is a no-op after Commit.
+func (o *output) Abort() {
+	if o.tmp == nil {
+		return
+	}
+	_ = o.tmp.Close()
+	_ = os.Remove(o.tmp.Name())
+	o.tmp = nil
+}
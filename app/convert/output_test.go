@@ -0,0 +1,146 @@
+package convert
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BIwashi/candecode/pkg/cli"
+)
+
+func TestOutputPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       converter
+		want    string
+		wantErr bool
+	}{
+		{name: "default", s: converter{inputFile: "logs/capture.pcapng", outputDir: defaultOutputDir}, want: filepath.Join("mcap", "capture.mcap")},
+		{name: "output dir", s: converter{inputFile: "capture.log", outputDir: "out", outputDirSet: true}, want: filepath.Join("out", "capture.mcap")},
+		{name: "output", s: converter{inputFile: "capture.log", output: "x.mcap", outputDir: defaultOutputDir}, want: "x.mcap"},
+		{name: "stdout", s: converter{inputFile: "capture.log", output: stdoutPath, outputDir: defaultOutputDir}, want: stdoutPath},
+		{name: "output and output dir", s: converter{output: "x.mcap", outputDir: "out", outputDirSet: true}, wantErr: true},
+		// --output-dir given with its default value still conflicts
+		{name: "output and explicit default output dir", s: converter{output: "x.mcap", outputDir: defaultOutputDir, outputDirSet: true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.s.outputPath()
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("outputPath() = %q, %v, want %q (error %t)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+// dirEntries returns the names of the files in dir.
+func dirEntries(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestOpenOutputOverwrite(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		path = filepath.Join(dir, "out.mcap")
+	)
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &converter{output: path}
+	if _, err := s.openOutput(); err == nil {
+		t.Fatal("openOutput() of an existing file without --force succeeded")
+	}
+
+	s.force = true
+	out, err := s.openOutput()
+	if err != nil {
+		t.Fatalf("openOutput() with --force error = %v", err)
+	}
+	if _, err := io.WriteString(out, "new"); err != nil {
+		t.Fatal(err)
+	}
+	// the existing file stays in place until Commit
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("output before Commit = %q, want old", data)
+	}
+	if err := out.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	out.Abort() // no-op after Commit
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("output after Commit = %q, want new", data)
+	}
+	if names := dirEntries(t, dir); len(names) != 1 {
+		t.Errorf("output dir holds %v, want out.mcap only", names)
+	}
+}
+
+func TestOpenOutputStdout(t *testing.T) {
+	out, err := (&converter{output: stdoutPath}).openOutput()
+	if err != nil {
+		t.Fatalf("openOutput() error = %v", err)
+	}
+	if out.Writer != os.Stdout || out.tmp != nil {
+		t.Errorf("openOutput() = %+v, want stdout", out)
+	}
+	if err := out.Commit(); err != nil {
+		t.Errorf("Commit() error = %v", err)
+	}
+}
+
+func TestOpenOutputAbort(t *testing.T) {
+	dir := t.TempDir()
+	out, err := (&converter{inputFile: "capture.log", outputDir: dir}).openOutput()
+	if err != nil {
+		t.Fatalf("openOutput() error = %v", err)
+	}
+	if _, err := io.WriteString(out, "partial"); err != nil {
+		t.Fatal(err)
+	}
+	out.Abort()
+	if names := dirEntries(t, dir); len(names) != 0 {
+		t.Errorf("output dir holds %v after Abort, want nothing", names)
+	}
+}
+
+func TestRunRemovesOutputOnFailure(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		dbcFile = filepath.Join(dir, "test.dbc")
+		logFile = filepath.Join(dir, "capture.log")
+		outDir  = filepath.Join(dir, "mcap")
+	)
+	if err := os.WriteFile(dbcFile, []byte("BO_ 291 A: 1 ECU\n SG_ S : 0|8@1+ (1,0) [0|255] \"\" ECU\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logFile, []byte("(1697040000.000000) can0 123#01\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newConverter()
+	s.dbcFile = dbcFile
+	s.inputFile = logFile
+	s.outputDir = outDir
+	// the conversion fails after the output was opened
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.run(ctx, cli.Input{Logger: *slog.New(slog.NewTextHandler(io.Discard, nil))}); err == nil {
+		t.Fatal("run() with a cancelled context succeeded")
+	}
+	if names := dirEntries(t, outDir); len(names) != 0 {
+		t.Errorf("output dir holds %v after a failed conversion, want nothing", names)
+	}
+}
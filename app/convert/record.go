@@ -0,0 +1,352 @@
+package convert
+
+import (
+	"time"
+
+	"go.einride.tech/can/pkg/descriptor"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/BIwashi/candecode/pkg/can"
+	"github.com/BIwashi/candecode/pkg/dbc"
+	candecodeproto "github.com/BIwashi/candecode/pkg/proto"
+	"github.com/BIwashi/candecode/pkg/timing"
+)
+
+const (
+	// channelModeSignal writes one channel per (bus, message, signal) with DecodedSignal records.
+	channelModeSignal = "signal"
+	// channelModeMessage writes one channel per (bus, message) with DecodedMessage records.
+	channelModeMessage = "message"
+	// channelModeTyped writes one channel per (bus, message) with records of a schema generated
+	// from the DBC, one typed field per signal.
+	channelModeTyped = "typed"
+)
+
+// newDecodedSignal builds the DecodedSignal record of one signal.
+// ok is false when the raw value has an unsupported type.
+func newDecodedSignal(
+	compiler *dbc.Compiler,
+	frame *can.TimedFrame,
+	messageName string,
+	sigName string,
+	sig dbc.DecodedSignal,
+	outcome dbc.LengthOutcome,
+) (*candecodeproto.DecodedSignal, bool) {
+	ds := &candecodeproto.DecodedSignal{
+		MessageName:    messageName,
+		Name:           sigName,
+		Timestamp:      timestamppb.New(sig.Timestamp),
+		CanId:          frame.ID,
+		IsExtended:     frame.IsExtended,
+		FrameBytes:     make([]byte, frame.Length),
+		IsFd:           frame.IsFD,
+		Brs:            frame.BRS,
+		Esi:            frame.ESI,
+		Bus:            frame.Interface,
+		InterfaceIndex: uint32(frame.InterfaceIndex),
+		Signal:         newSignalDefinition(compiler, sig.Signal),
+		LengthOutcome:  lengthOutcome(outcome),
+		OutOfRange:     sig.OutOfRange,
+		UndefinedEnum:  sig.UndefinedEnum,
+		Direction:      candecodeproto.Direction(frame.Direction),
+	}
+
+	// Physical
+	if sig.Physical != nil {
+		ds.Physical = sig.Physical
+	}
+	// Description (value description matched)
+	if sig.Description != "" {
+		ds.Description = sig.Description
+	}
+
+	// Raw oneof
+	if !setDecodedSignalRaw(ds, sig.Raw) {
+		return nil, false
+	}
+
+	copy(ds.FrameBytes, frame.Payload())
+
+	return ds, true
+}
+
+// newDecodedMessage builds the DecodedMessage record of one frame.
+func newDecodedMessage(
+	frame *can.TimedFrame,
+	messageName string,
+	msgDesc *descriptor.Message,
+	decoded map[string]dbc.DecodedSignal,
+	outcome dbc.LengthOutcome,
+) *candecodeproto.DecodedMessage {
+	dm := &candecodeproto.DecodedMessage{
+		Name:           messageName,
+		Timestamp:      timestamppb.New(frame.Timestamp),
+		CanId:          frame.ID,
+		IsExtended:     frame.IsExtended,
+		FrameBytes:     make([]byte, frame.Length),
+		IsFd:           frame.IsFD,
+		Brs:            frame.BRS,
+		Esi:            frame.ESI,
+		Bus:            frame.Interface,
+		InterfaceIndex: uint32(frame.InterfaceIndex),
+		LengthOutcome:  lengthOutcome(outcome),
+		Direction:      candecodeproto.Direction(frame.Direction),
+	}
+	copy(dm.FrameBytes, frame.Payload())
+
+	dm.Signals = newSignalValues(msgDesc, decoded)
+
+	return dm
+}
+
+// newRawFrame builds the RawFrame record of one captured frame.
+func newRawFrame(frame *can.TimedFrame) *candecodeproto.RawFrame {
+	rf := &candecodeproto.RawFrame{
+		Timestamp:      timestamppb.New(frame.Timestamp),
+		CanId:          frame.ID,
+		IsExtended:     frame.IsExtended,
+		IsRemote:       frame.IsRemote,
+		IsFd:           frame.IsFD,
+		Brs:            frame.BRS,
+		Esi:            frame.ESI,
+		Dlc:            uint32(frame.DLC()),
+		Data:           make([]byte, frame.Length),
+		Bus:            frame.Interface,
+		InterfaceIndex: uint32(frame.InterfaceIndex),
+		Direction:      candecodeproto.Direction(frame.Direction),
+	}
+	copy(rf.Data, frame.Payload())
+	return rf
+}
+
+// newTimingViolation converts a cycle time violation.
+func newTimingViolation(v timing.Violation) *candecodeproto.TimingViolation {
+	return &candecodeproto.TimingViolation{
+		Timestamp:      timestamppb.New(v.Timestamp),
+		Kind:           candecodeproto.TimingViolationKind(v.Kind),
+		Bus:            v.Bus,
+		InterfaceIndex: uint32(v.InterfaceIndex),
+		CanId:          v.Message.ID,
+		IsExtended:     v.Message.IsExtended,
+		Message:        v.Message.Name,
+		CycleTimeMs:    float64(v.Message.CycleTime) / float64(time.Millisecond),
+		GapMs:          float64(v.Gap) / float64(time.Millisecond),
+	}
+}
+
+// newSignalDefinition converts the DBC definition of s.
+func newSignalDefinition(compiler *dbc.Compiler, s *descriptor.Signal) *candecodeproto.Signal {
+	def := &candecodeproto.Signal{
+		Name:             s.Name,
+		Start:            uint32(compiler.StartBit(s)),
+		Length:           uint32(s.Length),
+		IsBigEndian:      s.IsBigEndian,
+		IsSigned:         s.IsSigned,
+		IsFloat:          s.IsFloat,
+		IsMultiplexer:    s.IsMultiplexer,
+		IsMultiplexed:    s.IsMultiplexed,
+		MultiplexerValue: uint32(s.MultiplexerValue),
+		Offset:           s.Offset,
+		Scale:            s.Scale,
+		Min:              s.Min,
+		Max:              s.Max,
+		Unit:             s.Unit,
+		Description:      s.Description,
+		DefaultValue:     int32(s.DefaultValue),
+		SourceFile:       compiler.SourceFile(),
+		Attributes:       attributeMap(compiler.SignalAttributes(s)),
+	}
+	// ValueDescriptions
+	for _, vd := range s.ValueDescriptions {
+		def.ValueDescriptions = append(def.ValueDescriptions, &candecodeproto.ValueDescription{
+			Value:       vd.Value,
+			Description: vd.Description,
+		})
+	}
+	// Receiver nodes
+	def.ReceiverNodes = append(def.ReceiverNodes, s.ReceiverNodes...)
+
+	return def
+}
+
+// newMessageDefinition converts the DBC definition of msgDesc as seen on the bus of frame.
+func newMessageDefinition(compiler *dbc.Compiler, frame *can.TimedFrame, msgDesc *descriptor.Message) *candecodeproto.MessageDefinition {
+	def := &candecodeproto.MessageDefinition{
+		Name:           msgDesc.Name,
+		CanId:          msgDesc.ID,
+		IsExtended:     msgDesc.IsExtended,
+		Length:         uint32(msgDesc.Length),
+		SenderNode:     msgDesc.SenderNode,
+		Description:    msgDesc.Description,
+		Bus:            frame.Interface,
+		InterfaceIndex: uint32(frame.InterfaceIndex),
+		SourceFile:     compiler.SourceFile(),
+		Attributes:     attributeMap(compiler.MessageAttributes(msgDesc)),
+	}
+	for _, s := range msgDesc.Signals {
+		def.Signals = append(def.Signals, newSignalDefinition(compiler, s))
+	}
+	return def
+}
+
+// attributeMap converts DBC attribute values, defaults included, to the attributes field of definitions.
+func attributeMap(attrs []dbc.Attribute) map[string]string {
+	if len(attrs) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		m[a.Name] = a.Value.String()
+	}
+	return m
+}
+
+// newSignalSample builds the compact record of one signal.
+// ok is false when the raw value has an unsupported type.
+func newSignalSample(sig dbc.DecodedSignal, outcome dbc.LengthOutcome) (*candecodeproto.SignalSample, bool) {
+	sample := &candecodeproto.SignalSample{
+		Timestamp:     timestamppb.New(sig.Timestamp),
+		Physical:      sig.Physical,
+		Description:   sig.Description,
+		LengthOutcome: lengthOutcome(outcome),
+		OutOfRange:    sig.OutOfRange,
+		UndefinedEnum: sig.UndefinedEnum,
+	}
+	if !setSignalSampleRaw(sample, sig.Raw) {
+		return nil, false
+	}
+	return sample, true
+}
+
+// newMessageSample builds the compact record of one frame. Units are omitted; they are part
+// of the MessageDefinition metadata.
+func newMessageSample(
+	frame *can.TimedFrame,
+	msgDesc *descriptor.Message,
+	decoded map[string]dbc.DecodedSignal,
+	outcome dbc.LengthOutcome,
+) *candecodeproto.MessageSample {
+	sample := &candecodeproto.MessageSample{
+		Timestamp:     timestamppb.New(frame.Timestamp),
+		LengthOutcome: lengthOutcome(outcome),
+	}
+	for _, sv := range newSignalValues(msgDesc, decoded) {
+		sv.Unit = ""
+		sample.Signals = append(sample.Signals, sv)
+	}
+	return sample
+}
+
+// newSignalValues converts the decoded signals of a frame in the DBC order of msgDesc,
+// so plots can rely on stable indices.
+func newSignalValues(msgDesc *descriptor.Message, decoded map[string]dbc.DecodedSignal) []*candecodeproto.SignalValue {
+	var values []*candecodeproto.SignalValue
+	for _, s := range msgDesc.Signals {
+		sig, ok := decoded[s.Name]
+		if !ok {
+			continue // multiplexed signal not present in this frame
+		}
+		sv := &candecodeproto.SignalValue{
+			Name:          s.Name,
+			Physical:      sig.Physical,
+			Description:   sig.Description,
+			Unit:          s.Unit,
+			OutOfRange:    sig.OutOfRange,
+			UndefinedEnum: sig.UndefinedEnum,
+		}
+		if sig.Missing {
+			// past the end of a short frame (lenient length policy)
+			sv.Missing = true
+			values = append(values, sv)
+			continue
+		}
+		if !setSignalValueRaw(sv, sig.Raw) {
+			continue
+		}
+		values = append(values, sv)
+	}
+
+	return values
+}
+
+// setDecodedSignalRaw sets the raw oneof of ds from the raw value of a decoded signal.
+// It returns false when the raw value has an unsupported type.
+func setDecodedSignalRaw(ds *candecodeproto.DecodedSignal, raw any) bool {
+	switch v := raw.(type) {
+	case bool:
+		ds.Raw = &candecodeproto.DecodedSignal_RawB{RawB: v}
+	case int64:
+		ds.Raw = &candecodeproto.DecodedSignal_RawS{RawS: v}
+	case uint64:
+		ds.Raw = &candecodeproto.DecodedSignal_RawU{RawU: v}
+	case float64:
+		ds.Raw = &candecodeproto.DecodedSignal_RawF{RawF: v}
+	case []byte:
+		ds.Raw = &candecodeproto.DecodedSignal_RawBytes{RawBytes: v}
+	default:
+		return false
+	}
+	return true
+}
+
+// setSignalSampleRaw sets the raw oneof of sample, like setDecodedSignalRaw.
+func setSignalSampleRaw(sample *candecodeproto.SignalSample, raw any) bool {
+	switch v := raw.(type) {
+	case bool:
+		sample.Raw = &candecodeproto.SignalSample_RawB{RawB: v}
+	case int64:
+		sample.Raw = &candecodeproto.SignalSample_RawS{RawS: v}
+	case uint64:
+		sample.Raw = &candecodeproto.SignalSample_RawU{RawU: v}
+	case float64:
+		sample.Raw = &candecodeproto.SignalSample_RawF{RawF: v}
+	case []byte:
+		sample.Raw = &candecodeproto.SignalSample_RawBytes{RawBytes: v}
+	default:
+		return false
+	}
+	return true
+}
+
+// setSignalValueRaw sets the raw oneof of sv, like setDecodedSignalRaw.
+func setSignalValueRaw(sv *candecodeproto.SignalValue, raw any) bool {
+	switch v := raw.(type) {
+	case bool:
+		sv.Raw = &candecodeproto.SignalValue_RawB{RawB: v}
+	case int64:
+		sv.Raw = &candecodeproto.SignalValue_RawS{RawS: v}
+	case uint64:
+		sv.Raw = &candecodeproto.SignalValue_RawU{RawU: v}
+	case float64:
+		sv.Raw = &candecodeproto.SignalValue_RawF{RawF: v}
+	case []byte:
+		sv.Raw = &candecodeproto.SignalValue_RawBytes{RawBytes: v}
+	default:
+		return false
+	}
+	return true
+}
+
+// lengthOutcome converts the decoder length outcome of a frame.
+func lengthOutcome(o dbc.LengthOutcome) candecodeproto.LengthOutcome {
+	switch o {
+	case dbc.LengthTruncated:
+		return candecodeproto.LengthOutcome_LENGTH_OUTCOME_TRUNCATED
+	case dbc.LengthPadded:
+		return candecodeproto.LengthOutcome_LENGTH_OUTCOME_PADDED
+	case dbc.LengthIgnored:
+		return candecodeproto.LengthOutcome_LENGTH_OUTCOME_IGNORED
+	default:
+		return candecodeproto.LengthOutcome_LENGTH_OUTCOME_MATCH
+	}
+}
+
+// newValidity converts the checksum and counter validation result of a frame.
+func newValidity(v dbc.Validity) *candecodeproto.Validity {
+	return &candecodeproto.Validity{
+		Valid:    v.Valid(),
+		Checksum: candecodeproto.ChecksumStatus(v.Checksum), // ChecksumStatus mirrors the proto enum
+		Counter:  candecodeproto.CounterStatus(v.Counter),   // CounterStatus mirrors the proto enum
+		Skipped:  v.Skipped,
+	}
+}
@@ -0,0 +1,75 @@
+package convert
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	candecodeproto "github.com/BIwashi/candecode/pkg/proto"
+)
+
+func TestSetRaw(t *testing.T) {
+	tests := []struct {
+		raw           any
+		decodedSignal *candecodeproto.DecodedSignal
+		signalSample  *candecodeproto.SignalSample
+		signalValue   *candecodeproto.SignalValue
+	}{
+		{
+			raw:           true,
+			decodedSignal: &candecodeproto.DecodedSignal{Raw: &candecodeproto.DecodedSignal_RawB{RawB: true}},
+			signalSample:  &candecodeproto.SignalSample{Raw: &candecodeproto.SignalSample_RawB{RawB: true}},
+			signalValue:   &candecodeproto.SignalValue{Raw: &candecodeproto.SignalValue_RawB{RawB: true}},
+		},
+		{
+			raw:           int64(-3),
+			decodedSignal: &candecodeproto.DecodedSignal{Raw: &candecodeproto.DecodedSignal_RawS{RawS: -3}},
+			signalSample:  &candecodeproto.SignalSample{Raw: &candecodeproto.SignalSample_RawS{RawS: -3}},
+			signalValue:   &candecodeproto.SignalValue{Raw: &candecodeproto.SignalValue_RawS{RawS: -3}},
+		},
+		{
+			raw:           uint64(7),
+			decodedSignal: &candecodeproto.DecodedSignal{Raw: &candecodeproto.DecodedSignal_RawU{RawU: 7}},
+			signalSample:  &candecodeproto.SignalSample{Raw: &candecodeproto.SignalSample_RawU{RawU: 7}},
+			signalValue:   &candecodeproto.SignalValue{Raw: &candecodeproto.SignalValue_RawU{RawU: 7}},
+		},
+		{
+			raw:           1.5,
+			decodedSignal: &candecodeproto.DecodedSignal{Raw: &candecodeproto.DecodedSignal_RawF{RawF: 1.5}},
+			signalSample:  &candecodeproto.SignalSample{Raw: &candecodeproto.SignalSample_RawF{RawF: 1.5}},
+			signalValue:   &candecodeproto.SignalValue{Raw: &candecodeproto.SignalValue_RawF{RawF: 1.5}},
+		},
+		{
+			raw:           []byte{1, 2},
+			decodedSignal: &candecodeproto.DecodedSignal{Raw: &candecodeproto.DecodedSignal_RawBytes{RawBytes: []byte{1, 2}}},
+			signalSample:  &candecodeproto.SignalSample{Raw: &candecodeproto.SignalSample_RawBytes{RawBytes: []byte{1, 2}}},
+			signalValue:   &candecodeproto.SignalValue{Raw: &candecodeproto.SignalValue_RawBytes{RawBytes: []byte{1, 2}}},
+		},
+	}
+	for _, tt := range tests {
+		var (
+			ds candecodeproto.DecodedSignal
+			ss candecodeproto.SignalSample
+			sv candecodeproto.SignalValue
+		)
+		if !setDecodedSignalRaw(&ds, tt.raw) || !proto.Equal(&ds, tt.decodedSignal) {
+			t.Errorf("DecodedSignal raw of %#v = %v, want %v", tt.raw, ds.Raw, tt.decodedSignal.Raw)
+		}
+		if !setSignalSampleRaw(&ss, tt.raw) || !proto.Equal(&ss, tt.signalSample) {
+			t.Errorf("SignalSample raw of %#v = %v, want %v", tt.raw, ss.Raw, tt.signalSample.Raw)
+		}
+		if !setSignalValueRaw(&sv, tt.raw) || !proto.Equal(&sv, tt.signalValue) {
+			t.Errorf("SignalValue raw of %#v = %v, want %v", tt.raw, sv.Raw, tt.signalValue.Raw)
+		}
+	}
+
+	var (
+		ds candecodeproto.DecodedSignal
+		ss candecodeproto.SignalSample
+		sv candecodeproto.SignalValue
+	)
+	if setDecodedSignalRaw(&ds, int32(1)) || setSignalSampleRaw(&ss, int32(1)) || setSignalValueRaw(&sv, int32(1)) ||
+		ds.Raw != nil || ss.Raw != nil || sv.Raw != nil {
+		t.Errorf("raw of an int32 = %v, %v, %v, want unsupported", ds.Raw, ss.Raw, sv.Raw)
+	}
+}
@@ -0,0 +1,213 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/BIwashi/candecode/pkg/can"
+	"github.com/BIwashi/candecode/pkg/dbc"
+)
+
+// Reasons a frame is not decoded, as used in the report.
+const (
+	reasonNoDBC          = "no_dbc"
+	reasonUnknownMessage = "unknown_message"
+	reasonShapeMismatch  = "shape_mismatch"
+	reasonRemoteFrame    = "remote_frame"
+	reasonOther          = "other"
+)
+
+// decodeReport counts decoded frames and collects the frames that could not be decoded,
+// per (bus, CAN ID). With --validate it also counts failed checksum and counter checks.
+type decodeReport struct {
+	Frames         uint64         `json:"frames"`
+	Decoded        uint64         `json:"decoded"`
+	Undecoded      uint64         `json:"undecoded"`
+	SkippedPackets uint64         `json:"skipped_packets"`
+	ChecksumErrors uint64         `json:"checksum_errors,omitempty"`
+	CounterSkips   uint64         `json:"counter_skips,omitempty"`
+	CounterRepeats uint64         `json:"counter_repeats,omitempty"`
+	UndecodedIDs   []*undecodedID `json:"undecoded_ids"`
+
+	ids map[undecodedKey]*undecodedID
+}
+
+type undecodedKey struct {
+	bus        string
+	id         uint32
+	isExtended bool
+}
+
+// undecodedID summarizes the undecoded frames of one CAN ID on one bus.
+type undecodedID struct {
+	Bus        string            `json:"bus"`
+	CanID      string            `json:"can_id"`
+	IsExtended bool              `json:"is_extended"`
+	Count      uint64            `json:"count"`
+	Reasons    map[string]uint64 `json:"reasons"`
+	DLCs       []int             `json:"dlcs"`
+	FirstSeen  time.Time         `json:"first_seen"`
+	LastSeen   time.Time         `json:"last_seen"`
+
+	id uint32
+}
+
+func newDecodeReport() *decodeReport {
+	return &decodeReport{
+		UndecodedIDs: []*undecodedID{},
+		ids:          make(map[undecodedKey]*undecodedID),
+	}
+}
+
+// addUndecoded records a frame rejected by the decoder with err.
+func (r *decodeReport) addUndecoded(frame *can.TimedFrame, err error) {
+	r.Undecoded++
+
+	key := undecodedKey{bus: frame.Interface, id: frame.ID, isExtended: frame.IsExtended}
+	u, ok := r.ids[key]
+	if !ok {
+		u = &undecodedID{
+			Bus:        frame.Interface,
+			CanID:      fmt.Sprintf("0x%X", frame.ID),
+			IsExtended: frame.IsExtended,
+			Reasons:    make(map[string]uint64),
+			FirstSeen:  frame.Timestamp,
+			LastSeen:   frame.Timestamp,
+			id:         frame.ID,
+		}
+		r.ids[key] = u
+		r.UndecodedIDs = append(r.UndecodedIDs, u)
+	}
+
+	u.Count++
+	u.Reasons[undecodedReason(err)]++
+	if dlc := int(frame.DLC()); !containsDLC(u.DLCs, dlc) {
+		u.DLCs = append(u.DLCs, dlc)
+		sort.Ints(u.DLCs)
+	}
+	if frame.Timestamp.Before(u.FirstSeen) {
+		u.FirstSeen = frame.Timestamp
+	}
+	if frame.Timestamp.After(u.LastSeen) {
+		u.LastSeen = frame.Timestamp
+	}
+}
+
+// addValidity counts the failed checks of a decoded frame.
+func (r *decodeReport) addValidity(v dbc.Validity) {
+	if v.Checksum == dbc.ChecksumInvalid {
+		r.ChecksumErrors++
+	}
+	switch v.Counter {
+	case dbc.CounterSkip:
+		r.CounterSkips++
+	case dbc.CounterRepeat:
+		r.CounterRepeats++
+	}
+}
+
+// finish orders the undecoded IDs by bus and CAN ID.
+func (r *decodeReport) finish(skippedPackets uint64) {
+	r.SkippedPackets = skippedPackets
+	sort.SliceStable(r.UndecodedIDs, func(i, j int) bool {
+		a, b := r.UndecodedIDs[i], r.UndecodedIDs[j]
+		if a.Bus != b.Bus {
+			return a.Bus < b.Bus
+		}
+		if a.id != b.id {
+			return a.id < b.id
+		}
+		return !a.IsExtended && b.IsExtended
+	})
+}
+
+// printTable writes the undecoded IDs as a table.
+func (r *decodeReport) printTable(w io.Writer) error {
+	fmt.Fprintf(w, "%d frames, %d decoded, %d undecoded, %d non-CAN packets skipped\n", //nolint:errcheck
+		r.Frames, r.Decoded, r.Undecoded, r.SkippedPackets)
+	if r.ChecksumErrors+r.CounterSkips+r.CounterRepeats > 0 {
+		fmt.Fprintf(w, "%d checksum errors, %d counter skips, %d counter repeats\n", //nolint:errcheck
+			r.ChecksumErrors, r.CounterSkips, r.CounterRepeats)
+	}
+	if len(r.UndecodedIDs) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BUS\tCAN ID\tCOUNT\tDLCS\tREASONS\tFIRST\tLAST") //nolint:errcheck
+	for _, u := range r.UndecodedIDs {
+		dlcs := make([]string, 0, len(u.DLCs))
+		for _, dlc := range u.DLCs {
+			dlcs = append(dlcs, fmt.Sprintf("%d", dlc))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n", //nolint:errcheck
+			u.Bus,
+			u.CanID,
+			u.Count,
+			strings.Join(dlcs, ","),
+			formatReasons(u.Reasons),
+			u.FirstSeen.UTC().Format(time.RFC3339Nano),
+			u.LastSeen.UTC().Format(time.RFC3339Nano),
+		)
+	}
+	return tw.Flush()
+}
+
+// writeJSON writes the report as JSON to path.
+func (r *decodeReport) writeJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal decode report")
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return errors.Wrap(err, "write decode report")
+	}
+	return nil
+}
+
+func undecodedReason(err error) string {
+	switch {
+	case errors.Is(err, dbc.ErrNoDBC):
+		return reasonNoDBC
+	case errors.Is(err, dbc.ErrUnknownMessage):
+		return reasonUnknownMessage
+	case errors.Is(err, dbc.ErrShapeMismatch):
+		return reasonShapeMismatch
+	case errors.Is(err, dbc.ErrRemoteFrame):
+		return reasonRemoteFrame
+	default:
+		return reasonOther
+	}
+}
+
+// formatReasons formats reason counts as reason=count, ordered by reason.
+func formatReasons(reasons map[string]uint64) string {
+	names := make([]string, 0, len(reasons))
+	for name := range reasons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, reasons[name]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func containsDLC(dlcs []int, dlc int) bool {
+	for _, d := range dlcs {
+		if d == dlc {
+			return true
+		}
+	}
+	return false
+}
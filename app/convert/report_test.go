@@ -0,0 +1,122 @@
+package convert
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/BIwashi/candecode/pkg/can"
+	"github.com/BIwashi/candecode/pkg/dbc"
+)
+
+func TestDecodeReport(t *testing.T) {
+	dir := t.TempDir()
+	dbcFile := filepath.Join(dir, "test.dbc")
+	if err := os.WriteFile(dbcFile, []byte("BO_ 256 ENGINE: 8 ECU\n SG_ RPM : 0|16@1+ (1,0) [0|65535] \"rpm\" ECU\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// can1 has no DBC
+	decoder, _, err := dbc.LoadDecoder("", []string{"can0=" + dbcFile})
+	if err != nil {
+		t.Fatalf("LoadDecoder() error = %v", err)
+	}
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	frame := func(ms int, bus string, f can.Frame) *can.TimedFrame {
+		return &can.TimedFrame{Frame: f, Timestamp: start.Add(time.Duration(ms) * time.Millisecond), Interface: bus}
+	}
+	frames := []*can.TimedFrame{
+		frame(0, "can0", can.Frame{ID: 0x100, Length: 8}),
+		frame(10, "can0", can.Frame{ID: 0x7FF, Length: 8}),
+		frame(20, "can0", can.Frame{ID: 0x7FF, Length: 2}),
+		frame(30, "can0", can.Frame{ID: 0x7FF, Length: 12, IsFD: true}),
+		frame(35, "can0", can.Frame{ID: 0x7FF, Length: 8}),
+		frame(40, "can0", can.Frame{ID: 0x100, Length: 4}),
+		frame(50, "can0", can.Frame{ID: 0x100, Length: 8, IsRemote: true}),
+		frame(60, "can0", can.Frame{ID: 0x100, Length: 8, IsExtended: true}),
+		frame(70, "can1", can.Frame{ID: 0x100, Length: 8}),
+		frame(80, "can1", can.Frame{ID: 0x100, Length: 8}),
+		frame(90, "can0", can.Frame{ID: 0x100, Length: 8}),
+	}
+
+	r := newDecodeReport()
+	for _, f := range frames {
+		r.Frames++
+		if _, err := decoder.Decode(f); err != nil {
+			r.addUndecoded(f, err)
+			continue
+		}
+		r.Decoded++
+	}
+	r.finish(3)
+
+	path := filepath.Join(dir, "report.json")
+	if err := r.writeJSON(path); err != nil {
+		t.Fatalf("writeJSON() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{
+  "frames": 11,
+  "decoded": 2,
+  "undecoded": 9,
+  "skipped_packets": 3,
+  "undecoded_ids": [
+    {
+      "bus": "can0",
+      "can_id": "0x100",
+      "is_extended": false,
+      "count": 2,
+      "reasons": {"remote_frame": 1, "shape_mismatch": 1},
+      "dlcs": [4, 8],
+      "first_seen": "2024-05-01T12:00:00.04Z",
+      "last_seen": "2024-05-01T12:00:00.05Z"
+    },
+    {
+      "bus": "can0",
+      "can_id": "0x100",
+      "is_extended": true,
+      "count": 1,
+      "reasons": {"shape_mismatch": 1},
+      "dlcs": [8],
+      "first_seen": "2024-05-01T12:00:00.06Z",
+      "last_seen": "2024-05-01T12:00:00.06Z"
+    },
+    {
+      "bus": "can0",
+      "can_id": "0x7FF",
+      "is_extended": false,
+      "count": 4,
+      "reasons": {"unknown_message": 4},
+      "dlcs": [2, 8, 9],
+      "first_seen": "2024-05-01T12:00:00.01Z",
+      "last_seen": "2024-05-01T12:00:00.035Z"
+    },
+    {
+      "bus": "can1",
+      "can_id": "0x100",
+      "is_extended": false,
+      "count": 2,
+      "reasons": {"no_dbc": 2},
+      "dlcs": [8],
+      "first_seen": "2024-05-01T12:00:00.07Z",
+      "last_seen": "2024-05-01T12:00:00.08Z"
+    }
+  ]
+}`
+	var got, wantReport any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal report %s: %v", data, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantReport); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, wantReport) {
+		t.Errorf("report = %s\nwant %s", data, want)
+	}
+}
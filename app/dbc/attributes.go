@@ -0,0 +1,198 @@
+package dbc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/BIwashi/candecode/pkg/cli"
+	candecodedbc "github.com/BIwashi/candecode/pkg/dbc"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+type attributeLister struct {
+	dbcFile  string
+	format   string
+	defaults bool
+}
+
+func newAttributesCommand() *cobra.Command {
+	s := &attributeLister{
+		dbcFile:  "",
+		format:   formatTable,
+		defaults: true,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "attributes",
+		Short: "List the attributes of a DBC file.",
+		Long: `
+List the attributes of a DBC file.
+
+The attribute definitions (BA_DEF_) are printed with their type, range or enum values and
+default (BA_DEF_DEF_), followed by the attribute values of the network, nodes, messages,
+signals and environment variables. Objects without an explicit value (BA_) get the default of
+the definition; use --defaults=false to list explicit values only.`,
+		Example: `
+# List the attributes of a DBC file
+candecode dbc attributes --dbc-file reference.dbc
+
+# Explicit values only, as JSON
+candecode dbc attributes --dbc-file reference.dbc --defaults=false --format json`,
+		RunE: cli.WithContext(s.run),
+	}
+
+	cmd.Flags().StringVar(&s.dbcFile, "dbc-file", s.dbcFile, "DBC file")
+	cmd.Flags().StringVar(&s.format, "format", s.format, "Output format. Available values: table, json")
+	cmd.Flags().BoolVar(&s.defaults, "defaults", s.defaults, "List default values of objects without an explicit value")
+
+	if err := cmd.MarkFlagRequired("dbc-file"); err != nil {
+		fmt.Printf("failed to mark flag as required, err: %v", err)
+
+		return nil
+	}
+
+	return cmd
+}
+
+// attributeListing is the JSON output of the attributes command.
+type attributeListing struct {
+	DBCFile     string                `json:"dbc_file"`
+	Definitions []attributeDefinition `json:"definitions"`
+	Values      []attributeValue      `json:"values"`
+}
+
+type attributeDefinition struct {
+	Name       string   `json:"name"`
+	Scope      string   `json:"scope"`
+	Type       string   `json:"type"`
+	Min        float64  `json:"min,omitempty"`
+	Max        float64  `json:"max,omitempty"`
+	EnumValues []string `json:"enum_values,omitempty"`
+	Default    *string  `json:"default,omitempty"`
+}
+
+type attributeValue struct {
+	Scope string `json:"scope"`
+	// Object is empty for network attributes, <Message> or <Message>.<Signal> for messages and signals.
+	Object    string `json:"object,omitempty"`
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	IsDefault bool   `json:"is_default,omitempty"`
+}
+
+func (s *attributeLister) run(_ context.Context, input cli.Input) error {
+	if s.format != formatTable && s.format != formatJSON {
+		return fmt.Errorf("invalid --format %q, expected %s or %s", s.format, formatTable, formatJSON)
+	}
+
+	compiler, err := candecodedbc.NewCompiler(s.dbcFile)
+	if err != nil {
+		return fmt.Errorf("failed to create DBC compiler: %w", err)
+	}
+
+	listing := attributeListing{DBCFile: s.dbcFile}
+	for _, ad := range compiler.AttributeDefinitions() {
+		def := attributeDefinition{
+			Name:       ad.Name,
+			Scope:      ad.Scope.String(),
+			Type:       string(ad.Type),
+			Min:        ad.Min,
+			Max:        ad.Max,
+			EnumValues: ad.EnumValues,
+		}
+		if ad.HasDefault {
+			value := ad.Default.String()
+			def.Default = &value
+		}
+		listing.Definitions = append(listing.Definitions, def)
+	}
+
+	add := func(scope candecodedbc.AttributeScope, object string, attrs []candecodedbc.Attribute) {
+		for _, a := range attrs {
+			if a.IsDefault && !s.defaults {
+				continue
+			}
+			listing.Values = append(listing.Values, attributeValue{
+				Scope:     scope.String(),
+				Object:    object,
+				Name:      a.Name,
+				Value:     a.Value.String(),
+				IsDefault: a.IsDefault,
+			})
+		}
+	}
+	add(candecodedbc.AttributeScopeNetwork, "", compiler.NetworkAttributes())
+	for _, node := range compiler.Nodes() {
+		add(candecodedbc.AttributeScopeNode, node.Name, compiler.NodeAttributes(node.Name))
+	}
+	for _, m := range compiler.Messages() {
+		add(candecodedbc.AttributeScopeMessage, m.Name, compiler.MessageAttributes(m))
+		for _, sig := range m.Signals {
+			add(candecodedbc.AttributeScopeSignal, m.Name+"."+sig.Name, compiler.SignalAttributes(sig))
+		}
+	}
+	for _, envVar := range compiler.EnvVars() {
+		add(candecodedbc.AttributeScopeEnvVar, envVar, compiler.EnvVarAttributes(envVar))
+	}
+
+	input.Logger.Debug("Listed DBC attributes", "dbc_file", s.dbcFile,
+		"definitions", len(listing.Definitions), "values", len(listing.Values))
+
+	if s.format == formatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(listing)
+	}
+	return listing.printTable(os.Stdout)
+}
+
+// printTable writes the definitions and values as human readable tables.
+func (l *attributeListing) printTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SCOPE\tNAME\tTYPE\tRANGE\tDEFAULT") //nolint:errcheck
+	for _, d := range l.Definitions {
+		var (
+			values = fmt.Sprintf("%g..%g", d.Min, d.Max)
+			def    = "-"
+		)
+		switch {
+		case d.Type == string(candecodedbc.AttributeTypeEnum):
+			values = strings.Join(d.EnumValues, ",")
+		case d.Type == string(candecodedbc.AttributeTypeString), d.Min == 0 && d.Max == 0:
+			values = "-"
+		}
+		if d.Default != nil {
+			def = fmt.Sprintf("%q", *d.Default)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", d.Scope, d.Name, d.Type, values, def) //nolint:errcheck
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w) //nolint:errcheck
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SCOPE\tOBJECT\tATTRIBUTE\tVALUE") //nolint:errcheck
+	for _, v := range l.Values {
+		object, value := v.Object, fmt.Sprintf("%q", v.Value)
+		if object == "" {
+			object = "-"
+		}
+		if v.IsDefault {
+			value += " (default)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", v.Scope, object, v.Name, value) //nolint:errcheck
+	}
+	return tw.Flush()
+}
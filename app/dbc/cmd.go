@@ -0,0 +1,19 @@
+package dbc
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dbc",
+		Short: "Inspect and validate DBC files.",
+	}
+
+	cmd.AddCommand(
+		newLintCommand(),
+		newAttributesCommand(),
+	)
+
+	return cmd
+}
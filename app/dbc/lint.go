@@ -0,0 +1,97 @@
+package dbc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/BIwashi/candecode/pkg/cli"
+	candecodedbc "github.com/BIwashi/candecode/pkg/dbc"
+)
+
+type linter struct {
+	dbcFiles []string
+	strict   bool
+}
+
+func newLintCommand() *cobra.Command {
+	s := &linter{
+		dbcFiles: nil,
+		strict:   false,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Report problems in DBC files.",
+		Long: `
+Report problems in DBC files.
+
+Besides the diagnostics of the DBC compiler (undeclared objects referenced by comments,
+attributes or value descriptions, bad float lengths, unsupported value types), lint checks for
+overlapping signal bits, signals past the message length, duplicate message IDs,
+min/max ranges the signal bit width cannot reach and multiplexer values the switch cannot reach.
+
+With --strict the command exits non-zero when any problem is found.`,
+		Example: `
+# Lint a DBC file
+candecode dbc lint --dbc-file reference.dbc
+
+# Gate DBC changes in CI
+candecode dbc lint --strict --dbc-file toyota_nodsu_pt.dbc --dbc-file toyota_adas.dbc`,
+		RunE: cli.WithContext(s.run),
+	}
+
+	cmd.Flags().StringArrayVar(&s.dbcFiles, "dbc-file", s.dbcFiles, "DBC file (repeatable)")
+	cmd.Flags().BoolVar(&s.strict, "strict", s.strict, "Exit non-zero when any problem is found")
+
+	if err := cmd.MarkFlagRequired("dbc-file"); err != nil {
+		fmt.Printf("failed to mark flag as required, err: %v", err)
+
+		return nil
+	}
+
+	return cmd
+}
+
+func (s *linter) run(_ context.Context, input cli.Input) error {
+	return s.lint(os.Stdout, input)
+}
+
+// lint writes the problems of every DBC file to w.
+func (s *linter) lint(w io.Writer, input cli.Input) error {
+	var (
+		problems int
+		failed   int
+	)
+	for _, path := range s.dbcFiles {
+		compiler, err := candecodedbc.NewCompiler(path)
+		if err != nil {
+			fmt.Fprintf(w, "%s: error: %v\n", path, err)
+			failed++
+			continue
+		}
+
+		diags := compiler.Lint()
+		for _, d := range diags {
+			fmt.Fprintln(w, d.String())
+		}
+		problems += len(diags)
+
+		input.Logger.Debug("Linted DBC file", "dbc_file", path, "problems", len(diags))
+	}
+
+	fmt.Fprintf(w, "%d problem(s) in %d file(s)\n", problems+failed, len(s.dbcFiles))
+
+	if failed > 0 {
+		return errors.Newf("failed to compile %d DBC file(s)", failed)
+	}
+	if s.strict && problems > 0 {
+		return errors.Newf("found %d problem(s)", problems)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package dbc
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BIwashi/candecode/pkg/cli"
+)
+
+func TestLintStrict(t *testing.T) {
+	dir := t.TempDir()
+	clean := filepath.Join(dir, "clean.dbc")
+	overlap := filepath.Join(dir, "overlap.dbc")
+	for path, source := range map[string]string{
+		clean: `
+BO_ 100 A: 8 ECU
+ SG_ S1 : 0|8@1+ (1,0) [0|255] "" ECU
+`,
+		overlap: `
+BO_ 100 A: 8 ECU
+ SG_ S1 : 0|8@1+ (1,0) [0|255] "" ECU
+ SG_ S2 : 4|8@1+ (1,0) [0|255] "" ECU
+`,
+	} {
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		files    []string
+		strict   bool
+		wantErr  bool
+		wantLast string
+	}{
+		{name: "clean", files: []string{clean}, strict: true, wantLast: "0 problem(s) in 1 file(s)"},
+		{name: "problems", files: []string{clean, overlap}, wantLast: "1 problem(s) in 2 file(s)"},
+		{name: "problems with --strict", files: []string{clean, overlap}, strict: true, wantErr: true, wantLast: "1 problem(s) in 2 file(s)"},
+		{name: "missing file", files: []string{filepath.Join(dir, "missing.dbc")}, wantErr: true, wantLast: "1 problem(s) in 1 file(s)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				out   bytes.Buffer
+				s     = &linter{dbcFiles: tt.files, strict: tt.strict}
+				input = cli.Input{Logger: *slog.New(slog.NewTextHandler(io.Discard, nil))}
+			)
+			err := s.lint(&out, input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("lint() error = %v, want error %t", err, tt.wantErr)
+			}
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if last := lines[len(lines)-1]; last != tt.wantLast {
+				t.Errorf("lint() output =\n%s\nwant last line %q", out.String(), tt.wantLast)
+			}
+		})
+	}
+}
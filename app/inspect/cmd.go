@@ -0,0 +1,201 @@
+package inspect
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/BIwashi/candecode/pkg/capture"
+	"github.com/BIwashi/candecode/pkg/cli"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+type inspector struct {
+	inputFile   string
+	inputFormat string
+	ascChannels []string
+	format      string
+	bitrate     int
+	dataBitrate int
+}
+
+func NewCommand() *cobra.Command {
+	s := &inspector{
+		inputFile:   "",
+		inputFormat: capture.FormatAuto,
+		ascChannels: nil,
+		format:      formatTable,
+		bitrate:     500_000,
+		dataBitrate: 2_000_000,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Print statistics of a CAN capture without a DBC file.",
+		Long: `
+Print statistics of a CAN capture (PCAPNG, candump log or Vector ASC trace) without a DBC file.
+
+The report lists the capture interfaces (with their link types for PCAPNG), the time span and frame counts.
+Per CAN ID it shows the frame count, mean rate and period, jitter (standard deviation of the
+period), DLC histogram and remote / error / FD frame counts.
+
+Bus load per interface is estimated from the frame lengths (including worst case bit stuffing)
+and --bitrate / --data-bitrate, relative to the time between the first and last frame on it.`,
+		Example: `
+# Overview of a capture
+candecode inspect --pcapng-file capture.pcapng
+
+# JSON for scripts, on a 1 Mbit/s bus
+candecode inspect --pcapng-file capture.pcapng --format json --bitrate 1000000
+
+# A Vector ASC trace
+candecode inspect --input-file trace.asc --asc-channel 1=can0`,
+		RunE: cli.WithContext(s.run),
+	}
+
+	cmd.Flags().StringVar(&s.inputFile, "input-file", s.inputFile, "Capture file: PCAPNG, candump log or Vector ASC trace")
+	cmd.Flags().StringVar(&s.inputFile, "pcapng-file", s.inputFile, "Capture file (alias of --input-file)")
+	cmd.Flags().StringVar(&s.inputFormat, "input-format", s.inputFormat,
+		"Format of the capture file. Available values: auto (detected from the contents), pcapng, candump, asc")
+	cmd.Flags().StringArrayVar(&s.ascChannels, "asc-channel", s.ascChannels,
+		"Bus name of a Vector ASC channel as channel=bus, e.g. 1=can0 (repeatable; default CAN<channel>)")
+	cmd.Flags().StringVar(&s.format, "format", s.format, "Output format. Available values: table, json")
+	cmd.Flags().IntVar(&s.bitrate, "bitrate", s.bitrate, "Nominal (arbitration) bit rate in bit/s, for the bus load estimate")
+	cmd.Flags().IntVar(&s.dataBitrate, "data-bitrate", s.dataBitrate, "CAN FD data phase bit rate in bit/s, for the bus load estimate")
+
+	return cmd
+}
+
+func (s *inspector) run(ctx context.Context, input cli.Input) error {
+	if s.inputFile == "" {
+		return errors.New("--input-file (or --pcapng-file) is required")
+	}
+	if s.format != formatTable && s.format != formatJSON {
+		return fmt.Errorf("invalid --format %q, expected %s or %s", s.format, formatTable, formatJSON)
+	}
+	if s.bitrate <= 0 || s.dataBitrate <= 0 {
+		return errors.New("--bitrate and --data-bitrate must be positive")
+	}
+
+	inputFile, err := os.Open(s.inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer inputFile.Close() //nolint:errcheck
+
+	opts, err := capture.ParseChannels(s.ascChannels)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, capture.WithFormat(s.inputFormat), capture.WithErrorFrames())
+	reader, inputFormat, err := capture.NewReader(bufio.NewReader(inputFile), opts...)
+	if err != nil {
+		return err
+	}
+
+	var (
+		stats  = newCaptureStats()
+		timing = busTiming{bitrate: float64(s.bitrate), dataBitrate: float64(s.dataBitrate)}
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "inspection cancelled")
+		default:
+		}
+
+		frame, err := reader.ReadFrame()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("failed to read frame: %w", err)
+		}
+		stats.add(frame, timing)
+	}
+	stats.finish(reader)
+
+	input.Logger.Debug("Inspected input file", "input_file", s.inputFile, "input_format", inputFormat, "frames", stats.Frames)
+
+	if s.format == formatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+	return stats.printTable(os.Stdout)
+}
+
+// printTable writes the statistics as human readable tables.
+func (c *captureStats) printTable(w io.Writer) error {
+	fmt.Fprintf(w, "Packets: %d (%d without a CAN frame)\n", c.Packets, c.SkippedPackets) //nolint:errcheck
+	fmt.Fprintf(w, "Frames:  %d\n", c.Frames)                                             //nolint:errcheck
+	if c.Frames > 0 {
+		fmt.Fprintf(w, "Start:   %s\n", c.Start.UTC().Format(time.RFC3339Nano)) //nolint:errcheck
+		fmt.Fprintf(w, "End:     %s\n", c.End.UTC().Format(time.RFC3339Nano))   //nolint:errcheck
+		fmt.Fprintf(w, "Span:    %s\n", c.End.Sub(c.Start))                     //nolint:errcheck
+	}
+
+	fmt.Fprintln(w) //nolint:errcheck
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "INTERFACE\tNAME\tLINK TYPE\tFRAMES\tFD\tERRORS\tBUS LOAD") //nolint:errcheck
+	for _, iface := range c.Interfaces {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%d\t%.1f%%\n", //nolint:errcheck
+			iface.Index, iface.Name, orDash(iface.LinkType), iface.Frames, iface.FDFrames, iface.Errors, iface.BusLoad*100)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w) //nolint:errcheck
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "INTERFACE\tCAN ID\tCOUNT\tRATE (HZ)\tPERIOD (MS)\tJITTER (MS)\tDLCS\tREMOTE\tERRORS\tFD") //nolint:errcheck
+	for _, s := range c.IDs {
+		id := s.CanID
+		switch {
+		case s.key.isError:
+			id += " (error)"
+		case s.IsExtended:
+			id += " (ext)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.2f\t%.2f\t%.2f\t%s\t%d\t%d\t%d\n", //nolint:errcheck
+			s.Interface, id, s.Count, s.RateHz, s.MeanPeriodMs, s.JitterMs, formatDLCs(s.DLCs), s.Remote, s.Errors, s.FD)
+	}
+	return tw.Flush()
+}
+
+// formatDLCs formats a DLC histogram as dlc:count, ordered by DLC.
+func formatDLCs(dlcs map[uint8]uint64) string {
+	keys := make([]int, 0, len(dlcs))
+	for dlc := range dlcs {
+		keys = append(keys, int(dlc))
+	}
+	sort.Ints(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, dlc := range keys {
+		parts = append(parts, fmt.Sprintf("%d:%d", dlc, dlcs[uint8(dlc)]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// orDash returns s, or - when it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,248 @@
+package inspect
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/BIwashi/candecode/pkg/can"
+	"github.com/BIwashi/candecode/pkg/capture"
+	"github.com/BIwashi/candecode/pkg/pcapng"
+)
+
+// captureStats summarizes a capture. Exported fields make up the JSON output.
+type captureStats struct {
+	Packets        uint64            `json:"packets"`
+	SkippedPackets uint64            `json:"skipped_packets"`
+	Frames         uint64            `json:"frames"`
+	Start          time.Time         `json:"start"`
+	End            time.Time         `json:"end"`
+	Duration       float64           `json:"duration_seconds"`
+	Interfaces     []*interfaceStats `json:"interfaces"`
+	IDs            []*idStats        `json:"ids"`
+
+	interfaces map[int]*interfaceStats
+	ids        map[idKey]*idStats
+}
+
+// interfaceStats summarizes one capture interface (bus).
+type interfaceStats struct {
+	Index    int     `json:"index"`
+	Name     string  `json:"name"`
+	LinkType string  `json:"link_type,omitempty"` // PCAPNG only
+	Frames   uint64  `json:"frames"`
+	FDFrames uint64  `json:"fd_frames"`
+	Errors   uint64  `json:"error_frames"`
+	BusLoad  float64 `json:"bus_load"` // fraction of the capture span the bus was busy
+
+	start    time.Time
+	end      time.Time
+	busyTime float64 // seconds
+}
+
+type idKey struct {
+	iface      int
+	id         uint32
+	isExtended bool
+	isError    bool
+}
+
+// idStats summarizes the frames of one CAN ID on one interface.
+type idStats struct {
+	Interface    string           `json:"interface"`
+	CanID        string           `json:"can_id"`
+	IsExtended   bool             `json:"is_extended"`
+	Count        uint64           `json:"count"`
+	RateHz       float64          `json:"rate_hz"`
+	MeanPeriodMs float64          `json:"mean_period_ms"`
+	JitterMs     float64          `json:"jitter_ms"` // standard deviation of the period
+	DLCs         map[uint8]uint64 `json:"dlcs"`
+	Remote       uint64           `json:"remote_frames"`
+	Errors       uint64           `json:"error_frames"`
+	FD           uint64           `json:"fd_frames"`
+
+	key  idKey
+	last time.Time
+	// Welford accumulators of the inter-arrival period in seconds
+	periods    uint64
+	periodMean float64
+	periodM2   float64
+}
+
+// busTiming holds the bit rates used to estimate bus load.
+type busTiming struct {
+	bitrate     float64
+	dataBitrate float64
+}
+
+func newCaptureStats() *captureStats {
+	return &captureStats{
+		Interfaces: []*interfaceStats{},
+		IDs:        []*idStats{},
+		interfaces: make(map[int]*interfaceStats),
+		ids:        make(map[idKey]*idStats),
+	}
+}
+
+// add accounts one frame.
+func (c *captureStats) add(frame *can.TimedFrame, timing busTiming) {
+	c.Frames++
+	if c.Start.IsZero() || frame.Timestamp.Before(c.Start) {
+		c.Start = frame.Timestamp
+	}
+	if frame.Timestamp.After(c.End) {
+		c.End = frame.Timestamp
+	}
+
+	iface, ok := c.interfaces[frame.InterfaceIndex]
+	if !ok {
+		iface = &interfaceStats{
+			Index: frame.InterfaceIndex,
+			Name:  frame.Interface,
+			start: frame.Timestamp,
+		}
+		c.interfaces[frame.InterfaceIndex] = iface
+	}
+	iface.Frames++
+	if frame.IsFD {
+		iface.FDFrames++
+	}
+	if frame.IsError {
+		iface.Errors++
+	} else {
+		iface.busyTime += frameDuration(&frame.Frame, timing)
+	}
+	if frame.Timestamp.Before(iface.start) {
+		iface.start = frame.Timestamp
+	}
+	if frame.Timestamp.After(iface.end) {
+		iface.end = frame.Timestamp
+	}
+
+	key := idKey{iface: frame.InterfaceIndex, id: frame.ID, isExtended: frame.IsExtended, isError: frame.IsError}
+	s, ok := c.ids[key]
+	if !ok {
+		s = &idStats{
+			Interface:  frame.Interface,
+			CanID:      fmt.Sprintf("0x%X", frame.ID),
+			IsExtended: frame.IsExtended,
+			DLCs:       make(map[uint8]uint64),
+			key:        key,
+		}
+		c.ids[key] = s
+	}
+	if s.Count > 0 {
+		s.addPeriod(frame.Timestamp.Sub(s.last).Seconds())
+	}
+	s.last = frame.Timestamp
+	s.Count++
+	s.DLCs[frame.DLC()]++
+	if frame.IsRemote {
+		s.Remote++
+	}
+	if frame.IsError {
+		s.Errors++
+	}
+	if frame.IsFD {
+		s.FD++
+	}
+}
+
+// addPeriod updates the running mean and variance of the inter-arrival period (Welford).
+func (s *idStats) addPeriod(period float64) {
+	s.periods++
+	delta := period - s.periodMean
+	s.periodMean += delta / float64(s.periods)
+	s.periodM2 += delta * (period - s.periodMean)
+}
+
+// finish fills the derived fields and orders interfaces by index and IDs by interface and CAN ID.
+func (c *captureStats) finish(reader capture.Reader) {
+	c.Packets = reader.GetPacketCount()
+	c.SkippedPackets = reader.GetSkippedCount()
+	c.Duration = c.End.Sub(c.Start).Seconds()
+
+	// PCAPNG interfaces without frames are listed too; candump and ASC buses only exist with frames
+	if pcapngReader, ok := reader.(*pcapng.Reader); ok {
+		for _, ri := range pcapngReader.Interfaces() {
+			iface, ok := c.interfaces[ri.Index]
+			if !ok {
+				iface = &interfaceStats{Index: ri.Index, Name: ri.Name}
+				c.interfaces[ri.Index] = iface
+			}
+			iface.LinkType = ri.LinkType.String()
+		}
+	}
+	for _, iface := range c.interfaces {
+		if span := iface.end.Sub(iface.start).Seconds(); span > 0 {
+			iface.BusLoad = iface.busyTime / span
+		}
+		c.Interfaces = append(c.Interfaces, iface)
+	}
+	sort.Slice(c.Interfaces, func(i, j int) bool { return c.Interfaces[i].Index < c.Interfaces[j].Index })
+
+	for _, s := range c.ids {
+		if s.periods > 0 {
+			s.MeanPeriodMs = s.periodMean * 1e3
+			s.JitterMs = math.Sqrt(s.periodM2/float64(s.periods)) * 1e3
+			if s.periodMean > 0 {
+				s.RateHz = 1 / s.periodMean
+			}
+		}
+		c.IDs = append(c.IDs, s)
+	}
+	sort.Slice(c.IDs, func(i, j int) bool {
+		a, b := c.IDs[i].key, c.IDs[j].key
+		switch {
+		case a.iface != b.iface:
+			return a.iface < b.iface
+		case a.isError != b.isError:
+			return !a.isError
+		case a.id != b.id:
+			return a.id < b.id
+		default:
+			return !a.isExtended && b.isExtended
+		}
+	})
+}
+
+// frameDuration estimates the time a frame occupies the bus in seconds, including worst case
+// bit stuffing, the interframe space and, for CAN FD frames with BRS, the faster data phase.
+func frameDuration(f *can.Frame, timing busTiming) float64 {
+	dataBits := 8 * float64(f.Length)
+	if f.IsRemote {
+		dataBits = 0
+	}
+
+	if !f.IsFD {
+		// SOF..CRC delimiter are stuffed; ACK, EOF and IFS (13 bits) are not
+		stuffed := 34 + dataBits
+		if f.IsExtended {
+			stuffed = 54 + dataBits
+		}
+		bits := stuffed + math.Floor((stuffed-1)/4) + 13
+		return bits / timing.bitrate
+	}
+
+	// Arbitration phase: SOF, ID, RRS, IDE, FDF, res, BRS (+ SRR and 18 ID bits when extended),
+	// plus CRC delimiter, ACK, EOF and IFS at the end of the frame
+	arbitration := 17.0 + 13
+	if f.IsExtended {
+		arbitration += 19
+	}
+	// Data phase: ESI, DLC, data, stuff count and CRC (17 or 21 bits), plus worst case stuffing
+	// of the data and the fixed stuff bits of the CRC
+	crc := 17.0
+	if f.Length > 16 {
+		crc = 21
+	}
+	data := 1 + 4 + dataBits + 4 + crc
+	data += math.Floor(dataBits/4) + math.Ceil(crc/4)
+
+	dataRate := timing.bitrate
+	if f.BRS {
+		dataRate = timing.dataBitrate
+	}
+	return arbitration/timing.bitrate + data/dataRate
+}
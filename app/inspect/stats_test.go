@@ -0,0 +1,111 @@
+package inspect
+
+import (
+	"bufio"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/BIwashi/candecode/pkg/can"
+	"github.com/BIwashi/candecode/pkg/capture"
+)
+
+func inspectLog(t *testing.T, log string, timing busTiming) *captureStats {
+	t.Helper()
+	reader, _, err := capture.NewReader(bufio.NewReader(strings.NewReader(log)), capture.WithErrorFrames())
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	stats := newCaptureStats()
+	for {
+		frame, err := reader.ReadFrame()
+		if err != nil {
+			break
+		}
+		stats.add(frame, timing)
+	}
+	stats.finish(reader)
+	return stats
+}
+
+func TestCaptureStatsJitter(t *testing.T) {
+	// periods of 10, 20, 10 and 20 ms: mean 15 ms, standard deviation 5 ms
+	stats := inspectLog(t, `(1697040000.000000) can0 123#00
+(1697040000.010000) can0 123#00
+(1697040000.030000) can0 123#00
+(1697040000.040000) can0 123#00
+(1697040000.060000) can0 123#00
+(1697040000.000000) can0 456#00
+`, busTiming{bitrate: 500_000, dataBitrate: 2_000_000})
+
+	if len(stats.IDs) != 2 {
+		t.Fatalf("IDs = %d, want 2", len(stats.IDs))
+	}
+	s := stats.IDs[0]
+	if s.CanID != "0x123" || s.Count != 5 {
+		t.Fatalf("IDs[0] = %s x %d, want 0x123 x 5", s.CanID, s.Count)
+	}
+	if math.Abs(s.MeanPeriodMs-15) > 1e-6 || math.Abs(s.JitterMs-5) > 1e-6 || math.Abs(s.RateHz-1000.0/15) > 1e-6 {
+		t.Errorf("period = %g ms, jitter = %g ms, rate = %g Hz, want 15, 5, %g", s.MeanPeriodMs, s.JitterMs, s.RateHz, 1000.0/15)
+	}
+	// a single frame has no period
+	if s := stats.IDs[1]; s.MeanPeriodMs != 0 || s.JitterMs != 0 || s.RateHz != 0 {
+		t.Errorf("IDs[1] period = %g ms, jitter = %g ms, rate = %g Hz, want 0", s.MeanPeriodMs, s.JitterMs, s.RateHz)
+	}
+}
+
+func TestFrameDuration(t *testing.T) {
+	timing := busTiming{bitrate: 500_000, dataBitrate: 2_000_000}
+	tests := []struct {
+		name  string
+		frame can.Frame
+		want  float64 // seconds
+	}{
+		// 34 + 64 stuffed bits, 24 stuff bits, 13 bits ACK..IFS
+		{"classic 8 bytes", can.Frame{Length: 8}, 135.0 / 500_000},
+		// 54 + 64 stuffed bits, 29 stuff bits, 13 bits ACK..IFS
+		{"extended 8 bytes", can.Frame{IsExtended: true, Length: 8}, 160.0 / 500_000},
+		// no data bits, whatever the length
+		{"remote", can.Frame{IsRemote: true, Length: 8}, 55.0 / 500_000},
+		{"classic empty", can.Frame{}, 55.0 / 500_000},
+		// 30 arbitration bits; 1 + 4 + 64 + 4 + 17 data phase bits, 16 + 5 stuff bits at the nominal rate
+		{"FD 8 bytes", can.Frame{IsFD: true, Length: 8}, (30.0 + 111) / 500_000},
+		// 30 arbitration bits; 1 + 4 + 512 + 4 + 21 data phase bits, 128 + 6 stuff bits at the data rate
+		{"FD BRS 64 bytes", can.Frame{IsFD: true, BRS: true, Length: 64}, 30.0/500_000 + 676.0/2_000_000},
+		{"FD BRS extended 64 bytes", can.Frame{IsFD: true, BRS: true, IsExtended: true, Length: 64}, 49.0/500_000 + 676.0/2_000_000},
+	}
+	for _, tt := range tests {
+		if got := frameDuration(&tt.frame, timing); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: frameDuration() = %g, want %g", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCaptureStatsBusLoad(t *testing.T) {
+	// four 8 byte frames of 135 bits at 125 kbit/s (1.08 ms each) in 10 ms; the error frame takes no time
+	stats := inspectLog(t, `(1697040000.000000) can0 123#0000000000000000
+(1697040000.005000) can0 123#0000000000000000
+(1697040000.007000) can0 20000004#0000000000000000
+(1697040000.008000) can0 456#0000000000000000
+(1697040000.010000) can0 456#0000000000000000
+(1697040000.000000) can1 123#0000000000000000
+`, busTiming{bitrate: 125_000, dataBitrate: 2_000_000})
+
+	if len(stats.Interfaces) != 2 {
+		t.Fatalf("Interfaces = %d, want 2", len(stats.Interfaces))
+	}
+	can0 := stats.Interfaces[0]
+	if can0.Name != "can0" || can0.Frames != 5 || can0.Errors != 1 || can0.LinkType != "" {
+		t.Errorf("Interfaces[0] = %+v", *can0)
+	}
+	if want := 4 * 135.0 / 125_000 / 0.010; math.Abs(can0.BusLoad-want) > 1e-9 {
+		t.Errorf("can0 bus load = %g, want %g", can0.BusLoad, want)
+	}
+	// a single frame spans no time
+	if can1 := stats.Interfaces[1]; can1.BusLoad != 0 {
+		t.Errorf("can1 bus load = %g, want 0", can1.BusLoad)
+	}
+	if stats.Packets != 6 || stats.Frames != 6 || stats.Duration != 0.010 {
+		t.Errorf("packets = %d, frames = %d, duration = %g", stats.Packets, stats.Frames, stats.Duration)
+	}
+}
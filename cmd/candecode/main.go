@@ -3,7 +3,11 @@ package main
 import (
 	"log"
 
+	"github.com/BIwashi/candecode/app/check"
 	"github.com/BIwashi/candecode/app/convert"
+	"github.com/BIwashi/candecode/app/dbc"
+	"github.com/BIwashi/candecode/app/encode"
+	"github.com/BIwashi/candecode/app/inspect"
 	"github.com/BIwashi/candecode/pkg/cli"
 )
 
@@ -14,7 +18,11 @@ func main() {
 	)
 
 	c.AddCommands(
+		check.NewCommand(),
 		convert.NewCommand(),
+		dbc.NewCommand(),
+		encode.NewCommand(),
+		inspect.NewCommand(),
 	)
 
 	if err := c.Run(); err != nil {
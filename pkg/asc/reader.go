@@ -0,0 +1,398 @@
+package asc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/BIwashi/candecode/pkg/can"
+)
+
+const (
+	// fdFlagEDL marks a CAN FD frame in the Flags field of CANFD events.
+	fdFlagEDL = 0x1000
+	// fdFlagRemote marks a remote frame in the Flags field of CANFD events.
+	fdFlagRemote = 0x10
+)
+
+// dateLayouts are the layouts of the date header, with and without the am/pm marker.
+// Fractional seconds are accepted by time.Parse after the seconds field.
+var dateLayouts = []string{
+	"Mon Jan _2 03:04:05 pm 2006",
+	"Mon Jan _2 15:04:05 2006",
+}
+
+// Reader reads CAN frames from a Vector ASC trace (CANoe / CANalyzer logging):
+//
+//	date Wed Oct 11 04:00:00.000 pm 2023
+//	base hex  timestamps absolute
+//	   0.015991 1  123             Rx   d 8 01 02 03 04 05 06 07 08
+//	   0.020000 2  18FEF100x       Tx   d 3 AA BB CC
+//	   0.030000 CANFD   1 Rx        1A0  EngineData  1 0 a 16 00 01 02 03 04 05 06 07 08 09 0A 0B 0C 0D 0E 0F  0 0 3000 0 0 0 0 0
+//
+// Frame timestamps are the date header plus the event time; the date has no time zone and is
+// taken as UTC. Without a date header they count from the Unix epoch. IDs and data bytes follow
+// the base header (hex or dec). Channel n is read as the bus CAN<n> unless mapped with
+// WithChannel; interfaces are numbered in order of first appearance. Events that hold no CAN
+// frame (status events, transmit requests, error frames) are skipped.
+type Reader struct {
+	scanner      *bufio.Scanner
+	eventCount   uint64
+	skippedCount uint64
+	errorFrames  bool
+	base         int
+	relative     bool
+	start        time.Time
+	hasDate      bool
+	elapsed      time.Duration
+	channels     map[int]string
+	interfaces   []string
+	ifaceIndex   map[string]int
+}
+
+type ReaderOption interface {
+	apply(*Reader)
+}
+
+type errorFramesOption struct{}
+
+func (errorFramesOption) apply(r *Reader) {
+	r.errorFrames = true
+}
+
+// WithErrorFrames returns error frames (Frame.IsError) instead of skipping them.
+func WithErrorFrames() ReaderOption {
+	return errorFramesOption{}
+}
+
+type channelOption struct {
+	channel int
+	bus     string
+}
+
+func (o channelOption) apply(r *Reader) {
+	r.channels[o.channel] = o.bus
+}
+
+// WithChannel names the bus of the ASC channel number channel (1 based), e.g. 1 -> can0.
+func WithChannel(channel int, bus string) ReaderOption {
+	return channelOption{channel: channel, bus: bus}
+}
+
+// NewReader creates a Vector ASC trace reader.
+func NewReader(r io.Reader, opts ...ReaderOption) *Reader {
+	reader := &Reader{
+		scanner:    bufio.NewScanner(r),
+		base:       16,
+		start:      time.Unix(0, 0),
+		channels:   make(map[int]string),
+		ifaceIndex: make(map[string]int),
+	}
+	for _, o := range opts {
+		o.apply(reader)
+	}
+	return reader
+}
+
+// IsASC reports whether head, the beginning of a file, looks like a Vector ASC trace: its first
+// non-empty line is the date or base header.
+func IsASC(head []byte) bool {
+	for _, line := range strings.Split(string(head), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		return strings.HasPrefix(line, "date ") || strings.HasPrefix(line, "base ")
+	}
+	return false
+}
+
+// Interfaces returns the names of the buses read so far, by interface index.
+func (r *Reader) Interfaces() []string {
+	return r.interfaces
+}
+
+// ReadNext reads the next CAN frame from the trace.
+func (r *Reader) ReadNext() (*can.TimedFrame, error) {
+	for r.scanner.Scan() {
+		fields := strings.Fields(r.scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "//") {
+			continue
+		}
+		offset, err := parseOffset(fields[0])
+		if err != nil {
+			r.parseHeader(fields)
+			continue
+		}
+		r.eventCount++
+		if r.relative {
+			r.elapsed += offset
+		} else {
+			r.elapsed = offset
+		}
+
+		frame, err := r.parseEvent(fields[1:])
+		if err != nil || frame == nil || (frame.IsError && !r.errorFrames) {
+			r.skippedCount++
+			continue
+		}
+		frame.Timestamp = r.start.Add(r.elapsed)
+		index, ok := r.ifaceIndex[frame.Interface]
+		if !ok {
+			index = len(r.interfaces)
+			r.ifaceIndex[frame.Interface] = index
+			r.interfaces = append(r.interfaces, frame.Interface)
+		}
+		frame.InterfaceIndex = index
+		return frame, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read ASC trace")
+	}
+	return nil, io.EOF
+}
+
+// ReadFrame is ReadNext, named as the pcapng reader.
+func (r *Reader) ReadFrame() (*can.TimedFrame, error) {
+	return r.ReadNext()
+}
+
+// GetPacketCount returns the number of events (lines with a timestamp) read.
+func (r *Reader) GetPacketCount() uint64 {
+	return r.eventCount
+}
+
+// GetSkippedCount returns the number of events skipped because they hold no CAN frame
+// (status events, transmit requests, malformed lines, error frames).
+func (r *Reader) GetSkippedCount() uint64 {
+	return r.skippedCount
+}
+
+// parseHeader reads the date, base and timestamps headers. Other lines without a timestamp
+// (Begin Triggerblock, comments, ...) are ignored.
+func (r *Reader) parseHeader(fields []string) {
+	switch strings.ToLower(fields[0]) {
+	case "date":
+		if start, ok := parseDate(fields[1:]); ok {
+			r.start, r.hasDate = start, true
+		}
+	case "begin":
+		// Begin Triggerblock <date>, the measurement start when there is no date header
+		if len(fields) > 2 && !r.hasDate {
+			if start, ok := parseDate(fields[2:]); ok {
+				r.start, r.hasDate = start, true
+			}
+		}
+	case "base":
+		for i := 0; i+1 < len(fields); i += 2 {
+			switch strings.ToLower(fields[i]) {
+			case "base":
+				if strings.EqualFold(fields[i+1], "dec") {
+					r.base = 10
+				} else {
+					r.base = 16
+				}
+			case "timestamps":
+				r.relative = strings.EqualFold(fields[i+1], "relative")
+			}
+		}
+	}
+}
+
+func parseDate(fields []string) (time.Time, bool) {
+	text := strings.Join(fields, " ")
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseOffset parses the event time "seconds.fraction" without losing digits to float rounding.
+func parseOffset(s string) (time.Duration, error) {
+	secText, fracText, _ := strings.Cut(s, ".")
+	sec, err := strconv.ParseUint(secText, 10, 32)
+	if err != nil {
+		return 0, errors.Newf("invalid timestamp: %s", s)
+	}
+	var nsec uint64
+	if fracText != "" {
+		if len(fracText) > 9 {
+			fracText = fracText[:9]
+		}
+		nsec, err = strconv.ParseUint(fracText+strings.Repeat("0", 9-len(fracText)), 10, 64)
+		if err != nil {
+			return 0, errors.Newf("invalid timestamp: %s", s)
+		}
+	}
+	return time.Duration(sec)*time.Second + time.Duration(nsec), nil
+}
+
+// parseEvent parses the fields of an event after its timestamp. It returns nil for events that
+// hold no CAN frame.
+func (r *Reader) parseEvent(fields []string) (*can.TimedFrame, error) {
+	if len(fields) > 0 && fields[0] == "CANFD" {
+		return r.parseFDEvent(fields[1:])
+	}
+	// <channel> <id>[x] <Rx|Tx> <d|r> [<dlc> <data>...]
+	// <channel> ErrorFrame
+	if len(fields) < 2 {
+		return nil, nil
+	}
+	channel, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, nil
+	}
+	tf := &can.TimedFrame{Interface: r.bus(channel)}
+	if fields[1] == "ErrorFrame" {
+		tf.IsError = true
+		return tf, nil
+	}
+	if len(fields) < 4 {
+		return nil, nil
+	}
+	if tf.Direction, err = parseDirection(fields[2]); err != nil {
+		return nil, nil
+	}
+	if err := r.parseID(fields[1], &tf.Frame); err != nil {
+		return nil, err
+	}
+
+	var length uint64
+	if len(fields) > 4 {
+		if length, err = strconv.ParseUint(fields[4], 16, 8); err != nil || length > can.MaxDataLength {
+			return nil, errors.Newf("invalid DLC: %s", fields[4])
+		}
+	}
+	switch fields[3] {
+	case "r":
+		tf.IsRemote = true
+		tf.Length = uint8(length)
+		return tf, nil
+	case "d":
+		if len(fields) < 5 {
+			return nil, errors.New("missing DLC")
+		}
+		if err := r.parseData(fields[5:], int(length), &tf.Frame); err != nil {
+			return nil, err
+		}
+		return tf, nil
+	default:
+		return nil, errors.Newf("unknown frame type: %s", fields[3])
+	}
+}
+
+// parseFDEvent parses the fields of a CANFD event after the CANFD keyword:
+//
+//	<channel> <Rx|Tx> <id>[x] [<name>] <brs> <esi> <dlc> <data length> <data>... <duration> <length> <flags> ...
+//	<channel> <Rx|Tx> ErrorFrame ...
+func (r *Reader) parseFDEvent(fields []string) (*can.TimedFrame, error) {
+	if len(fields) < 3 {
+		return nil, nil
+	}
+	channel, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, nil
+	}
+	tf := &can.TimedFrame{Interface: r.bus(channel)}
+	if tf.Direction, err = parseDirection(fields[1]); err != nil {
+		return nil, nil
+	}
+	if fields[2] == "ErrorFrame" {
+		tf.IsError = true
+		return tf, nil
+	}
+	if err := r.parseID(fields[2], &tf.Frame); err != nil {
+		return nil, err
+	}
+	fields = fields[3:]
+	if len(fields) > 0 && fields[0] != "0" && fields[0] != "1" {
+		fields = fields[1:] // symbolic message name
+	}
+	if len(fields) < 4 {
+		return nil, errors.New("truncated CANFD event")
+	}
+	tf.BRS = fields[0] == "1"
+	tf.ESI = fields[1] == "1"
+	length, err := strconv.ParseUint(fields[3], 10, 8)
+	if err != nil || length > can.MaxFDDataLength {
+		return nil, errors.Newf("invalid data length: %s", fields[3])
+	}
+	if err := r.parseData(fields[4:], int(length), &tf.Frame); err != nil {
+		return nil, err
+	}
+
+	tf.IsFD = true
+	// The flags follow the message duration and length; without them the frame is taken as FD
+	if rest := fields[4+length:]; len(rest) > 2 {
+		flags, err := strconv.ParseUint(rest[2], 16, 32)
+		if err != nil {
+			return nil, errors.Newf("invalid CANFD flags: %s", rest[2])
+		}
+		tf.IsFD = flags&fdFlagEDL != 0
+		if flags&fdFlagRemote != 0 {
+			tf.IsRemote = true
+		}
+	}
+	if !tf.IsFD {
+		tf.BRS, tf.ESI = false, false
+		if tf.Length > can.MaxDataLength {
+			return nil, errors.Newf("classic frame with %d data bytes", tf.Length)
+		}
+	}
+	return tf, nil
+}
+
+// parseID parses a CAN ID in the base of the trace, extended IDs end with x.
+func (r *Reader) parseID(s string, f *can.Frame) error {
+	idText, extended := strings.CutSuffix(s, "x")
+	id, err := strconv.ParseUint(idText, r.base, 32)
+	if err != nil {
+		return fmt.Errorf("invalid CAN ID %s: %w", s, err)
+	}
+	f.ID = uint32(id)
+	f.IsExtended = extended
+	return nil
+}
+
+// parseData parses length data bytes in the base of the trace.
+func (r *Reader) parseData(fields []string, length int, f *can.Frame) error {
+	if len(fields) < length {
+		return errors.Newf("expected %d data bytes, got %d", length, len(fields))
+	}
+	for i := 0; i < length; i++ {
+		b, err := strconv.ParseUint(fields[i], r.base, 8)
+		if err != nil {
+			return fmt.Errorf("invalid data byte %s: %w", fields[i], err)
+		}
+		f.Data[i] = byte(b)
+	}
+	f.Length = uint8(length)
+	return nil
+}
+
+// bus returns the bus name of an ASC channel number.
+func (r *Reader) bus(channel int) string {
+	if bus, ok := r.channels[channel]; ok {
+		return bus
+	}
+	return fmt.Sprintf("CAN%d", channel)
+}
+
+// parseDirection parses Rx or Tx. Transmit requests (TxRq) are not frames on the bus.
+func parseDirection(s string) (can.Direction, error) {
+	switch s {
+	case "Rx":
+		return can.DirectionRx, nil
+	case "Tx":
+		return can.DirectionTx, nil
+	default:
+		return can.DirectionUnknown, errors.Newf("unknown direction: %s", s)
+	}
+}
@@ -0,0 +1,180 @@
+package asc
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BIwashi/candecode/pkg/can"
+)
+
+// readAll reads every frame of trace.
+func readAll(t *testing.T, trace string, opts ...ReaderOption) ([]*can.TimedFrame, *Reader) {
+	t.Helper()
+	r := NewReader(strings.NewReader(trace), opts...)
+	var frames []*can.TimedFrame
+	for {
+		f, err := r.ReadFrame()
+		if errors.Is(err, io.EOF) {
+			return frames, r
+		}
+		if err != nil {
+			t.Fatalf("ReadFrame() error = %v", err)
+		}
+		frames = append(frames, f)
+	}
+}
+
+func TestReaderHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		events string
+		want   []time.Time
+	}{
+		{
+			name:   "date with am/pm marker",
+			header: "date Wed Oct 11 04:00:00.000 pm 2023\nbase hex  timestamps absolute\n",
+			events: "   0.015991 1  123 Rx d 1 01\n   1.500000 1  123 Rx d 1 01\n",
+			want: []time.Time{
+				time.Date(2023, 10, 11, 16, 0, 0, 15991000, time.UTC),
+				time.Date(2023, 10, 11, 16, 0, 1, 500000000, time.UTC),
+			},
+		},
+		{
+			name:   "24 hour date with a single digit day",
+			header: "date Mon Oct  2 09:30:15 2023\nbase hex  timestamps absolute\n",
+			events: "   0.000001 1  123 Rx d 1 01\n",
+			want:   []time.Time{time.Date(2023, 10, 2, 9, 30, 15, 1000, time.UTC)},
+		},
+		{
+			name:   "begin triggerblock without a date header",
+			header: "base hex  timestamps absolute\nBegin Triggerblock Wed Oct 11 04:00:00.000 pm 2023\n",
+			events: "   0.100000 1  123 Rx d 1 01\n",
+			want:   []time.Time{time.Date(2023, 10, 11, 16, 0, 0, 100000000, time.UTC)},
+		},
+		{
+			name:   "no date",
+			header: "base hex  timestamps absolute\n",
+			events: "   2.000000001 1  123 Rx d 1 01\n",
+			want:   []time.Time{time.Unix(2, 1)},
+		},
+		{
+			name:   "relative timestamps",
+			header: "date Wed Oct 11 04:00:00.000 pm 2023\nbase hex  timestamps relative\n",
+			events: "   0.100000 1  123 Rx d 1 01\n   0.250000 1  123 Rx d 1 01\n",
+			want: []time.Time{
+				time.Date(2023, 10, 11, 16, 0, 0, 100000000, time.UTC),
+				time.Date(2023, 10, 11, 16, 0, 0, 350000000, time.UTC),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames, _ := readAll(t, tt.header+tt.events)
+			if len(frames) != len(tt.want) {
+				t.Fatalf("read %d frames, want %d", len(frames), len(tt.want))
+			}
+			for i, f := range frames {
+				if !f.Timestamp.Equal(tt.want[i]) {
+					t.Errorf("frame %d timestamp = %s, want %s", i, f.Timestamp, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReaderDecimalBase(t *testing.T) {
+	frames, _ := readAll(t, "base dec  timestamps absolute\n   0.000000 1  291 Rx d 2 10 255\n   0.000000 1  419361024x Tx d 0\n")
+	if len(frames) != 2 {
+		t.Fatalf("read %d frames, want 2", len(frames))
+	}
+	if f := frames[0]; f.ID != 0x123 || f.Length != 2 || f.Data[0] != 10 || f.Data[1] != 255 {
+		t.Errorf("frame 0 = %+v", f.Frame)
+	}
+	if f := frames[1]; f.ID != 0x18FEF100 || !f.IsExtended {
+		t.Errorf("frame 1 = %+v", f.Frame)
+	}
+}
+
+func TestReaderEvents(t *testing.T) {
+	trace := `date Wed Oct 11 04:00:00.000 pm 2023
+base hex  timestamps absolute
+internal events logged
+// version 13.0.0
+Begin Triggerblock Wed Oct 11 04:00:00.000 pm 2023
+   0.000000 Start of measurement
+   0.010000 1  123             Rx   d 8 01 02 03 04 05 06 07 08
+   0.020000 2  18FEF100x       Tx   d 3 AA BB CC
+   0.030000 1  321             Rx   r 4
+   0.035000 1  321             TxRq d 1 00
+   0.040000 1  ErrorFrame
+   0.050000 CANFD   1 Rx        1A0  EngineData  1 0 a 16 00 01 02 03 04 05 06 07 08 09 0A 0B 0C 0D 0E 0F  0 0 3000 0 0 0 0 0
+   0.060000 CANFD   2 Tx        1A1                0 1 2 2 AA BB  0 0 1000 0 0 0 0 0
+   0.070000 CANFD   1 Rx        1A2  Classic       1 1 2 2 AA BB  0 0 0 0 0 0 0 0
+   0.080000 CANFD   1 Rx        1A3                0 0 0 0  0 0 10 0 0 0 0 0
+   0.090000 CANFD   1 Rx        1A4  Short         1 0 f 64 00
+   0.100000 CANFD   1 Rx        ErrorFrame
+   0.110000 CANFD   1 Rx        1A5  NoFlags       1 0 1 1 7F
+End TriggerBlock
+`
+	frames, r := readAll(t, trace, WithChannel(1, "can0"))
+	want := []can.TimedFrame{
+		{Frame: can.Frame{ID: 0x123, Length: 8, Data: can.Data{1, 2, 3, 4, 5, 6, 7, 8}}, Interface: "can0", Direction: can.DirectionRx},
+		{Frame: can.Frame{ID: 0x18FEF100, IsExtended: true, Length: 3, Data: can.Data{0xAA, 0xBB, 0xCC}},
+			InterfaceIndex: 1, Interface: "CAN2", Direction: can.DirectionTx},
+		{Frame: can.Frame{ID: 0x321, IsRemote: true, Length: 4}, Interface: "can0", Direction: can.DirectionRx},
+		{Frame: can.Frame{ID: 0x1A0, IsFD: true, BRS: true, Length: 16,
+			Data: can.Data{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}}, Interface: "can0", Direction: can.DirectionRx},
+		{Frame: can.Frame{ID: 0x1A1, IsFD: true, ESI: true, Length: 2, Data: can.Data{0xAA, 0xBB}},
+			InterfaceIndex: 1, Interface: "CAN2", Direction: can.DirectionTx},
+		{Frame: can.Frame{ID: 0x1A2, Length: 2, Data: can.Data{0xAA, 0xBB}}, Interface: "can0", Direction: can.DirectionRx},
+		{Frame: can.Frame{ID: 0x1A3, IsRemote: true}, Interface: "can0", Direction: can.DirectionRx},
+		{Frame: can.Frame{ID: 0x1A5, IsFD: true, BRS: true, Length: 1, Data: can.Data{0x7F}}, Interface: "can0", Direction: can.DirectionRx},
+	}
+	if len(frames) != len(want) {
+		t.Fatalf("read %d frames, want %d", len(frames), len(want))
+	}
+	for i, f := range frames {
+		w := want[i]
+		if f.Frame != w.Frame || f.Interface != w.Interface || f.InterfaceIndex != w.InterfaceIndex || f.Direction != w.Direction {
+			t.Errorf("frame %d = %+v %s(%d) %d, want %+v %s(%d) %d", i,
+				f.Frame, f.Interface, f.InterfaceIndex, f.Direction, w.Frame, w.Interface, w.InterfaceIndex, w.Direction)
+		}
+	}
+	// start of measurement, TxRq, both error frames and the truncated FD frame
+	if r.GetPacketCount() != 13 || r.GetSkippedCount() != 5 {
+		t.Errorf("events = %d, skipped = %d, want 13, 5", r.GetPacketCount(), r.GetSkippedCount())
+	}
+	if got := strings.Join(r.Interfaces(), ","); got != "can0,CAN2" {
+		t.Errorf("Interfaces() = %s, want can0,CAN2", got)
+	}
+}
+
+func TestReaderErrorFrames(t *testing.T) {
+	frames, _ := readAll(t, "base hex  timestamps absolute\n   0.040000 1  ErrorFrame\n   0.050000 CANFD   2 Rx        ErrorFrame\n",
+		WithErrorFrames())
+	if len(frames) != 2 || !frames[0].IsError || !frames[1].IsError || frames[1].Interface != "CAN2" {
+		t.Errorf("frames = %+v, want two error frames", frames)
+	}
+}
+
+func TestIsASC(t *testing.T) {
+	tests := []struct {
+		head string
+		want bool
+	}{
+		{"date Wed Oct 11 04:00:00.000 pm 2023\nbase hex  timestamps absolute\n", true},
+		{"\r\n base hex  timestamps absolute\r\n", true},
+		{"(1697040000.123456) can0 123#DEADBEEF\n", false},
+		{"   0.015991 1  123 Rx d 1 01\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsASC([]byte(tt.head)); got != tt.want {
+			t.Errorf("IsASC(%q) = %t, want %t", tt.head, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package can
+
+// Data is a CAN payload large enough for CAN FD frames.
+//
+// Bit numbering follows the DBC convention: bit i lives in byte i/8 at position i%8,
+// so bit 0 is the least significant bit of the first byte.
+type Data [MaxFDDataLength]byte
+
+// Bit returns the value of the i:th bit. Bits outside the payload read as false.
+func (d *Data) Bit(i uint16) bool {
+	if int(i) >= len(d)*8 {
+		return false
+	}
+	return d[i/8]&(1<<(i%8)) != 0
+}
+
+// UnsignedBitsLittleEndian returns the little-endian bit range [start, start+length) as an unsigned value.
+func (d *Data) UnsignedBitsLittleEndian(start, length uint16) uint64 {
+	var value uint64
+	for i := uint16(0); i < length; i++ {
+		if d.Bit(start + i) {
+			value |= 1 << i
+		}
+	}
+	return value
+}
+
+// UnsignedBitsBigEndian returns the big-endian (Motorola) bit range as an unsigned value.
+// start is the most significant bit in DBC sawtooth numbering.
+func (d *Data) UnsignedBitsBigEndian(start, length uint16) uint64 {
+	var (
+		value uint64
+		pos   = start
+	)
+	for i := uint16(0); i < length; i++ {
+		value <<= 1
+		if d.Bit(pos) {
+			value |= 1
+		}
+		pos = nextBigEndianBit(pos)
+	}
+	return value
+}
+
+// SetBit sets the i:th bit to value. Bits outside the payload are ignored.
+func (d *Data) SetBit(i uint16, value bool) {
+	if int(i) >= len(d)*8 {
+		return
+	}
+	if value {
+		d[i/8] |= 1 << (i % 8)
+	} else {
+		d[i/8] &^= 1 << (i % 8)
+	}
+}
+
+// SetUnsignedBitsLittleEndian writes the low length bits of value to the little-endian bit range
+// [start, start+length).
+func (d *Data) SetUnsignedBitsLittleEndian(start, length uint16, value uint64) {
+	for i := uint16(0); i < length; i++ {
+		d.SetBit(start+i, value&(1<<i) != 0)
+	}
+}
+
+// SetUnsignedBitsBigEndian writes the low length bits of value to the big-endian (Motorola) bit range
+// starting at its most significant bit start.
+func (d *Data) SetUnsignedBitsBigEndian(start, length uint16, value uint64) {
+	pos := start
+	for i := length; i > 0; i-- {
+		d.SetBit(pos, value&(1<<(i-1)) != 0)
+		pos = nextBigEndianBit(pos)
+	}
+}
+
+// SignedBitsLittleEndian returns the little-endian bit range as a two's complement signed value.
+func (d *Data) SignedBitsLittleEndian(start, length uint16) int64 {
+	return signExtend(d.UnsignedBitsLittleEndian(start, length), length)
+}
+
+// SignedBitsBigEndian returns the big-endian bit range as a two's complement signed value.
+func (d *Data) SignedBitsBigEndian(start, length uint16) int64 {
+	return signExtend(d.UnsignedBitsBigEndian(start, length), length)
+}
+
+// SignalBits returns the payload bit positions covered by a signal of length bits starting at start.
+// Big-endian (Motorola) signals are walked from the most significant bit in DBC sawtooth numbering.
+func SignalBits(start, length uint16, bigEndian bool) []uint16 {
+	var (
+		bits = make([]uint16, 0, length)
+		pos  = start
+	)
+	for i := uint16(0); i < length; i++ {
+		bits = append(bits, pos)
+		if bigEndian {
+			pos = nextBigEndianBit(pos)
+		} else {
+			pos++
+		}
+	}
+	return bits
+}
+
+// nextBigEndianBit returns the next less significant bit position in DBC sawtooth numbering.
+func nextBigEndianBit(pos uint16) uint16 {
+	if pos%8 == 0 {
+		return pos + 15
+	}
+	return pos - 1
+}
+
+func signExtend(value uint64, length uint16) int64 {
+	if length == 0 || length >= 64 {
+		return int64(value)
+	}
+	shift := 64 - length
+	return int64(value<<shift) >> shift
+}
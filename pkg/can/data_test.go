@@ -0,0 +1,191 @@
+package can
+
+import (
+	"slices"
+	"testing"
+)
+
+// newData returns a payload with the given bytes set, by index.
+func newData(bytes map[int]byte) *Data {
+	var d Data
+	for i, b := range bytes {
+		d[i] = b
+	}
+	return &d
+}
+
+func TestDataUnsignedBitsLittleEndian(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          map[int]byte
+		start, length uint16
+		want          uint64
+	}{
+		{"within one byte", map[int]byte{0: 0xAB}, 4, 4, 0xA},
+		{"across a byte boundary", map[int]byte{0: 0xAB, 1: 0xCD}, 4, 8, 0xDA},
+		{"two bytes", map[int]byte{0: 0xAB, 1: 0xCD}, 0, 16, 0xCDAB},
+		{"start bit above 255", map[int]byte{37: 0x50, 38: 0x3C}, 300, 12, 0x3C5},
+		{"64 bit at the end of an FD payload", map[int]byte{56: 0x01, 57: 0x02, 58: 0x03, 59: 0x04, 60: 0x05, 61: 0x06, 62: 0x07, 63: 0x08},
+			448, 64, 0x0807060504030201},
+		{"bits past the payload read as zero", map[int]byte{63: 0xC0}, 510, 4, 0x3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newData(tt.data).UnsignedBitsLittleEndian(tt.start, tt.length); got != tt.want {
+				t.Errorf("UnsignedBitsLittleEndian(%d, %d) = 0x%X, want 0x%X", tt.start, tt.length, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataUnsignedBitsBigEndian(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          map[int]byte
+		start, length uint16
+		want          uint64
+	}{
+		{"within one byte", map[int]byte{0: 0xAB}, 7, 4, 0xA},
+		{"two bytes", map[int]byte{0: 0x12, 1: 0x34}, 7, 16, 0x1234},
+		{"across a byte boundary", map[int]byte{0: 0xAB, 1: 0xCD}, 3, 12, 0xBCD},
+		{"start bit above 255", map[int]byte{32: 0xBE, 33: 0xEF}, 263, 16, 0xBEEF},
+		{"start bit above 255 across a byte boundary", map[int]byte{37: 0x15, 38: 0xAA}, 300, 13, 0x15AA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newData(tt.data).UnsignedBitsBigEndian(tt.start, tt.length); got != tt.want {
+				t.Errorf("UnsignedBitsBigEndian(%d, %d) = 0x%X, want 0x%X", tt.start, tt.length, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataSetUnsignedBits(t *testing.T) {
+	tests := []struct {
+		name          string
+		start, length uint16
+		bigEndian     bool
+		value         uint64
+		want          map[int]byte
+	}{
+		{"little endian within one byte", 4, 4, false, 0xA, map[int]byte{0: 0xA0}},
+		{"little endian across a byte boundary", 4, 8, false, 0xDA, map[int]byte{0: 0xA0, 1: 0x0D}},
+		{"little endian start bit above 255", 300, 12, false, 0x3C5, map[int]byte{37: 0x50, 38: 0x3C}},
+		{"little endian 64 bit", 448, 64, false, 0x0807060504030201,
+			map[int]byte{56: 0x01, 57: 0x02, 58: 0x03, 59: 0x04, 60: 0x05, 61: 0x06, 62: 0x07, 63: 0x08}},
+		{"little endian high value bits dropped", 0, 4, false, 0xFF, map[int]byte{0: 0x0F}},
+		{"little endian bits past the payload dropped", 510, 4, false, 0xF, map[int]byte{63: 0xC0}},
+		{"big endian within one byte", 7, 4, true, 0xA, map[int]byte{0: 0xA0}},
+		{"big endian across a byte boundary", 3, 12, true, 0xBCD, map[int]byte{0: 0x0B, 1: 0xCD}},
+		{"big endian start bit above 255", 263, 16, true, 0xBEEF, map[int]byte{32: 0xBE, 33: 0xEF}},
+		{"big endian above 255 across a byte boundary", 300, 13, true, 0x15AA, map[int]byte{37: 0x15, 38: 0xAA}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Data
+			if tt.bigEndian {
+				d.SetUnsignedBitsBigEndian(tt.start, tt.length, tt.value)
+			} else {
+				d.SetUnsignedBitsLittleEndian(tt.start, tt.length, tt.value)
+			}
+			if want := newData(tt.want); d != *want {
+				t.Errorf("set %d bits at %d = % X, want % X", tt.length, tt.start, d[:], want[:])
+			}
+
+			// bits outside the range are kept
+			d = Data{}
+			for i := range d {
+				d[i] = 0xFF
+			}
+			if tt.bigEndian {
+				d.SetUnsignedBitsBigEndian(tt.start, tt.length, 0)
+			} else {
+				d.SetUnsignedBitsLittleEndian(tt.start, tt.length, 0)
+			}
+			for _, bit := range SignalBits(tt.start, tt.length, tt.bigEndian) {
+				d.SetBit(bit, true)
+			}
+			for i, b := range d {
+				if b != 0xFF {
+					t.Errorf("byte %d = 0x%02X after clearing the range, want 0xFF", i, b)
+				}
+			}
+		})
+	}
+}
+
+func TestDataSetBit(t *testing.T) {
+	var d Data
+	d.SetBit(9, true)
+	d.SetBit(511, true)
+	d.SetBit(512, true) // past the payload, ignored
+	if d[1] != 0x02 || d[63] != 0x80 || !d.Bit(9) || d.Bit(512) {
+		t.Errorf("SetBit: byte 1 = 0x%02X, byte 63 = 0x%02X", d[1], d[63])
+	}
+	d.SetBit(9, false)
+	if d[1] != 0 {
+		t.Errorf("SetBit(9, false): byte 1 = 0x%02X, want 0", d[1])
+	}
+}
+
+func TestDataSignedBits(t *testing.T) {
+	if got := newData(map[int]byte{1: 0xFF, 2: 0x0F}).SignedBitsLittleEndian(8, 12); got != -1 {
+		t.Errorf("SignedBitsLittleEndian(8, 12) = %d, want -1", got)
+	}
+	if got := newData(map[int]byte{1: 0xFF, 2: 0x07}).SignedBitsLittleEndian(8, 12); got != 0x7FF {
+		t.Errorf("SignedBitsLittleEndian(8, 12) = %d, want %d", got, 0x7FF)
+	}
+	if got := newData(map[int]byte{0: 0x80}).SignedBitsBigEndian(7, 8); got != -128 {
+		t.Errorf("SignedBitsBigEndian(7, 8) = %d, want -128", got)
+	}
+	if got := newData(map[int]byte{40: 0xFF, 41: 0xFE}).SignedBitsBigEndian(327, 16); got != -2 {
+		t.Errorf("SignedBitsBigEndian(327, 16) = %d, want -2", got)
+	}
+}
+
+func TestSignalBits(t *testing.T) {
+	tests := []struct {
+		name          string
+		start, length uint16
+		bigEndian     bool
+		want          []uint16
+	}{
+		{"little endian", 6, 4, false, []uint16{6, 7, 8, 9}},
+		{"big endian sawtooth", 3, 6, true, []uint16{3, 2, 1, 0, 15, 14}},
+		{"big endian above 255", 256, 3, true, []uint16{256, 271, 270}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SignalBits(tt.start, tt.length, tt.bigEndian); !slices.Equal(got, tt.want) {
+				t.Errorf("SignalBits(%d, %d, %t) = %v, want %v", tt.start, tt.length, tt.bigEndian, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrameDLC(t *testing.T) {
+	tests := []struct {
+		length uint8
+		want   uint8
+	}{
+		{0, 0}, {1, 1}, {7, 7}, {8, 8},
+		{12, 9}, {16, 10}, {20, 11}, {24, 12}, {32, 13}, {48, 14}, {64, 15},
+		// lengths between the FD sizes map to the next larger size
+		{9, 9}, {13, 10}, {33, 14}, {49, 15},
+	}
+	for _, tt := range tests {
+		f := Frame{Length: tt.length}
+		if got := f.DLC(); got != tt.want {
+			t.Errorf("DLC() of a %d byte frame = %d, want %d", tt.length, got, tt.want)
+		}
+	}
+}
+
+func TestIsFDLength(t *testing.T) {
+	valid := map[int]bool{12: true, 16: true, 20: true, 24: true, 32: true, 48: true, 64: true}
+	for n := -1; n <= 65; n++ {
+		if got, want := IsFDLength(n), n >= 0 && (n <= 8 || valid[n]); got != want {
+			t.Errorf("IsFDLength(%d) = %t, want %t", n, got, want)
+		}
+	}
+}
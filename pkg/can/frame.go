@@ -6,13 +6,90 @@ import (
 	ecan "go.einride.tech/can"
 )
 
-// TimedFrame wraps einride can.Frame to add capture timestamp information.
+const (
+	// MaxDataLength is the maximum payload length of a classic CAN frame.
+	MaxDataLength = ecan.MaxDataLength
+	// MaxFDDataLength is the maximum payload length of a CAN FD frame.
+	MaxFDDataLength = 64
+)
+
+// Frame is a classic CAN or CAN FD frame.
+// Unlike einride can.Frame the payload holds up to 64 bytes so FD frames are not truncated.
+type Frame struct {
+	ID         uint32
+	Length     uint8
+	Data       Data
+	IsRemote   bool
+	IsExtended bool
+	// IsFD reports whether the frame was sent in CAN FD format.
+	IsFD bool
+	// BRS is the CAN FD bit rate switch flag.
+	BRS bool
+	// ESI is the CAN FD error state indicator flag.
+	ESI bool
+	// IsError marks a SocketCAN error frame; ID holds the error class bits.
+	IsError bool
+}
+
+// Payload returns the received bytes of the frame (Data[:Length]).
+func (f *Frame) Payload() []byte {
+	return f.Data[:f.Length]
+}
+
+// fdLengths maps CAN FD data length codes 9..15 to payload lengths.
+var fdLengths = [...]uint8{12, 16, 20, 24, 32, 48, 64}
+
+// DLC returns the data length code of the frame. Payload lengths up to 8 are their own code;
+// longer CAN FD payloads map to the smallest code that holds them.
+func (f *Frame) DLC() uint8 {
+	if f.Length <= MaxDataLength {
+		return f.Length
+	}
+	for i, l := range fdLengths {
+		if f.Length <= l {
+			return uint8(9 + i)
+		}
+	}
+	return 15
+}
+
+// IsFDLength reports whether n is a CAN FD payload length: 0..8, 12, 16, 20, 24, 32, 48 or 64.
+func IsFDLength(n int) bool {
+	if n <= MaxDataLength {
+		return n >= 0
+	}
+	for _, l := range fdLengths {
+		if n == int(l) {
+			return true
+		}
+	}
+	return false
+}
+
+// Direction is the direction of a frame as seen by the capturing node, when the capture records it.
+// Values match candecode.proto.v1.Direction.
+type Direction int
+
+const (
+	// DirectionUnknown means the capture does not record the direction (pcapng).
+	DirectionUnknown Direction = iota
+	// DirectionRx means the frame was received.
+	DirectionRx
+	// DirectionTx means the frame was transmitted by the capturing node.
+	DirectionTx
+)
+
+// TimedFrame wraps Frame to add capture timestamp information.
 // Embedding keeps field access (ID, Length, Data, IsExtended, IsRemote, ...) identical.
 type TimedFrame struct {
-	ecan.Frame
+	Frame
 	// Timestamp is the original capture time from the pcap (host monotonic not required;
 	// wall-clock provided by gopacket CaptureInfo).
 	Timestamp time.Time
+	// InterfaceIndex is the capture interface (pcapng Interface Description Block) the frame was read from.
+	InterfaceIndex int
+	// Interface is the name of the capture interface, i.e. the bus (can0, can1, vcan0, ...).
+	Interface string
+	// Direction is the Rx/Tx direction of the frame (Vector ASC, candump -x).
+	Direction Direction
 }
-
-type Data ecan.Data
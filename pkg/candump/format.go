@@ -0,0 +1,58 @@
+package candump
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BIwashi/candecode/pkg/can"
+)
+
+const (
+	// fdFlagBRS and fdFlagESI are the CAN FD flags of the candump ## notation.
+	fdFlagBRS = 0x1
+	fdFlagESI = 0x2
+	// errorFlag marks the CAN ID of a SocketCAN error frame (CAN_ERR_FLAG).
+	errorFlag = 0x20000000
+)
+
+// Format formats f as a line of a candump log file (candump -l / -L):
+//
+//	(1700000000.000000) can0 123#DEADBEEF
+//	(1700000000.000000) can0 12345678#R
+//	(1700000000.000000) can0 123##1001122334455667788
+//
+// Standard IDs have 3 hex digits, extended and error frame IDs 8. CAN FD frames use ## followed
+// by the flags digit (BRS = 1, ESI = 2).
+func Format(f *can.TimedFrame) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "(%d.%06d) %s ", f.Timestamp.Unix(), f.Timestamp.Nanosecond()/1000, f.Interface)
+
+	switch {
+	case f.IsError:
+		fmt.Fprintf(&b, "%08X#", f.ID|errorFlag)
+	case f.IsExtended:
+		fmt.Fprintf(&b, "%08X#", f.ID)
+	default:
+		fmt.Fprintf(&b, "%03X#", f.ID)
+	}
+
+	switch {
+	case f.IsRemote:
+		b.WriteString("R")
+		if f.Length > 0 {
+			fmt.Fprintf(&b, "%d", f.Length)
+		}
+	case f.IsFD:
+		var flags int
+		if f.BRS {
+			flags |= fdFlagBRS
+		}
+		if f.ESI {
+			flags |= fdFlagESI
+		}
+		fmt.Fprintf(&b, "#%X%X", flags, f.Payload())
+	default:
+		fmt.Fprintf(&b, "%X", f.Payload())
+	}
+	return b.String()
+}
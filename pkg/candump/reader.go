@@ -0,0 +1,263 @@
+package candump
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/BIwashi/candecode/pkg/can"
+)
+
+const (
+	idMaskStandard = 0x7ff
+	idMaskExtended = 0x1fffffff
+)
+
+// Reader reads CAN frames from a candump log file (candump -l / -L), one frame per line:
+//
+//	(1697040000.123456) can0 123#DEADBEEF
+//
+// Interfaces are numbered in order of first appearance. Empty lines, comments (#) and lines
+// that hold no CAN frame (e.g. CAN XL frames) are skipped.
+type Reader struct {
+	scanner      *bufio.Scanner
+	lineCount    uint64
+	skippedCount uint64
+	errorFrames  bool
+	interfaces   []string
+	ifaceIndex   map[string]int
+}
+
+type ReaderOption interface {
+	apply(*Reader)
+}
+
+type errorFramesOption struct{}
+
+func (errorFramesOption) apply(r *Reader) {
+	r.errorFrames = true
+}
+
+// WithErrorFrames returns SocketCAN error frames (Frame.IsError) instead of skipping them.
+func WithErrorFrames() ReaderOption {
+	return errorFramesOption{}
+}
+
+// NewReader creates a candump log reader.
+func NewReader(r io.Reader, opts ...ReaderOption) *Reader {
+	reader := &Reader{
+		scanner:    bufio.NewScanner(r),
+		ifaceIndex: make(map[string]int),
+	}
+	for _, o := range opts {
+		o.apply(reader)
+	}
+	return reader
+}
+
+// IsLog reports whether head, the beginning of a file, looks like a candump log: its first
+// non-empty line starts with a (timestamp).
+func IsLog(head []byte) bool {
+	for _, line := range strings.Split(string(head), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		return strings.HasPrefix(line, "(")
+	}
+	return false
+}
+
+// Interfaces returns the names of the interfaces read so far, by interface index.
+func (r *Reader) Interfaces() []string {
+	return r.interfaces
+}
+
+// ReadNext reads the next CAN frame from the log.
+func (r *Reader) ReadNext() (*can.TimedFrame, error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r.lineCount++
+
+		frame, err := parseLine(line)
+		if err != nil || (frame.IsError && !r.errorFrames) {
+			r.skippedCount++
+			continue
+		}
+		index, ok := r.ifaceIndex[frame.Interface]
+		if !ok {
+			index = len(r.interfaces)
+			r.ifaceIndex[frame.Interface] = index
+			r.interfaces = append(r.interfaces, frame.Interface)
+		}
+		frame.InterfaceIndex = index
+		return frame, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read candump log")
+	}
+	return nil, io.EOF
+}
+
+// ReadFrame is ReadNext, named as the pcapng reader.
+func (r *Reader) ReadFrame() (*can.TimedFrame, error) {
+	return r.ReadNext()
+}
+
+// GetPacketCount returns the number of log lines read, comments and empty lines excluded.
+func (r *Reader) GetPacketCount() uint64 {
+	return r.lineCount
+}
+
+// GetSkippedCount returns the number of lines skipped because they hold no CAN frame
+// (malformed lines, CAN XL frames, error frames).
+func (r *Reader) GetSkippedCount() uint64 {
+	return r.skippedCount
+}
+
+// parseLine parses "(timestamp) interface frame [R|T]". The direction is written by candump -x.
+func parseLine(line string) (*can.TimedFrame, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return nil, errors.Newf("expected (timestamp) interface frame: %q", line)
+	}
+	ts, err := parseTimestamp(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	frame, err := ParseFrame(fields[2])
+	if err != nil {
+		return nil, err
+	}
+	tf := &can.TimedFrame{
+		Frame:     frame,
+		Timestamp: ts,
+		Interface: fields[1],
+	}
+	if len(fields) > 3 {
+		switch fields[3] {
+		case "R":
+			tf.Direction = can.DirectionRx
+		case "T":
+			tf.Direction = can.DirectionTx
+		}
+	}
+	return tf, nil
+}
+
+// parseTimestamp parses "(seconds.fraction)" without losing sub-microsecond digits to float rounding.
+func parseTimestamp(s string) (time.Time, error) {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return time.Time{}, errors.Newf("invalid timestamp: %s", s)
+	}
+	secText, fracText, _ := strings.Cut(s[1:len(s)-1], ".")
+	sec, err := strconv.ParseInt(secText, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Newf("invalid timestamp: %s", s)
+	}
+	var nsec int64
+	if fracText != "" {
+		if len(fracText) > 9 {
+			fracText = fracText[:9]
+		}
+		nsec, err = strconv.ParseInt(fracText+strings.Repeat("0", 9-len(fracText)), 10, 64)
+		if err != nil {
+			return time.Time{}, errors.Newf("invalid timestamp: %s", s)
+		}
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+// ParseFrame parses a frame in the notation of can-utils (cansend, candump -l):
+//
+//	<id>#{data}          classic CAN, data as hex bytes optionally separated by dots
+//	<id>#R{len}          remote frame with an optional length
+//	<id>##<flags>{data}  CAN FD, flags is one hex digit (BRS = 1, ESI = 2)
+//
+// CAN FD data must have one of the FD payload lengths (0..8, 12, 16, 20, 24, 32, 48, 64 bytes).
+// The ID has 3 hex digits for standard frames and 8 for extended and error frames.
+// The _<dlc> suffix of classic frames with a DLC above 8 is ignored.
+func ParseFrame(s string) (can.Frame, error) {
+	idText, rest, ok := strings.Cut(s, "#")
+	if !ok {
+		return can.Frame{}, errors.Newf("missing # in frame: %s", s)
+	}
+
+	var f can.Frame
+	id, err := strconv.ParseUint(idText, 16, 32)
+	if err != nil {
+		return can.Frame{}, errors.Newf("invalid CAN ID in frame: %s", s)
+	}
+	switch len(idText) {
+	case 3:
+		if id > idMaskStandard {
+			return can.Frame{}, errors.Newf("invalid standard CAN ID in frame: %s", s)
+		}
+		f.ID = uint32(id)
+	case 8:
+		if id&errorFlag != 0 {
+			f.IsError = true
+		} else {
+			f.IsExtended = true
+		}
+		f.ID = uint32(id) & idMaskExtended
+	default:
+		return can.Frame{}, errors.Newf("CAN ID must have 3 or 8 hex digits: %s", s)
+	}
+
+	switch {
+	case strings.HasPrefix(rest, "#"):
+		if len(rest) < 2 {
+			return can.Frame{}, errors.Newf("missing CAN FD flags in frame: %s", s)
+		}
+		flags, err := strconv.ParseUint(rest[1:2], 16, 8)
+		if err != nil {
+			return can.Frame{}, errors.Newf("invalid CAN FD flags in frame: %s", s)
+		}
+		f.IsFD = true
+		f.BRS = flags&fdFlagBRS != 0
+		f.ESI = flags&fdFlagESI != 0
+		rest = rest[2:]
+	case strings.HasPrefix(rest, "R") || strings.HasPrefix(rest, "r"):
+		f.IsRemote = true
+		if length, _, _ := strings.Cut(rest[1:], "_"); length != "" {
+			n, err := strconv.ParseUint(length, 10, 8)
+			if err != nil || n > can.MaxDataLength {
+				return can.Frame{}, errors.Newf("invalid remote frame length in frame: %s", s)
+			}
+			f.Length = uint8(n)
+		}
+		return f, nil
+	default:
+		rest, _, _ = strings.Cut(rest, "_")
+	}
+
+	maxLength := can.MaxDataLength
+	if f.IsFD {
+		maxLength = can.MaxFDDataLength
+	}
+	data := strings.ReplaceAll(rest, ".", "")
+	if len(data)%2 != 0 || len(data)/2 > maxLength {
+		return can.Frame{}, errors.Newf("invalid data length in frame: %s", s)
+	}
+	if f.IsFD && !can.IsFDLength(len(data)/2) {
+		return can.Frame{}, errors.Newf("invalid CAN FD data length %d in frame: %s", len(data)/2, s)
+	}
+	for i := 0; i < len(data)/2; i++ {
+		b, err := strconv.ParseUint(data[2*i:2*i+2], 16, 8)
+		if err != nil {
+			return can.Frame{}, fmt.Errorf("invalid data in frame %s: %w", s, err)
+		}
+		f.Data[i] = byte(b)
+	}
+	f.Length = uint8(len(data) / 2)
+	return f, nil
+}
@@ -0,0 +1,175 @@
+package candump
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BIwashi/candecode/pkg/can"
+)
+
+func TestParseFrame(t *testing.T) {
+	tests := []struct {
+		in   string
+		want can.Frame
+	}{
+		{"123#DEADBEEF", can.Frame{ID: 0x123, Length: 4, Data: can.Data{0xDE, 0xAD, 0xBE, 0xEF}}},
+		{"123#DE.AD.BE.EF", can.Frame{ID: 0x123, Length: 4, Data: can.Data{0xDE, 0xAD, 0xBE, 0xEF}}},
+		{"123#", can.Frame{ID: 0x123}},
+		{"18FEF100#0102", can.Frame{ID: 0x18FEF100, IsExtended: true, Length: 2, Data: can.Data{1, 2}}},
+		{"20000004#0000000000000000", can.Frame{ID: 0x4, IsError: true, Length: 8}},
+		{"123#R", can.Frame{ID: 0x123, IsRemote: true}},
+		{"123#R4", can.Frame{ID: 0x123, IsRemote: true, Length: 4}},
+		{"123#R8_F", can.Frame{ID: 0x123, IsRemote: true, Length: 8}},
+		{"123#1122334455667788_F", can.Frame{ID: 0x123, Length: 8, Data: can.Data{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88}}},
+		{"1A0##1", can.Frame{ID: 0x1A0, IsFD: true, BRS: true}},
+		{"1A0##3AABB", can.Frame{ID: 0x1A0, IsFD: true, BRS: true, ESI: true, Length: 2, Data: can.Data{0xAA, 0xBB}}},
+		{"1A0##0" + strings.Repeat("01", 12), can.Frame{ID: 0x1A0, IsFD: true, Length: 12,
+			Data: can.Data{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseFrame(tt.in)
+		if err != nil {
+			t.Errorf("ParseFrame(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseFrame(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseFrameFDLengths(t *testing.T) {
+	valid := map[int]bool{12: true, 16: true, 20: true, 24: true, 32: true, 48: true, 64: true}
+	for n := 0; n <= 65; n++ {
+		in := "1A0##0" + strings.Repeat("AB", n)
+		f, err := ParseFrame(in)
+		if wantOK := n <= 8 || valid[n]; (err == nil) != wantOK {
+			t.Errorf("ParseFrame() of %d FD bytes: error = %v, want ok = %t", n, err, wantOK)
+			continue
+		}
+		if err == nil && int(f.Length) != n {
+			t.Errorf("ParseFrame() of %d FD bytes: length = %d", n, f.Length)
+		}
+	}
+}
+
+func TestParseFrameErrors(t *testing.T) {
+	for _, in := range []string{
+		"123",                    // no #
+		"12#00",                  // 2 digit ID
+		"1234#00",                // 4 digit ID
+		"800#00",                 // standard ID above 0x7FF
+		"XYZ#00",                 // not hex
+		"123#0",                  // odd number of digits
+		"123#001122334455667788", // 9 classic bytes
+		"123#GG",                 // not hex data
+		"123#R9",                 // remote length above 8
+		"1A0##",                  // no FD flags
+		"1A0##X00",               // invalid FD flags
+		"1A0##0" + strings.Repeat("00", 13),
+		"1A0##0" + strings.Repeat("00", 65),
+	} {
+		if f, err := ParseFrame(in); err == nil {
+			t.Errorf("ParseFrame(%q) = %+v, want an error", in, f)
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	log := `# comment
+
+(1697040000.123456) can0 123#DEADBEEF
+(1697040000.123456789) can1 18FEF100##1AABB R
+not a frame
+(1697040001) can0 20000004#0000000000000000
+(1697040002.5) can0 1A0#01 T
+(1697040003.000000) can0 123##0` + strings.Repeat("00", 13) + `
+`
+	r := NewReader(strings.NewReader(log))
+	want := []can.TimedFrame{
+		{
+			Frame:     can.Frame{ID: 0x123, Length: 4, Data: can.Data{0xDE, 0xAD, 0xBE, 0xEF}},
+			Timestamp: time.Unix(1697040000, 123456000), Interface: "can0",
+		},
+		{
+			Frame:     can.Frame{ID: 0x18FEF100, IsExtended: true, IsFD: true, BRS: true, Length: 2, Data: can.Data{0xAA, 0xBB}},
+			Timestamp: time.Unix(1697040000, 123456789), InterfaceIndex: 1, Interface: "can1", Direction: can.DirectionRx,
+		},
+		{
+			Frame:     can.Frame{ID: 0x1A0, Length: 1, Data: can.Data{0x01}},
+			Timestamp: time.Unix(1697040002, 500000000), Interface: "can0", Direction: can.DirectionTx,
+		},
+	}
+	for i, w := range want {
+		got, err := r.ReadFrame()
+		if err != nil {
+			t.Fatalf("frame %d: ReadFrame() error = %v", i, err)
+		}
+		if got.Frame != w.Frame || !got.Timestamp.Equal(w.Timestamp) || got.InterfaceIndex != w.InterfaceIndex ||
+			got.Interface != w.Interface || got.Direction != w.Direction {
+			t.Errorf("frame %d = %+v, want %+v", i, *got, w)
+		}
+	}
+	if _, err := r.ReadFrame(); !errors.Is(err, io.EOF) {
+		t.Errorf("ReadFrame() at the end error = %v, want EOF", err)
+	}
+	// the malformed line, the error frame and the 13 byte FD frame
+	if r.GetPacketCount() != 6 || r.GetSkippedCount() != 3 {
+		t.Errorf("packets = %d, skipped = %d, want 6, 3", r.GetPacketCount(), r.GetSkippedCount())
+	}
+	if got := strings.Join(r.Interfaces(), ","); got != "can0,can1" {
+		t.Errorf("Interfaces() = %s, want can0,can1", got)
+	}
+}
+
+func TestReaderErrorFrames(t *testing.T) {
+	r := NewReader(strings.NewReader("(1697040001.000000) can0 20000004#0000000000000000\n"), WithErrorFrames())
+	f, err := r.ReadFrame()
+	if err != nil {
+		t.Fatalf("ReadFrame() error = %v", err)
+	}
+	if !f.IsError || f.ID != 0x4 {
+		t.Errorf("ReadFrame() = %+v, want error frame 0x4", f.Frame)
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	for _, line := range []string{
+		"(1697040000.123456) can0 123#DEADBEEF",
+		"(1697040000.000001) vcan0 18FEF100#",
+		"(1697040000.000000) can0 12345678#R",
+		"(1697040000.000000) can0 123#R4",
+		"(1697040000.000000) can1 1A0##3" + strings.Repeat("5A", 64),
+		"(1697040000.000000) can0 20000004#0000000000000000",
+	} {
+		f, err := parseLine(line)
+		if err != nil {
+			t.Errorf("parseLine(%q) error = %v", line, err)
+			continue
+		}
+		if got := Format(f); got != line {
+			t.Errorf("Format(parseLine(%q)) = %q", line, got)
+		}
+	}
+}
+
+func TestIsLog(t *testing.T) {
+	tests := []struct {
+		head string
+		want bool
+	}{
+		{"(1697040000.123456) can0 123#DEADBEEF\n", true},
+		{"\n\n  (1697040000.123456) can0 123#DEADBEEF", true},
+		{"date Wed Oct 11 10:00:00.000 am 2023\n", false},
+		{"123#DEADBEEF\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsLog([]byte(tt.head)); got != tt.want {
+			t.Errorf("IsLog(%q) = %t, want %t", tt.head, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/BIwashi/candecode/pkg/asc"
+	"github.com/BIwashi/candecode/pkg/can"
+	"github.com/BIwashi/candecode/pkg/candump"
+	"github.com/BIwashi/candecode/pkg/pcapng"
+)
+
+// Input formats.
+const (
+	// FormatAuto detects the format from the file contents.
+	FormatAuto    = "auto"
+	FormatPcapng  = "pcapng"
+	FormatCandump = "candump"
+	FormatASC     = "asc"
+)
+
+// detectHeadSize is the number of bytes inspected to detect the input format.
+const detectHeadSize = 512
+
+var (
+	// pcapngMagic is the block type of the Section Header Block that starts every pcapng file.
+	pcapngMagic = []byte{0x0A, 0x0D, 0x0D, 0x0A}
+	// pcapMagics start classic pcap files (microsecond and nanosecond, both byte orders).
+	pcapMagics = [][]byte{
+		{0xD4, 0xC3, 0xB2, 0xA1}, {0xA1, 0xB2, 0xC3, 0xD4},
+		{0x4D, 0x3C, 0xB2, 0xA1}, {0xA1, 0xB2, 0x3C, 0x4D},
+	}
+)
+
+// Reader reads the CAN frames of a capture, whatever its format.
+type Reader interface {
+	ReadFrame() (*can.TimedFrame, error)
+	// GetPacketCount returns the number of packets, log lines or events read.
+	GetPacketCount() uint64
+	// GetSkippedCount returns the number of packets, log lines or events that held no CAN frame.
+	GetSkippedCount() uint64
+}
+
+type options struct {
+	format      string
+	errorFrames bool
+	channels    []asc.ReaderOption
+}
+
+type Option interface {
+	apply(*options)
+}
+
+type formatOption string
+
+func (o formatOption) apply(opts *options) {
+	opts.format = string(o)
+}
+
+// WithFormat reads the input as format instead of detecting it. FormatAuto detects it.
+func WithFormat(format string) Option {
+	return formatOption(format)
+}
+
+type errorFramesOption struct{}
+
+func (errorFramesOption) apply(opts *options) {
+	opts.errorFrames = true
+}
+
+// WithErrorFrames returns error frames (Frame.IsError) instead of skipping them.
+func WithErrorFrames() Option {
+	return errorFramesOption{}
+}
+
+type channelOption struct {
+	channel int
+	bus     string
+}
+
+func (o channelOption) apply(opts *options) {
+	opts.channels = append(opts.channels, asc.WithChannel(o.channel, o.bus))
+}
+
+// WithChannel names the bus of an ASC channel number (see asc.WithChannel). Channel names are
+// an error for other input formats.
+func WithChannel(channel int, bus string) Option {
+	return channelOption{channel: channel, bus: bus}
+}
+
+// ParseChannels parses ASC channel mappings given as channel=bus, e.g. 1=can0, into WithChannel options.
+func ParseChannels(mappings []string) ([]Option, error) {
+	opts := make([]Option, 0, len(mappings))
+	for _, m := range mappings {
+		channel, bus, ok := strings.Cut(m, "=")
+		n, err := strconv.Atoi(channel)
+		if !ok || err != nil || n < 1 || bus == "" {
+			return nil, errors.Newf("invalid ASC channel mapping %q, expected channel=bus (e.g. 1=can0)", m)
+		}
+		opts = append(opts, WithChannel(n, bus))
+	}
+	return opts, nil
+}
+
+// DetectFormat picks the input format from head, the beginning of the input file:
+// pcapng files start with the Section Header Block magic, candump logs with a (timestamp) and
+// ASC traces with the date or base header.
+func DetectFormat(head []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(head, pcapngMagic):
+		return FormatPcapng, nil
+	case candump.IsLog(head):
+		return FormatCandump, nil
+	case asc.IsASC(head):
+		return FormatASC, nil
+	}
+	for _, magic := range pcapMagics {
+		if bytes.HasPrefix(head, magic) {
+			return "", errors.New("classic pcap files are not supported, convert the capture to pcapng (e.g. editcap -F pcapng)")
+		}
+	}
+	return "", errors.Newf("unknown input format (available: %s, %s, %s)", FormatPcapng, FormatCandump, FormatASC)
+}
+
+// NewReader creates the reader of the input format of r, detected from the file contents
+// unless set with WithFormat. It also returns the format.
+func NewReader(r *bufio.Reader, opts ...Option) (Reader, string, error) {
+	o := options{format: FormatAuto}
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+
+	format := o.format
+	if format == FormatAuto {
+		head, err := r.Peek(detectHeadSize)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, "", fmt.Errorf("failed to read input file: %w", err)
+		}
+		if format, err = DetectFormat(head); err != nil {
+			return nil, "", err
+		}
+	}
+	if len(o.channels) > 0 && format != FormatASC {
+		return nil, "", errors.Newf("ASC channel mappings only apply to ASC input, the input format is %s", format)
+	}
+
+	switch format {
+	case FormatPcapng:
+		var pcapngOpts []pcapng.ReaderOption
+		if o.errorFrames {
+			pcapngOpts = append(pcapngOpts, pcapng.WithErrorFrames())
+		}
+		reader, err := pcapng.NewReader(r, pcapngOpts...)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create PCAPNG reader: %w", err)
+		}
+		return reader, format, nil
+	case FormatCandump:
+		var candumpOpts []candump.ReaderOption
+		if o.errorFrames {
+			candumpOpts = append(candumpOpts, candump.WithErrorFrames())
+		}
+		return candump.NewReader(r, candumpOpts...), format, nil
+	case FormatASC:
+		ascOpts := o.channels
+		if o.errorFrames {
+			ascOpts = append(ascOpts, asc.WithErrorFrames())
+		}
+		return asc.NewReader(r, ascOpts...), format, nil
+	default:
+		return nil, "", errors.Newf("invalid input format %q, expected %s, %s, %s or %s",
+			format, FormatAuto, FormatPcapng, FormatCandump, FormatASC)
+	}
+}
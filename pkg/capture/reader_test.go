@@ -0,0 +1,88 @@
+package capture
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		head    []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "pcapng", head: []byte{0x0A, 0x0D, 0x0D, 0x0A, 0x1C, 0x00, 0x00, 0x00}, want: FormatPcapng},
+		{name: "candump", head: []byte("(1697040000.123456) can0 123#DEADBEEF\n"), want: FormatCandump},
+		{name: "candump after empty lines", head: []byte("\n\n(1697040000.123456) can0 123#DEADBEEF\n"), want: FormatCandump},
+		{name: "asc", head: []byte("date Wed Oct 11 10:00:00.000 am 2023\nbase hex  timestamps absolute\n"), want: FormatASC},
+		{name: "asc starting with base", head: []byte("base hex  timestamps absolute\n"), want: FormatASC},
+		{name: "classic pcap", head: []byte{0xD4, 0xC3, 0xB2, 0xA1, 0x02, 0x00}, wantErr: true},
+		{name: "classic pcap, nanoseconds", head: []byte{0xA1, 0xB2, 0x3C, 0x4D, 0x00, 0x02}, wantErr: true},
+		{name: "unknown", head: []byte("hello\n"), wantErr: true},
+		{name: "empty", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectFormat(tt.head)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("DetectFormat() = %q, %v, want %q (error %t)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	const (
+		ascInput     = "base hex  timestamps absolute\n"
+		candumpInput = "(1697040000.123456) can0 123#DEADBEEF\n"
+	)
+	tests := []struct {
+		name     string
+		input    string
+		format   string
+		channels []string
+		want     string
+		wantErr  bool
+	}{
+		{name: "asc", input: ascInput, format: FormatAuto, channels: []string{"1=can0"}, want: FormatASC},
+		{name: "candump", input: candumpInput, format: FormatAuto, want: FormatCandump},
+		{name: "candump with channels", input: candumpInput, format: FormatAuto, channels: []string{"1=can0"}, wantErr: true},
+		{name: "forced candump with channels", input: candumpInput, format: FormatCandump, channels: []string{"1=can0"}, wantErr: true},
+		{name: "forced asc", input: candumpInput, format: FormatASC, want: FormatASC},
+		{name: "invalid format", input: candumpInput, format: "blf", wantErr: true},
+		{name: "invalid mapping", input: ascInput, format: FormatAuto, channels: []string{"can0=1"}, wantErr: true},
+		{name: "channel zero", input: ascInput, format: FormatAuto, channels: []string{"0=can0"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := ParseChannels(tt.channels)
+			var got string
+			if err == nil {
+				_, got, err = NewReader(bufio.NewReader(strings.NewReader(tt.input)), append(opts, WithFormat(tt.format))...)
+			}
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("NewReader() = %q, %v, want %q (error %t)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewReaderErrorFrames(t *testing.T) {
+	const input = "(1697040001.000000) can0 20000004#0000000000000000\n"
+	for _, errorFrames := range []bool{false, true} {
+		var opts []Option
+		if errorFrames {
+			opts = append(opts, WithErrorFrames())
+		}
+		r, _, err := NewReader(bufio.NewReader(strings.NewReader(input)), opts...)
+		if err != nil {
+			t.Fatalf("NewReader() error = %v", err)
+		}
+		f, err := r.ReadFrame()
+		if got := err == nil && f.IsError; got != errorFrames {
+			t.Errorf("WithErrorFrames %t: ReadFrame() = %v, %v", errorFrames, f, err)
+		}
+	}
+}
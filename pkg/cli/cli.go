@@ -89,7 +89,8 @@ func runWithContext(cmd *cobra.Command, signalCh <-chan os.Signal, runner Runner
 		Stdin:           cmd.InOrStdin(),
 	}
 
-	logger := slog.New(slog.NewJSONHandler(os.Stdout,
+	// Logs go to stderr so stdout stays free for command output (e.g. MCAP streamed with -o -).
+	logger := slog.New(slog.NewJSONHandler(os.Stderr,
 		&slog.HandlerOptions{
 			Level: slogLevelFromString(flags.LogLevel),
 		}),
@@ -0,0 +1,398 @@
+package dbc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"text/scanner"
+	"time"
+
+	"go.einride.tech/can/pkg/dbc"
+	"go.einride.tech/can/pkg/descriptor"
+)
+
+// AttributeScope is the kind of object an attribute applies to.
+type AttributeScope int
+
+const (
+	// AttributeScopeNetwork attributes apply to the whole DBC file (BA_DEF_ without object type).
+	AttributeScopeNetwork AttributeScope = iota
+	// AttributeScopeNode attributes apply to nodes (BU_).
+	AttributeScopeNode
+	// AttributeScopeMessage attributes apply to messages (BO_).
+	AttributeScopeMessage
+	// AttributeScopeSignal attributes apply to signals (SG_).
+	AttributeScopeSignal
+	// AttributeScopeEnvVar attributes apply to environment variables (EV_).
+	AttributeScopeEnvVar
+)
+
+func (s AttributeScope) String() string {
+	switch s {
+	case AttributeScopeNetwork:
+		return "network"
+	case AttributeScopeNode:
+		return "node"
+	case AttributeScopeMessage:
+		return "message"
+	case AttributeScopeSignal:
+		return "signal"
+	case AttributeScopeEnvVar:
+		return "env_var"
+	default:
+		return fmt.Sprintf("scope(%d)", int(s))
+	}
+}
+
+// AttributeType is the value type of an attribute definition.
+type AttributeType string
+
+const (
+	AttributeTypeInt    AttributeType = "INT"
+	AttributeTypeHex    AttributeType = "HEX"
+	AttributeTypeFloat  AttributeType = "FLOAT"
+	AttributeTypeString AttributeType = "STRING"
+	AttributeTypeEnum   AttributeType = "ENUM"
+)
+
+// AttributeDefinition is an attribute declared with BA_DEF_ and its BA_DEF_DEF_ default.
+type AttributeDefinition struct {
+	Name  string
+	Scope AttributeScope
+	Type  AttributeType
+	// Min and Max bound INT, HEX and FLOAT values; both are 0 when unbounded.
+	Min float64
+	Max float64
+	// EnumValues are the names of ENUM values, by index.
+	EnumValues []string
+	// Default is the BA_DEF_DEF_ value, if HasDefault.
+	Default    AttributeValue
+	HasDefault bool
+
+	pos        scanner.Position
+	defaultPos scanner.Position
+}
+
+// AttributeValue is the value of an attribute. ENUM values keep both the index and the name.
+type AttributeValue struct {
+	Type        AttributeType
+	IntValue    int64
+	FloatValue  float64
+	StringValue string
+}
+
+// String formats the value as written in channel metadata: decimal INT, 0x prefixed HEX, shortest
+// FLOAT, and the STRING or ENUM name.
+func (v AttributeValue) String() string {
+	switch v.Type {
+	case AttributeTypeInt:
+		return strconv.FormatInt(v.IntValue, 10)
+	case AttributeTypeHex:
+		return fmt.Sprintf("0x%X", v.IntValue)
+	case AttributeTypeFloat:
+		return strconv.FormatFloat(v.FloatValue, 'g', -1, 64)
+	default:
+		return v.StringValue
+	}
+}
+
+// Attribute is the value of an attribute for one object, either set with BA_ or the default.
+type Attribute struct {
+	Name      string
+	Value     AttributeValue
+	IsDefault bool
+}
+
+// attributeKey identifies the object of an attribute value. name is the node or environment variable name.
+type attributeKey struct {
+	scope   AttributeScope
+	name    string
+	message *descriptor.Message
+	signal  *descriptor.Signal
+}
+
+type attributeEntry struct {
+	value AttributeValue
+	pos   scanner.Position
+}
+
+func attributeScope(t dbc.ObjectType) AttributeScope {
+	switch t {
+	case dbc.ObjectTypeNetworkNode:
+		return AttributeScopeNode
+	case dbc.ObjectTypeMessage:
+		return AttributeScopeMessage
+	case dbc.ObjectTypeSignal:
+		return AttributeScopeSignal
+	case dbc.ObjectTypeEnvironmentVariable:
+		return AttributeScopeEnvVar
+	default:
+		return AttributeScopeNetwork
+	}
+}
+
+// addAttributes collects the attribute definitions, defaults and values, then applies the attributes
+// the descriptors model (GenMsgSendType, GenMsgCycleTime, GenMsgDelayTime, GenSigStartValue).
+func (c *Compiler) addAttributes() {
+	for _, def := range c.defs {
+		def, ok := def.(*dbc.AttributeDef)
+		if !ok {
+			continue
+		}
+		ad := &AttributeDefinition{
+			Name:       string(def.Name),
+			Scope:      attributeScope(def.ObjectType),
+			Type:       AttributeType(def.Type),
+			EnumValues: def.EnumValues,
+			pos:        def.Pos,
+		}
+		switch ad.Type {
+		case AttributeTypeFloat:
+			ad.Min, ad.Max = def.MinimumFloat, def.MaximumFloat
+		default:
+			ad.Min, ad.Max = float64(def.MinimumInt), float64(def.MaximumInt)
+		}
+		if _, ok := c.attributeDefs[ad.Name]; ok {
+			c.addDiagnostic(SeverityWarning, def.Pos, "duplicate definition of attribute %s", ad.Name)
+		}
+		c.attributeDefs[ad.Name] = ad
+	}
+
+	for _, def := range c.defs {
+		switch def := def.(type) {
+		case *dbc.AttributeDefaultValueDef:
+			ad, ok := c.attributeDefs[string(def.AttributeName)]
+			if !ok {
+				c.addDiagnostic(SeverityWarning, def.Pos, "default value for undefined attribute %s", def.AttributeName)
+				continue
+			}
+			value, err := ad.value(def.DefaultIntValue, def.DefaultFloatValue, def.DefaultStringValue)
+			if err != nil {
+				c.addDiagnostic(SeverityWarning, def.Pos, "invalid default value of attribute %s: %v", def.AttributeName, err)
+				continue
+			}
+			ad.Default, ad.HasDefault, ad.defaultPos = value, true, def.Pos
+		case *dbc.AttributeValueForObjectDef:
+			c.addAttributeValue(def)
+		}
+	}
+
+	c.applyAttributes()
+}
+
+func (c *Compiler) addAttributeValue(def *dbc.AttributeValueForObjectDef) {
+	ad, ok := c.attributeDefs[string(def.AttributeName)]
+	if !ok {
+		c.addDiagnostic(SeverityWarning, def.Pos, "value of undefined attribute %s", def.AttributeName)
+		return
+	}
+	if scope := attributeScope(def.ObjectType); scope != ad.Scope {
+		c.addDiagnostic(SeverityWarning, def.Pos, "attribute %s of %s scope set for a %s", def.AttributeName, ad.Scope, scope)
+		return
+	}
+
+	key := attributeKey{scope: ad.Scope}
+	switch ad.Scope {
+	case AttributeScopeNode:
+		if _, ok := c.db.Node(string(def.NodeName)); !ok {
+			c.addDiagnostic(SeverityWarning, def.Pos, "attribute %s for undeclared node %s", def.AttributeName, def.NodeName)
+			return
+		}
+		key.name = string(def.NodeName)
+	case AttributeScopeMessage:
+		msg, ok := c.db.Message(def.MessageID.ToCAN())
+		if !ok {
+			c.addDiagnostic(SeverityWarning, def.Pos, "attribute %s for undeclared message 0x%X", def.AttributeName, def.MessageID.ToCAN())
+			return
+		}
+		key.message = msg
+	case AttributeScopeSignal:
+		sig, ok := c.db.Signal(def.MessageID.ToCAN(), string(def.SignalName))
+		if !ok {
+			c.addDiagnostic(SeverityWarning, def.Pos, "attribute %s for undeclared signal %s in message 0x%X", def.AttributeName, def.SignalName, def.MessageID.ToCAN())
+			return
+		}
+		key.signal = sig
+	case AttributeScopeEnvVar:
+		key.name = string(def.EnvironmentVariableName)
+	}
+
+	value, err := ad.value(def.IntValue, def.FloatValue, def.StringValue)
+	if err != nil {
+		c.addDiagnostic(SeverityWarning, def.Pos, "invalid value of attribute %s: %v", def.AttributeName, err)
+		return
+	}
+	values, ok := c.attributeValues[key]
+	if !ok {
+		values = make(map[string]attributeEntry)
+		c.attributeValues[key] = values
+	}
+	values[ad.Name] = attributeEntry{value: value, pos: def.Pos}
+}
+
+// value converts a parsed value to the type of the definition. The parser keeps integer literals in
+// intValue and string literals in stringValue whatever the attribute type; ENUM values are an index
+// or, as written by some tools, the value name.
+func (ad *AttributeDefinition) value(intValue int64, floatValue float64, stringValue string) (AttributeValue, error) {
+	v := AttributeValue{Type: ad.Type}
+	switch ad.Type {
+	case AttributeTypeInt, AttributeTypeHex:
+		v.IntValue = intValue
+		if intValue == 0 && floatValue != 0 {
+			v.IntValue = int64(floatValue)
+		}
+	case AttributeTypeFloat:
+		v.FloatValue = floatValue
+		if floatValue == 0 {
+			v.FloatValue = float64(intValue)
+		}
+	case AttributeTypeEnum:
+		index := intValue
+		if stringValue != "" {
+			index = -1
+			for i, name := range ad.EnumValues {
+				if name == stringValue {
+					index = int64(i)
+					break
+				}
+			}
+			if i, err := strconv.ParseInt(stringValue, 10, 64); index < 0 && err == nil {
+				index = i
+			}
+		}
+		if index < 0 || index >= int64(len(ad.EnumValues)) {
+			return AttributeValue{}, fmt.Errorf("no enum value %q", valueText(intValue, stringValue))
+		}
+		v.IntValue = index
+		v.StringValue = ad.EnumValues[index]
+	default:
+		v.StringValue = stringValue
+	}
+	return v, nil
+}
+
+func valueText(intValue int64, stringValue string) string {
+	if stringValue != "" {
+		return stringValue
+	}
+	return strconv.FormatInt(intValue, 10)
+}
+
+// applyAttributes sets the message and signal fields backed by attributes, defaults included.
+func (c *Compiler) applyAttributes() {
+	for _, m := range c.db.Messages {
+		if e, ok := c.attributeEntry(attributeKey{scope: AttributeScopeMessage, message: m}, "GenMsgSendType"); ok {
+			// Vector send types other than Cyclic and Event (NoMsgSendType, IfActive, ...) are valid
+			// but have no equivalent in the descriptors model
+			if err := m.SendType.UnmarshalString(e.value.StringValue); err != nil {
+				m.SendType = descriptor.SendTypeNone
+			}
+		}
+		if e, ok := c.attributeEntry(attributeKey{scope: AttributeScopeMessage, message: m}, "GenMsgCycleTime"); ok {
+			m.CycleTime = time.Duration(e.value.number()) * time.Millisecond
+		}
+		if e, ok := c.attributeEntry(attributeKey{scope: AttributeScopeMessage, message: m}, "GenMsgDelayTime"); ok {
+			m.DelayTime = time.Duration(e.value.number()) * time.Millisecond
+		}
+		for _, s := range m.Signals {
+			if e, ok := c.attributeEntry(attributeKey{scope: AttributeScopeSignal, signal: s}, "GenSigStartValue"); ok {
+				s.DefaultValue = int(e.value.number())
+			}
+		}
+	}
+}
+
+// number returns a numeric value as float64 (the index of ENUM values).
+func (v AttributeValue) number() float64 {
+	if v.Type == AttributeTypeFloat {
+		return v.FloatValue
+	}
+	return float64(v.IntValue)
+}
+
+// attributeEntry returns the value of attribute name for the object of key, or the default.
+func (c *Compiler) attributeEntry(key attributeKey, name string) (attributeEntry, bool) {
+	if e, ok := c.attributeValues[key][name]; ok {
+		return e, true
+	}
+	ad, ok := c.attributeDefs[name]
+	if !ok || ad.Scope != key.scope || !ad.HasDefault {
+		return attributeEntry{}, false
+	}
+	return attributeEntry{value: ad.Default, pos: ad.defaultPos}, true
+}
+
+// attributes returns every attribute of the object of key with a value or a default, ordered by name.
+func (c *Compiler) attributes(key attributeKey) []Attribute {
+	var attrs []Attribute
+	for _, ad := range c.AttributeDefinitions() {
+		if ad.Scope != key.scope {
+			continue
+		}
+		if e, ok := c.attributeValues[key][ad.Name]; ok {
+			attrs = append(attrs, Attribute{Name: ad.Name, Value: e.value})
+			continue
+		}
+		if ad.HasDefault {
+			attrs = append(attrs, Attribute{Name: ad.Name, Value: ad.Default, IsDefault: true})
+		}
+	}
+	return attrs
+}
+
+// AttributeDefinitions returns the attribute definitions of the DBC file, ordered by scope and name.
+func (c *Compiler) AttributeDefinitions() []*AttributeDefinition {
+	defs := make([]*AttributeDefinition, 0, len(c.attributeDefs))
+	for _, ad := range c.attributeDefs {
+		defs = append(defs, ad)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].Scope != defs[j].Scope {
+			return defs[i].Scope < defs[j].Scope
+		}
+		return defs[i].Name < defs[j].Name
+	})
+	return defs
+}
+
+// NetworkAttributes returns the network attributes (e.g. BusType, DBName) with defaults applied.
+func (c *Compiler) NetworkAttributes() []Attribute {
+	return c.attributes(attributeKey{scope: AttributeScopeNetwork})
+}
+
+// NodeAttributes returns the attributes of a node (e.g. NmStationAddress) with defaults applied.
+func (c *Compiler) NodeAttributes(node string) []Attribute {
+	return c.attributes(attributeKey{scope: AttributeScopeNode, name: node})
+}
+
+// MessageAttributes returns the attributes of m (e.g. VFrameFormat, GenMsgCycleTime) with defaults applied.
+func (c *Compiler) MessageAttributes(m *descriptor.Message) []Attribute {
+	return c.attributes(attributeKey{scope: AttributeScopeMessage, message: m})
+}
+
+// SignalAttributes returns the attributes of s (e.g. GenSigSendType) with defaults applied.
+func (c *Compiler) SignalAttributes(s *descriptor.Signal) []Attribute {
+	return c.attributes(attributeKey{scope: AttributeScopeSignal, signal: s})
+}
+
+// EnvVarAttributes returns the attributes of an environment variable with defaults applied.
+func (c *Compiler) EnvVarAttributes(envVar string) []Attribute {
+	return c.attributes(attributeKey{scope: AttributeScopeEnvVar, name: envVar})
+}
+
+// EnvVars returns the environment variables with attribute values, ordered by name.
+func (c *Compiler) EnvVars() []string {
+	var names []string
+	for key := range c.attributeValues {
+		if key.scope == AttributeScopeEnvVar {
+			names = append(names, key.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Nodes returns the nodes (BU_) of the DBC file, ordered by name.
+func (c *Compiler) Nodes() []*descriptor.Node {
+	return c.db.Nodes
+}
@@ -0,0 +1,56 @@
+package dbc
+
+import (
+	"testing"
+
+	"go.einride.tech/can/pkg/descriptor"
+)
+
+func TestApplyAttributesSendType(t *testing.T) {
+	var (
+		sendTypes = []string{"Cyclic", "NotUsed", "IfActive", "Event", "NoMsgSendType"}
+		enum      = func(name string) AttributeValue {
+			for i, v := range sendTypes {
+				if v == name {
+					return AttributeValue{Type: AttributeTypeEnum, IntValue: int64(i), StringValue: v}
+				}
+			}
+			t.Fatalf("no send type %s", name)
+			return AttributeValue{}
+		}
+		cyclic   = &descriptor.Message{Name: "CYCLIC", ID: 0x100, SendType: descriptor.SendTypeEvent}
+		event    = &descriptor.Message{Name: "EVENT", ID: 0x101}
+		ifActive = &descriptor.Message{Name: "IF_ACTIVE", ID: 0x102, SendType: descriptor.SendTypeCyclic}
+		fallback = &descriptor.Message{Name: "DEFAULT", ID: 0x103, SendType: descriptor.SendTypeCyclic}
+		c        = testCompiler([]*descriptor.Message{cyclic, event, ifActive, fallback}, nil)
+	)
+	c.attributeDefs["GenMsgSendType"] = &AttributeDefinition{
+		Name:       "GenMsgSendType",
+		Scope:      AttributeScopeMessage,
+		Type:       AttributeTypeEnum,
+		EnumValues: sendTypes,
+		Default:    enum("NoMsgSendType"),
+		HasDefault: true,
+	}
+	for m, v := range map[*descriptor.Message]string{cyclic: "Cyclic", event: "Event", ifActive: "IfActive"} {
+		c.attributeValues[attributeKey{scope: AttributeScopeMessage, message: m}] = map[string]attributeEntry{
+			"GenMsgSendType": {value: enum(v)},
+		}
+	}
+
+	c.applyAttributes()
+
+	for m, want := range map[*descriptor.Message]descriptor.SendType{
+		cyclic:   descriptor.SendTypeCyclic,
+		event:    descriptor.SendTypeEvent,
+		ifActive: descriptor.SendTypeNone,
+		fallback: descriptor.SendTypeNone,
+	} {
+		if m.SendType != want {
+			t.Errorf("send type of %s = %d, want %d", m.Name, m.SendType, want)
+		}
+	}
+	if diags := c.Diagnostics(); len(diags) != 0 {
+		t.Errorf("diagnostics = %v, want none", diags)
+	}
+}
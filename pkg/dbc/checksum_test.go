@@ -0,0 +1,134 @@
+package dbc
+
+import "testing"
+
+// The expected values were computed with the opendbc reference algorithms (crcmod for the Hyundai CRC).
+func TestChecksums(t *testing.T) {
+	tests := []struct {
+		name     string
+		checksum Checksum
+		frame    ChecksumFrame
+		want     uint64
+		wantOK   bool
+	}{
+		{
+			name: "honda", checksum: hondaChecksum{},
+			frame: ChecksumFrame{ID: 0x1FA, Data: []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x30}},
+			want:  0xF, wantOK: true,
+		},
+		{
+			name: "honda extended", checksum: hondaChecksum{},
+			frame: ChecksumFrame{ID: 0x18DAF1B0, IsExtended: true, Data: []byte{0x10, 0x20, 0x30, 0x00}},
+			want:  0xA, wantOK: true,
+		},
+		{
+			name: "toyota", checksum: toyotaChecksum{},
+			frame: ChecksumFrame{ID: 0x2E4, Data: []byte{0x80, 0x00, 0x00, 0x00, 0x00}},
+			want:  0x6B, wantOK: true,
+		},
+		{
+			name: "toyota two address bytes", checksum: toyotaChecksum{},
+			frame: ChecksumFrame{ID: 0x1D2, Data: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x12, 0x00}},
+			want:  0xED, wantOK: true,
+		},
+		{
+			name: "hyundai LKAS11", checksum: hyundaiChecksum{},
+			frame: ChecksumFrame{
+				ID: 0x340, Data: []byte{0x00, 0x40, 0x10, 0x00, 0x01, 0x00, 0x00, 0x00}, ChecksumBytes: []int{6},
+			},
+			want: 0x3B, wantOK: true,
+		},
+		{
+			name: "hyundai LKAS11 with a full payload", checksum: hyundaiChecksum{},
+			frame: ChecksumFrame{
+				ID: 0x340, Data: []byte{0x12, 0x34, 0x56, 0x78, 0x9A, 0xBC, 0x00, 0xDE}, ChecksumBytes: []int{6},
+			},
+			want: 0xB1, wantOK: true,
+		},
+		{
+			name: "hyundai other message", checksum: hyundaiChecksum{},
+			frame: ChecksumFrame{ID: 0x251, Data: make([]byte, 8), ChecksumBytes: []int{7}},
+		},
+		{
+			name: "vw mqb HCA_01", checksum: vwMQBChecksum{},
+			frame: ChecksumFrame{
+				ID: 0x126, Data: []byte{0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, ChecksumBytes: []int{0},
+				Counter: 3, HasCounter: true,
+			},
+			want: 0x2F, wantOK: true,
+		},
+		{
+			name: "vw mqb LWI_01", checksum: vwMQBChecksum{},
+			frame: ChecksumFrame{
+				ID: 0x086, Data: []byte{0x00, 0x05, 0x12, 0x34, 0x00, 0x00, 0x00, 0x00}, ChecksumBytes: []int{0},
+				Counter: 5, HasCounter: true,
+			},
+			want: 0xC3, wantOK: true,
+		},
+		{
+			name: "vw mqb unknown message", checksum: vwMQBChecksum{},
+			frame: ChecksumFrame{ID: 0x7FF, Data: make([]byte, 8), ChecksumBytes: []int{0}, HasCounter: true},
+		},
+		{
+			name: "vw pq", checksum: vwPQChecksum{},
+			frame: ChecksumFrame{
+				ID: 0x0D0, Data: []byte{0x00, 0x11, 0x22, 0x44, 0x88, 0x01, 0x02, 0x03}, ChecksumBytes: []int{0},
+			},
+			want: 0xFF, wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.checksum.Compute(tt.frame)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Compute() = %#x, %t, want %#x, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCRC8Update(t *testing.T) {
+	// check values of the CRC catalogue over "123456789"
+	tests := []struct {
+		name         string
+		poly         byte
+		init, xorOut byte
+		want         byte
+	}{
+		{name: "CRC-8/SAE-J1850", poly: 0x1D, init: 0xFF, xorOut: 0xFF, want: 0x4B},
+		{name: "CRC-8/AUTOSAR", poly: 0x2F, init: 0xFF, xorOut: 0xFF, want: 0xDF},
+	}
+	for _, tt := range tests {
+		crc := tt.init
+		for _, b := range []byte("123456789") {
+			crc = crc8Update(crc, b, tt.poly)
+		}
+		if crc ^= tt.xorOut; crc != tt.want {
+			t.Errorf("%s = %#x, want %#x", tt.name, crc, tt.want)
+		}
+	}
+}
+
+func TestDetectChecksum(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{file: "opendbc/honda_civic_touring_2016_can_generated.dbc", want: "honda"},
+		{file: "acura_ilx_2016_can_generated.dbc", want: "honda"},
+		{file: "toyota_nodsu_pt_generated.dbc", want: "toyota"},
+		{file: "vw_mqb_2010.dbc", want: "vw-mqb"},
+		{file: "vw_golf_mk4.dbc", want: "vw-pq"},
+		{file: "hyundai_kia_generic.dbc"},
+		{file: "vehicle.dbc"},
+	}
+	for _, tt := range tests {
+		var got string
+		if c, ok := DetectChecksum(tt.file); ok {
+			got = c.Name()
+		}
+		if got != tt.want {
+			t.Errorf("DetectChecksum(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
@@ -1,10 +1,9 @@
 package dbc
 
 import (
-	"fmt"
 	"os"
 	"sort"
-	"time"
+	"text/scanner"
 
 	"github.com/cockroachdb/errors"
 	"go.einride.tech/can/pkg/dbc"
@@ -13,9 +12,22 @@ import (
 
 // Decoder decodes CAN frames using DBC information
 type Compiler struct {
-	db     *descriptor.Database
-	defs   []dbc.Def
-	errors []error
+	db          *descriptor.Database
+	defs        []dbc.Def
+	source      []byte
+	diagnostics []Diagnostic
+	// startBits keeps the DBC start bit of every signal. descriptor.Signal.Start is a uint8
+	// and cannot address bits past 255 in CAN FD payloads.
+	startBits map[*descriptor.Signal]uint16
+	// positions keeps where each message is defined, for diagnostics.
+	positions map[*descriptor.Message]scanner.Position
+	// attributeDefs and attributeValues keep the BA_DEF_ definitions and BA_ values (see attribute.go).
+	attributeDefs   map[string]*AttributeDefinition
+	attributeValues map[attributeKey]map[string]attributeEntry
+	// multiplexing keeps the multiplexer switch and values of every multiplexed signal (see multiplex.go).
+	multiplexing map[*descriptor.Signal]multiplexing
+	// schema is the typed protobuf schema, generated on first use (see schema.go).
+	schema *Schema
 }
 
 func NewCompiler(filePath string) (*Compiler, error) {
@@ -28,16 +40,29 @@ func NewCompiler(filePath string) (*Compiler, error) {
 	if err := p.Parse(); err != nil {
 		return nil, errors.Wrap(err, "failed to parse dbc file")
 	}
+	return compile(filePath, dbcBytes, p.Defs()), nil
+}
+
+// compile builds the descriptors of the parsed definitions of a DBC file.
+func compile(filePath string, source []byte, defs []dbc.Def) *Compiler {
 	c := &Compiler{
-		db:   &descriptor.Database{SourceFile: filePath},
-		defs: p.Defs(),
+		db:              &descriptor.Database{SourceFile: filePath},
+		defs:            defs,
+		source:          source,
+		startBits:       make(map[*descriptor.Signal]uint16),
+		positions:       make(map[*descriptor.Message]scanner.Position),
+		multiplexing:    make(map[*descriptor.Signal]multiplexing),
+		attributeDefs:   make(map[string]*AttributeDefinition),
+		attributeValues: make(map[attributeKey]map[string]attributeEntry),
 	}
 
 	c.collectDescriptors()
 	c.addMetadata()
+	c.addAttributes()
+	c.addMultiplexing()
 	c.sortDescriptors()
 
-	return c, nil
+	return c
 }
 
 /*
@@ -78,8 +103,10 @@ func (c *Compiler) collectDescriptors() {
 				for _, receiver := range signalDef.Receivers {
 					signal.ReceiverNodes = append(signal.ReceiverNodes, string(receiver))
 				}
+				c.startBits[signal] = uint16(signalDef.StartBit)
 				message.Signals = append(message.Signals, signal)
 			}
+			c.positions[message] = def.Pos
 			c.db.Messages = append(c.db.Messages, message)
 		case *dbc.NodesDef:
 			for _, node := range def.NodeNames {
@@ -93,17 +120,50 @@ func (c *Compiler) Message(id uint32) (*descriptor.Message, bool) {
 	return c.db.Message(id)
 }
 
+// Messages returns the messages of the DBC file, ordered by ID.
+func (c *Compiler) Messages() []*descriptor.Message {
+	return c.db.Messages
+}
+
+// MessageByName returns the message called name.
+func (c *Compiler) MessageByName(name string) (*descriptor.Message, bool) {
+	for _, m := range c.db.Messages {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
 func (c *Compiler) SourceFile() string {
 	return c.db.SourceFile
 }
 
+// Source returns the contents of the DBC file as read by NewCompiler.
+func (c *Compiler) Source() []byte {
+	return c.source
+}
+
+// Version returns the VERSION string of the DBC file.
+func (c *Compiler) Version() string {
+	return c.db.Version
+}
+
+// StartBit returns the DBC start bit of s, including start bits past 255 used by CAN FD messages.
+func (c *Compiler) StartBit(s *descriptor.Signal) uint16 {
+	if start, ok := c.startBits[s]; ok {
+		return start
+	}
+	return uint16(s.Start)
+}
+
 func (c *Compiler) addMetadata() {
 	for _, def := range c.defs {
 		switch def := def.(type) {
 		case *dbc.SignalValueTypeDef:
 			signal, ok := c.db.Signal(def.MessageID.ToCAN(), string(def.SignalName))
 			if !ok {
-				c.errors = append(c.errors, fmt.Errorf("no declared signal: %v", def))
+				c.addDiagnostic(SeverityWarning, def.Pos, "SIG_VALTYPE_ for undeclared signal %s in message 0x%X", def.SignalName, def.MessageID.ToCAN())
 				continue
 			}
 			switch def.SignalValueType {
@@ -113,12 +173,16 @@ func (c *Compiler) addMetadata() {
 				if signal.Length == 32 {
 					signal.IsFloat = true
 				} else {
-					reason := fmt.Sprintf("incorrect float signal length: %d", signal.Length)
-					c.errors = append(c.errors, errors.New(reason))
+					c.addDiagnostic(SeverityError, def.Pos, "incorrect float signal length of %s: %d", signal.Name, signal.Length)
+				}
+			case dbc.SignalValueTypeFloat64:
+				if signal.Length == 64 {
+					signal.IsFloat = true
+				} else {
+					c.addDiagnostic(SeverityError, def.Pos, "incorrect double signal length of %s: %d", signal.Name, signal.Length)
 				}
 			default:
-				reason := fmt.Sprintf("unsupported signal value type: %v", def.SignalValueType)
-				c.errors = append(c.errors, errors.New(reason))
+				c.addDiagnostic(SeverityError, def.Pos, "unsupported signal value type of %s: %v", signal.Name, def.SignalValueType)
 			}
 		case *dbc.CommentDef:
 			switch def.ObjectType {
@@ -128,7 +192,7 @@ func (c *Compiler) addMetadata() {
 				}
 				message, ok := c.db.Message(def.MessageID.ToCAN())
 				if !ok {
-					c.errors = append(c.errors, errors.New(fmt.Sprintf("no declared message: %v", def)))
+					c.addDiagnostic(SeverityWarning, def.Pos, "comment for undeclared message 0x%X", def.MessageID.ToCAN())
 					continue
 				}
 				message.Description = def.Comment
@@ -138,14 +202,14 @@ func (c *Compiler) addMetadata() {
 				}
 				signal, ok := c.db.Signal(def.MessageID.ToCAN(), string(def.SignalName))
 				if !ok {
-					c.errors = append(c.errors, errors.New(fmt.Sprintf("no declared signal: %v", def)))
+					c.addDiagnostic(SeverityWarning, def.Pos, "comment for undeclared signal %s in message 0x%X", def.SignalName, def.MessageID.ToCAN())
 					continue
 				}
 				signal.Description = def.Comment
 			case dbc.ObjectTypeNetworkNode:
 				node, ok := c.db.Node(string(def.NodeName))
 				if !ok {
-					c.errors = append(c.errors, errors.New(fmt.Sprintf("no declared node: %v", def)))
+					c.addDiagnostic(SeverityWarning, def.Pos, "comment for undeclared node %s", def.NodeName)
 					continue
 				}
 				node.Description = def.Comment
@@ -159,7 +223,7 @@ func (c *Compiler) addMetadata() {
 			}
 			signal, ok := c.db.Signal(def.MessageID.ToCAN(), string(def.SignalName))
 			if !ok {
-				c.errors = append(c.errors, errors.New(fmt.Sprintf("no declared signal: %v", def)))
+				c.addDiagnostic(SeverityWarning, def.Pos, "value descriptions for undeclared signal %s in message 0x%X", def.SignalName, def.MessageID.ToCAN())
 				continue
 			}
 			for _, valueDescription := range def.ValueDescriptions {
@@ -168,35 +232,6 @@ func (c *Compiler) addMetadata() {
 					Value:       int64(valueDescription.Value),
 				})
 			}
-		case *dbc.AttributeValueForObjectDef:
-			switch def.ObjectType {
-			case dbc.ObjectTypeMessage:
-				msg, ok := c.db.Message(def.MessageID.ToCAN())
-				if !ok {
-					c.errors = append(c.errors, errors.New(fmt.Sprintf("no declared message: %v", def)))
-					continue
-				}
-				switch def.AttributeName {
-				case "GenMsgSendType":
-					if err := msg.SendType.UnmarshalString(def.StringValue); err != nil {
-						c.errors = append(c.errors, errors.New(fmt.Sprintf("failed to unmarshal message send type: %v", def)))
-						continue
-					}
-				case "GenMsgCycleTime":
-					msg.CycleTime = time.Duration(def.IntValue) * time.Millisecond
-				case "GenMsgDelayTime":
-					msg.DelayTime = time.Duration(def.IntValue) * time.Millisecond
-				}
-			case dbc.ObjectTypeSignal:
-				sig, ok := c.db.Signal(def.MessageID.ToCAN(), string(def.SignalName))
-				if !ok {
-					c.errors = append(c.errors, errors.New(fmt.Sprintf("no declared signal: %v", def)))
-					continue
-				}
-				if def.AttributeName == "GenSigStartValue" {
-					sig.DefaultValue = int(def.IntValue)
-				}
-			}
 		}
 	}
 }
@@ -216,7 +251,7 @@ func (c *Compiler) sortDescriptors() {
 			if m.Signals[j].MultiplexerValue < m.Signals[k].MultiplexerValue {
 				return true
 			}
-			return m.Signals[j].Start < m.Signals[k].Start
+			return c.StartBit(m.Signals[j]) < c.StartBit(m.Signals[k])
 		})
 		// Sort value descriptions by value
 		for _, s := range m.Signals {
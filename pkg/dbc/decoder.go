@@ -2,6 +2,7 @@ package dbc
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -10,69 +11,253 @@ import (
 	"github.com/BIwashi/candecode/pkg/can"
 )
 
+var (
+	// ErrNoDBC is returned by Decode for frames of a bus without a DBC.
+	ErrNoDBC = errors.New("no dbc for bus")
+	// ErrUnknownMessage is returned by Decode for CAN IDs the DBC does not define.
+	ErrUnknownMessage = errors.New("unknown message id")
+	// ErrShapeMismatch is returned by Decode when the DLC or the extended flag of a frame differs
+	// from the DBC message.
+	ErrShapeMismatch = errors.New("frame shape mismatch")
+	// ErrRemoteFrame is returned by Decode for remote frames, which carry no data.
+	ErrRemoteFrame = errors.New("remote frame")
+)
+
 type DecodedSignal struct {
 	Raw         any
 	Physical    *float64
 	Description string
 	Signal      *descriptor.Signal
 	Timestamp   time.Time
+	// Missing is set for signals whose bits lie past the end of a short frame (LengthPolicyLenient).
+	// Raw and Physical are not set.
+	Missing bool
+	// OutOfRange is set when the physical value lies outside the DBC [Min, Max] range.
+	OutOfRange bool
+	// UndefinedEnum is set when the signal has value descriptions but none matches the raw value.
+	UndefinedEnum bool
+}
+
+// LengthPolicy controls how Decode treats frames whose length differs from the DBC message length.
+type LengthPolicy int
+
+const (
+	// LengthPolicyStrict rejects the frame with ErrShapeMismatch.
+	LengthPolicyStrict LengthPolicy = iota
+	// LengthPolicyLenient decodes every signal whose bits lie within the received bytes and marks
+	// the other signals Missing.
+	LengthPolicyLenient
+	// LengthPolicyIgnore decodes every signal; bytes past the end of a short frame read as zero.
+	LengthPolicyIgnore
+)
+
+func (p LengthPolicy) String() string {
+	switch p {
+	case LengthPolicyStrict:
+		return "strict"
+	case LengthPolicyLenient:
+		return "lenient"
+	case LengthPolicyIgnore:
+		return "ignore-length"
+	default:
+		return fmt.Sprintf("length-policy(%d)", int(p))
+	}
+}
+
+// ParseLengthPolicy parses a policy name as accepted on the command line: strict, lenient or ignore-length.
+func ParseLengthPolicy(s string) (LengthPolicy, error) {
+	for _, p := range []LengthPolicy{LengthPolicyStrict, LengthPolicyLenient, LengthPolicyIgnore} {
+		if p.String() == s {
+			return p, nil
+		}
+	}
+	return 0, errors.Newf("unsupported length policy: %s (available: strict, lenient, ignore-length)", s)
 }
 
+// LengthOutcome records how the length of a decoded frame compared with the DBC message length
+// under the decoder LengthPolicy. Values match candecode.proto.v1.LengthOutcome.
+type LengthOutcome int
+
+const (
+	// LengthMatch means the frame has the DBC message length.
+	LengthMatch LengthOutcome = iota
+	// LengthTruncated means the frame is shorter and signals past its end are Missing (lenient).
+	LengthTruncated
+	// LengthPadded means the frame is longer; the extra bytes are ignored.
+	LengthPadded
+	// LengthIgnored means the frame is shorter and was decoded as if zero padded (ignore-length).
+	LengthIgnored
+)
+
+// RangePolicy controls how Decode treats signals whose physical value lies outside the DBC range.
+type RangePolicy int
+
+const (
+	// RangePolicyKeep keeps the value and sets DecodedSignal.OutOfRange.
+	RangePolicyKeep RangePolicy = iota
+	// RangePolicyDrop leaves the signal out of the decoded signals.
+	RangePolicyDrop
+	// RangePolicyClamp clamps the physical value to [Min, Max] and sets DecodedSignal.OutOfRange.
+	// The raw value is kept.
+	RangePolicyClamp
+)
+
+// Decoder decodes CAN frames using one DBC per bus.
+// Frames from buses without a dedicated DBC are decoded with the default compiler.
 type Decoder struct {
+	compiler     *Compiler
+	buses        map[string]*Compiler
+	lengthPolicy LengthPolicy
+	rangePolicy  RangePolicy
+}
+
+type DecoderOption interface {
+	apply(*Decoder)
+}
+
+type busCompilerOption struct {
+	bus      string
 	compiler *Compiler
 }
 
-func NewDecoder(compiler *Compiler) *Decoder {
-	return &Decoder{
-		compiler: compiler,
+func (o busCompilerOption) apply(d *Decoder) {
+	d.buses[o.bus] = o.compiler
+}
+
+// WithBusCompiler decodes frames captured on bus (the pcapng interface name) with compiler.
+func WithBusCompiler(bus string, compiler *Compiler) DecoderOption {
+	return busCompilerOption{bus: bus, compiler: compiler}
+}
+
+type lengthPolicyOption LengthPolicy
+
+func (o lengthPolicyOption) apply(d *Decoder) {
+	d.lengthPolicy = LengthPolicy(o)
+}
+
+// WithLengthPolicy sets how frames whose length differs from the DBC are decoded (strict by default).
+func WithLengthPolicy(policy LengthPolicy) DecoderOption {
+	return lengthPolicyOption(policy)
+}
+
+type rangePolicyOption RangePolicy
+
+func (o rangePolicyOption) apply(d *Decoder) {
+	d.rangePolicy = RangePolicy(o)
+}
+
+// WithRangePolicy sets how out of range signal values are decoded (kept and flagged by default).
+func WithRangePolicy(policy RangePolicy) DecoderOption {
+	return rangePolicyOption(policy)
+}
+
+// NewDecoder creates a decoder. compiler is the default DBC and may be nil when every bus is mapped
+// explicitly with WithBusCompiler.
+func NewDecoder(compiler *Compiler, opts ...DecoderOption) *Decoder {
+	d := &Decoder{
+		compiler:     compiler,
+		buses:        make(map[string]*Compiler),
+		lengthPolicy: LengthPolicyStrict,
+	}
+	for _, o := range opts {
+		o.apply(d)
 	}
+	return d
+}
+
+// Compiler returns the compiler used to decode frames captured on bus.
+func (d *Decoder) Compiler(bus string) (*Compiler, bool) {
+	if c, ok := d.buses[bus]; ok {
+		return c, true
+	}
+	return d.compiler, d.compiler != nil
+}
+
+// HasBusCompiler reports whether bus has its own DBC (WithBusCompiler) rather than the default one.
+func (d *Decoder) HasBusCompiler(bus string) bool {
+	_, ok := d.buses[bus]
+	return ok
 }
 
 func (d *Decoder) Decode(f *can.TimedFrame) (map[string]DecodedSignal, error) {
-	message, ok := d.compiler.db.Message(f.ID)
+	compiler, ok := d.Compiler(f.Interface)
 	if !ok {
-		return nil, errors.New(fmt.Sprintf("unknown message id: 0x%X", f.ID))
+		return nil, fmt.Errorf("%w: %s", ErrNoDBC, f.Interface)
 	}
-	if f.Length != message.Length || f.IsExtended != message.IsExtended || f.IsRemote {
-		return nil, errors.New("frame shape mismatch")
+	message, ok := compiler.db.Message(f.ID)
+	if !ok {
+		return nil, fmt.Errorf("%w: 0x%X", ErrUnknownMessage, f.ID)
+	}
+	if f.IsRemote {
+		return nil, fmt.Errorf("%w: 0x%X", ErrRemoteFrame, f.ID)
+	}
+	if f.IsExtended != message.IsExtended {
+		return nil, fmt.Errorf("%w: 0x%X extended=%t, dbc extended=%t", ErrShapeMismatch, f.ID, f.IsExtended, message.IsExtended)
+	}
+	if f.Length != message.Length && d.lengthPolicy == LengthPolicyStrict {
+		return nil, fmt.Errorf("%w: 0x%X length %d, dbc length %d", ErrShapeMismatch, f.ID, f.Length, message.Length)
 	}
 
 	var (
 		signalsMap = make(map[string]DecodedSignal)
-		mux        *descriptor.Signal
-		muxVal     uint64
+		truncated  = d.LengthOutcome(f, message) == LengthTruncated
+		tree       = newMultiplexTree(compiler, f, truncated)
 	)
 
-	// decode non-multiplexed signals
 	for _, s := range message.Signals {
-		if s.IsMultiplexed {
+		if !tree.isPresent(s) {
+			// multiplexed signal not selected by its switch (or the switch is missing); left out
 			continue
 		}
-		if s.IsMultiplexer {
-			mux = s
-			muxVal = s.UnmarshalUnsigned(f.Data)
-			signalsMap[s.Name] = decodeSignal(s, *f)
+		if truncated && !signalFits(compiler, s, f.Length) {
+			signalsMap[s.Name] = DecodedSignal{Signal: s, Timestamp: f.Timestamp, Missing: true}
 			continue
 		}
-		signalsMap[s.Name] = decodeSignal(s, *f)
-	}
-
-	// decode multiplexed signals
-	if mux != nil {
-		for _, s := range message.Signals {
-			if !s.IsMultiplexed {
-				continue
-			}
-			if muxVal == uint64(s.MultiplexerValue) {
-				signalsMap[s.Name] = decodeSignal(s, *f)
-			}
-		}
+		d.addSignal(signalsMap, decodeSignal(compiler, s, f))
 	}
 
 	return signalsMap, nil
 }
 
-func decodeSignal(s *descriptor.Signal, f can.TimedFrame) DecodedSignal {
+// addSignal adds a decoded signal to signalsMap under the decoder range policy.
+func (d *Decoder) addSignal(signalsMap map[string]DecodedSignal, ds DecodedSignal) {
+	if ds.OutOfRange {
+		switch d.rangePolicy {
+		case RangePolicyDrop:
+			return
+		case RangePolicyClamp:
+			pv := math.Max(math.Min(physicalValue(ds), ds.Signal.Max), ds.Signal.Min)
+			ds.Physical = &pv
+		}
+	}
+	signalsMap[ds.Signal.Name] = ds
+}
+
+// LengthOutcome reports how the length of f compares with message under the decoder length policy.
+func (d *Decoder) LengthOutcome(f *can.TimedFrame, message *descriptor.Message) LengthOutcome {
+	switch {
+	case f.Length == message.Length:
+		return LengthMatch
+	case f.Length > message.Length:
+		return LengthPadded
+	case d.lengthPolicy == LengthPolicyIgnore:
+		return LengthIgnored
+	default:
+		return LengthTruncated
+	}
+}
+
+// signalFits reports whether every bit of s lies within the first length bytes of the payload.
+func signalFits(c *Compiler, s *descriptor.Signal, length uint8) bool {
+	for _, bit := range can.SignalBits(c.StartBit(s), uint16(s.Length), s.IsBigEndian) {
+		if bit >= uint16(length)*8 {
+			return false
+		}
+	}
+	return true
+}
+
+func decodeSignal(c *Compiler, s *descriptor.Signal, f *can.TimedFrame) DecodedSignal {
 	var (
 		raw         any
 		physical    *float64
@@ -80,35 +265,116 @@ func decodeSignal(s *descriptor.Signal, f can.TimedFrame) DecodedSignal {
 	)
 	switch {
 	case s.Length == 1:
-		raw = s.UnmarshalBool(f.Data)
+		raw = f.Data.Bit(c.StartBit(s))
 	case s.IsFloat:
-		raw = s.UnmarshalFloat(f.Data)
+		raw = unmarshalFloat(c, s, &f.Data)
 	case s.IsSigned:
-		raw = s.UnmarshalSigned(f.Data)
+		raw = unmarshalSigned(c, s, &f.Data)
 	default:
-		raw = s.UnmarshalUnsigned(f.Data)
+		raw = unmarshalUnsigned(c, s, &f.Data)
 	}
 
-	if !s.IsFloat && (s.Scale != 0 || s.Offset != 0 || s.Min != 0 || s.Max != 0) {
+	if s.Scale != 0 || s.Offset != 0 || s.Min != 0 || s.Max != 0 {
+		// Not descriptor.Signal.ToPhysical, which clamps to [Min, Max] and would hide sentinel values
 		switch v := raw.(type) {
 		case int64:
-			pv := s.ToPhysical(float64(v))
+			pv := float64(v)*s.Scale + s.Offset
 			physical = &pv
 		case uint64:
-			pv := s.ToPhysical(float64(v))
+			pv := float64(v)*s.Scale + s.Offset
+			physical = &pv
+		case float64:
+			pv := v*s.Scale + s.Offset
 			physical = &pv
 		}
 	}
-	vd, ok := s.UnmarshalValueDescription(f.Data)
+	vd, ok := valueDescription(c, s, &f.Data)
 	if ok {
 		description = vd
 	}
 
-	return DecodedSignal{
-		Raw:         raw,
-		Physical:    physical,
-		Description: description,
-		Signal:      s,
-		Timestamp:   f.Timestamp,
+	ds := DecodedSignal{
+		Raw:           raw,
+		Physical:      physical,
+		Description:   description,
+		Signal:        s,
+		Timestamp:     f.Timestamp,
+		UndefinedEnum: !ok && !s.IsFloat && len(s.ValueDescriptions) > 0,
+	}
+	ds.OutOfRange = outOfRange(s, physicalValue(ds))
+	return ds
+}
+
+// physicalValue returns the physical value of ds, or its raw value when it has none.
+func physicalValue(ds DecodedSignal) float64 {
+	if ds.Physical != nil {
+		return *ds.Physical
+	}
+	switch v := ds.Raw.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return 0
+	}
+}
+
+// outOfRange reports whether v lies outside the [Min, Max] range of s. A range of [0, 0] means
+// unbounded, as in most DBC files. Values within half a scale step of a bound are in range,
+// since DBC bounds are rounded to the signal resolution.
+func outOfRange(s *descriptor.Signal, v float64) bool {
+	if (s.Min == 0 && s.Max == 0) || s.Min > s.Max || math.IsNaN(v) {
+		return false
+	}
+	tolerance := math.Abs(s.Scale) / 2
+	if s.IsFloat {
+		tolerance = 0
+	}
+	return v < s.Min-tolerance || v > s.Max+tolerance
+}
+
+// unmarshalUnsigned reads the raw bits of s from data using the full (FD capable) start bit.
+func unmarshalUnsigned(c *Compiler, s *descriptor.Signal, data *can.Data) uint64 {
+	start := c.StartBit(s)
+	if s.IsBigEndian {
+		return data.UnsignedBitsBigEndian(start, uint16(s.Length))
+	}
+	return data.UnsignedBitsLittleEndian(start, uint16(s.Length))
+}
+
+// unmarshalFloat reads the raw bits of s as an IEEE 754 single (32 bit) or double (64 bit) value.
+func unmarshalFloat(c *Compiler, s *descriptor.Signal, data *can.Data) float64 {
+	bits := unmarshalUnsigned(c, s, data)
+	if s.Length == 64 {
+		return math.Float64frombits(bits)
+	}
+	return float64(math.Float32frombits(uint32(bits)))
+}
+
+// unmarshalSigned reads the raw bits of s from data as a two's complement value.
+func unmarshalSigned(c *Compiler, s *descriptor.Signal, data *can.Data) int64 {
+	start := c.StartBit(s)
+	if s.IsBigEndian {
+		return data.SignedBitsBigEndian(start, uint16(s.Length))
+	}
+	return data.SignedBitsLittleEndian(start, uint16(s.Length))
+}
+
+// valueDescription looks up the value description matching the raw value of s.
+func valueDescription(c *Compiler, s *descriptor.Signal, data *can.Data) (string, bool) {
+	if len(s.ValueDescriptions) == 0 {
+		return "", false
+	}
+	if s.IsSigned {
+		return s.ValueDescription(unmarshalSigned(c, s, data))
 	}
+	return s.ValueDescription(int64(unmarshalUnsigned(c, s, data)))
 }
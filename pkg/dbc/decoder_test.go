@@ -0,0 +1,316 @@
+package dbc
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"go.einride.tech/can/pkg/descriptor"
+
+	"github.com/BIwashi/candecode/pkg/can"
+)
+
+// testCompiler builds a compiler from descriptors, as NewCompiler does from a DBC file. starts holds
+// the start bits past 255, which descriptor.Signal.Start cannot hold.
+func testCompiler(messages []*descriptor.Message, starts map[*descriptor.Signal]uint16) *Compiler {
+	c := &Compiler{
+		db:              &descriptor.Database{SourceFile: "test.dbc", Messages: messages},
+		startBits:       make(map[*descriptor.Signal]uint16),
+		multiplexing:    make(map[*descriptor.Signal]multiplexing),
+		attributeDefs:   make(map[string]*AttributeDefinition),
+		attributeValues: make(map[attributeKey]map[string]attributeEntry),
+	}
+	for _, m := range messages {
+		for _, s := range m.Signals {
+			c.startBits[s] = uint16(s.Start)
+		}
+	}
+	for s, start := range starts {
+		c.startBits[s] = start
+	}
+	return c
+}
+
+// fdTestMessage is a 64 byte CAN FD message with signals past bit 255.
+func fdTestMessage() (*descriptor.Message, map[*descriptor.Signal]uint16) {
+	var (
+		head   = &descriptor.Signal{Name: "HEAD", Start: 0, Length: 8}
+		little = &descriptor.Signal{Name: "LITTLE", Length: 12, Scale: 1}
+		big    = &descriptor.Signal{Name: "BIG", Length: 16, IsBigEndian: true, IsSigned: true, Scale: 0.5}
+		wide   = &descriptor.Signal{Name: "WIDE", Length: 64}
+	)
+	m := &descriptor.Message{
+		Name:    "FD_DATA",
+		ID:      0x100,
+		Length:  64,
+		Signals: []*descriptor.Signal{head, little, big, wide},
+	}
+	return m, map[*descriptor.Signal]uint16{little: 300, big: 263, wide: 448}
+}
+
+func TestDecodeStartBitsAbove255(t *testing.T) {
+	m, starts := fdTestMessage()
+	d := NewDecoder(testCompiler([]*descriptor.Message{m}, starts))
+
+	f := &can.TimedFrame{Frame: can.Frame{ID: 0x100, Length: 64, IsFD: true}}
+	f.Data[0] = 0x2A
+	f.Data[32], f.Data[33] = 0xFF, 0xFE
+	f.Data[37], f.Data[38] = 0x50, 0x3C
+	copy(f.Data[56:], []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08})
+
+	signals, err := d.Decode(f)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	tests := []struct {
+		signal   string
+		raw      any
+		physical *float64
+	}{
+		{"HEAD", uint64(0x2A), nil},
+		{"LITTLE", uint64(0x3C5), ptr(float64(0x3C5))},
+		{"BIG", int64(-2), ptr(-1.0)},
+		{"WIDE", uint64(0x0807060504030201), nil},
+	}
+	for _, tt := range tests {
+		ds, ok := signals[tt.signal]
+		if !ok {
+			t.Errorf("signal %s not decoded", tt.signal)
+			continue
+		}
+		if ds.Raw != tt.raw {
+			t.Errorf("signal %s raw = %#v, want %#v", tt.signal, ds.Raw, tt.raw)
+		}
+		switch {
+		case tt.physical == nil && ds.Physical != nil:
+			t.Errorf("signal %s physical = %g, want none", tt.signal, *ds.Physical)
+		case tt.physical != nil && (ds.Physical == nil || *ds.Physical != *tt.physical):
+			t.Errorf("signal %s physical = %v, want %g", tt.signal, ds.Physical, *tt.physical)
+		}
+	}
+}
+
+func TestDecodeLenientTruncatedFD(t *testing.T) {
+	m, starts := fdTestMessage()
+	d := NewDecoder(testCompiler([]*descriptor.Message{m}, starts), WithLengthPolicy(LengthPolicyLenient))
+
+	f := &can.TimedFrame{Frame: can.Frame{ID: 0x100, Length: 32, IsFD: true}}
+	f.Data[0] = 0x2A
+	signals, err := d.Decode(f)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if ds := signals["HEAD"]; ds.Missing || ds.Raw != uint64(0x2A) {
+		t.Errorf("HEAD = %+v, want raw 0x2A", ds)
+	}
+	for _, name := range []string{"LITTLE", "BIG", "WIDE"} {
+		if !signals[name].Missing {
+			t.Errorf("%s past the end of a 32 byte frame is not missing", name)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// valueTestDBC has a scaled signal whose maximum (49.9) is rounded below its last step (50.0),
+// an enum, a signed signal and IEEE 754 float signals (SIG_VALTYPE_).
+const valueTestDBC = `VERSION ""
+
+BU_: ECU
+
+BO_ 256 VALUES: 8 ECU
+ SG_ TEMP : 0|8@1+ (0.5,-40) [-40|49.9] "degC" Vector__XXX
+ SG_ GEAR : 8|3@1+ (1,0) [0|7] "" Vector__XXX
+ SG_ LEVEL : 16|8@1- (1,0) [-100|100] "" Vector__XXX
+
+BO_ 512 SINGLE: 4 ECU
+ SG_ F32 : 0|32@1- (0.5,1) [0|100] "" Vector__XXX
+
+BO_ 768 DOUBLE: 8 ECU
+ SG_ F64 : 0|64@1- (1,0) [0|0] "" Vector__XXX
+
+VAL_ 256 GEAR 0 "P" 1 "R" 2 "N" 3 "D" ;
+
+SIG_VALTYPE_ 512 F32 : 1;
+SIG_VALTYPE_ 768 F64 : 2;
+`
+
+func float32Bytes(v float32) []byte {
+	return binary.LittleEndian.AppendUint32(nil, math.Float32bits(v))
+}
+
+func float64Bytes(v float64) []byte {
+	return binary.LittleEndian.AppendUint64(nil, math.Float64bits(v))
+}
+
+func TestDecodeSignalValues(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        RangePolicy
+		id            uint32
+		data          []byte
+		signal        string
+		dropped       bool
+		raw           any
+		physical      float64
+		description   string
+		outOfRange    bool
+		undefinedEnum bool
+	}{
+		{
+			name:     "in range",
+			id:       0x100,
+			data:     []byte{100, 0, 0, 0, 0, 0, 0, 0},
+			signal:   "TEMP",
+			raw:      uint64(100),
+			physical: 10,
+		},
+		{
+			name:     "within half a scale step of the maximum",
+			id:       0x100,
+			data:     []byte{180, 0, 0, 0, 0, 0, 0, 0},
+			signal:   "TEMP",
+			raw:      uint64(180),
+			physical: 50,
+		},
+		{
+			name:       "out of range kept",
+			id:         0x100,
+			data:       []byte{181, 0, 0, 0, 0, 0, 0, 0},
+			signal:     "TEMP",
+			raw:        uint64(181),
+			physical:   50.5,
+			outOfRange: true,
+		},
+		{
+			name:    "out of range dropped",
+			policy:  RangePolicyDrop,
+			id:      0x100,
+			data:    []byte{181, 0, 0, 0, 0, 0, 0, 0},
+			signal:  "TEMP",
+			dropped: true,
+		},
+		{
+			name:       "out of range clamped",
+			policy:     RangePolicyClamp,
+			id:         0x100,
+			data:       []byte{181, 0, 0, 0, 0, 0, 0, 0},
+			signal:     "TEMP",
+			raw:        uint64(181),
+			physical:   49.9,
+			outOfRange: true,
+		},
+		{
+			name:       "signed below the minimum",
+			id:         0x100,
+			data:       []byte{0, 0, 0x80, 0, 0, 0, 0, 0},
+			signal:     "LEVEL",
+			raw:        int64(-128),
+			physical:   -128,
+			outOfRange: true,
+		},
+		{
+			name:       "signed clamped to the minimum",
+			policy:     RangePolicyClamp,
+			id:         0x100,
+			data:       []byte{0, 0, 0x80, 0, 0, 0, 0, 0},
+			signal:     "LEVEL",
+			raw:        int64(-128),
+			physical:   -100,
+			outOfRange: true,
+		},
+		{
+			name:        "enum",
+			id:          0x100,
+			data:        []byte{0, 3, 0, 0, 0, 0, 0, 0},
+			signal:      "GEAR",
+			raw:         uint64(3),
+			physical:    3,
+			description: "D",
+		},
+		{
+			name:          "undefined enum",
+			id:            0x100,
+			data:          []byte{0, 5, 0, 0, 0, 0, 0, 0},
+			signal:        "GEAR",
+			raw:           uint64(5),
+			physical:      5,
+			undefinedEnum: true,
+		},
+		{
+			name:        "in range signals are kept when dropping",
+			policy:      RangePolicyDrop,
+			id:          0x100,
+			data:        []byte{181, 2, 0, 0, 0, 0, 0, 0},
+			signal:      "GEAR",
+			raw:         uint64(2),
+			physical:    2,
+			description: "N",
+		},
+		{
+			name:     "scaled float32",
+			id:       0x200,
+			data:     float32Bytes(10.5),
+			signal:   "F32",
+			raw:      10.5,
+			physical: 6.25,
+		},
+		{
+			// floats have no resolution, so no half step tolerance: 100.125 is past the maximum
+			name:       "float32 out of range",
+			id:         0x200,
+			data:       float32Bytes(198.25),
+			signal:     "F32",
+			raw:        198.25,
+			physical:   100.125,
+			outOfRange: true,
+		},
+		{
+			name:     "float64",
+			id:       0x300,
+			data:     float64Bytes(math.Pi),
+			signal:   "F64",
+			raw:      math.Pi,
+			physical: math.Pi,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(compileSource(t, valueTestDBC), WithRangePolicy(tt.policy))
+			f := &can.TimedFrame{Frame: can.Frame{ID: tt.id, Length: uint8(len(tt.data))}}
+			copy(f.Data[:], tt.data)
+
+			signals, err := d.Decode(f)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			ds, ok := signals[tt.signal]
+			if tt.dropped {
+				if ok {
+					t.Errorf("signal %s = %+v, want dropped", tt.signal, ds)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("signal %s not decoded", tt.signal)
+			}
+			if ds.Raw != tt.raw {
+				t.Errorf("raw = %#v, want %#v", ds.Raw, tt.raw)
+			}
+			if ds.Physical == nil || *ds.Physical != tt.physical {
+				t.Errorf("physical = %v, want %g", ds.Physical, tt.physical)
+			}
+			if ds.Description != tt.description {
+				t.Errorf("description = %q, want %q", ds.Description, tt.description)
+			}
+			if ds.OutOfRange != tt.outOfRange {
+				t.Errorf("out of range = %t, want %t", ds.OutOfRange, tt.outOfRange)
+			}
+			if ds.UndefinedEnum != tt.undefinedEnum {
+				t.Errorf("undefined enum = %t, want %t", ds.UndefinedEnum, tt.undefinedEnum)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package dbc
+
+import (
+	"fmt"
+	"sort"
+	"text/scanner"
+)
+
+// Severity classifies a Diagnostic.
+type Severity int
+
+const (
+	// SeverityWarning marks definitions that compile but are likely mistakes.
+	SeverityWarning Severity = iota
+	// SeverityError marks definitions that are ignored or decode incorrectly.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// Diagnostic is a problem found in a DBC file while compiling or linting it.
+type Diagnostic struct {
+	Severity Severity
+	Pos      scanner.Position
+	Message  string
+}
+
+// String formats the diagnostic as file:line:column: severity: message.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Pos, d.Severity, d.Message)
+}
+
+// Diagnostics returns the problems found while compiling the DBC file, ordered by position.
+func (c *Compiler) Diagnostics() []Diagnostic {
+	diags := make([]Diagnostic, len(c.diagnostics))
+	copy(diags, c.diagnostics)
+	sortDiagnostics(diags)
+	return diags
+}
+
+func (c *Compiler) addDiagnostic(severity Severity, pos scanner.Position, format string, args ...any) {
+	c.diagnostics = append(c.diagnostics, newDiagnostic(severity, pos, format, args...))
+}
+
+func newDiagnostic(severity Severity, pos scanner.Position, format string, args ...any) Diagnostic {
+	return Diagnostic{
+		Severity: severity,
+		Pos:      pos,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func sortDiagnostics(diags []Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Pos.Line != diags[j].Pos.Line {
+			return diags[i].Pos.Line < diags[j].Pos.Line
+		}
+		return diags[i].Pos.Column < diags[j].Pos.Column
+	})
+}
@@ -0,0 +1,313 @@
+package dbc
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"go.einride.tech/can/pkg/descriptor"
+
+	"github.com/BIwashi/candecode/pkg/can"
+)
+
+// Encoder builds CAN frames from physical signal values, the inverse of Decoder.
+//
+// Signals without a value get their GenSigStartValue. A multiplexed signal selects its multiplexer
+// switch (the first value of its SG_MUL_VAL_ ranges, or its m<n> value) unless the switch is given.
+// Rolling counter and checksum signals without a value are filled in as checked by Validator: the
+// counter advances by one per encoded frame of the message, and the checksum is computed with the
+// algorithm detected from the DBC file name (see DetectChecksum) unless one is set with
+// WithEncoderChecksum.
+type Encoder struct {
+	compiler *Compiler
+	checksum Checksum
+	noDetect bool
+	counters map[*descriptor.Message]uint64
+}
+
+type EncoderOption interface {
+	apply(*Encoder)
+}
+
+type encoderChecksumOption struct {
+	checksum Checksum
+}
+
+func (o encoderChecksumOption) apply(e *Encoder) {
+	e.checksum = o.checksum
+	e.noDetect = true
+}
+
+// WithEncoderChecksum fills in checksums with c instead of the algorithm detected from the DBC
+// file name. A nil c leaves checksum signals at their given or default value.
+func WithEncoderChecksum(c Checksum) EncoderOption {
+	return encoderChecksumOption{checksum: c}
+}
+
+func NewEncoder(c *Compiler, opts ...EncoderOption) *Encoder {
+	e := &Encoder{
+		compiler: c,
+		counters: make(map[*descriptor.Message]uint64),
+	}
+	for _, o := range opts {
+		o.apply(e)
+	}
+	if !e.noDetect {
+		e.checksum, _ = DetectChecksum(c.SourceFile())
+	}
+	return e
+}
+
+// Encode builds a frame of the message called message from values, keyed by signal name.
+//
+// A value is a physical value (float64, float32, int, int64, uint64), a bool for one bit signals,
+// or a string holding a value description (enum label) or a number. Values outside the DBC
+// [Min, Max] range or the bit width of the signal, unknown signals and multiplexed signals whose
+// switch holds another value are errors.
+func (e *Encoder) Encode(message string, values map[string]any) (*can.Frame, error) {
+	m, ok := e.compiler.MessageByName(message)
+	if !ok {
+		return nil, errors.Newf("unknown message: %s", message)
+	}
+
+	enc := &encoding{
+		compiler: e.compiler,
+		raw:      make(map[*descriptor.Signal]uint64),
+		present:  make(map[*descriptor.Signal]bool),
+	}
+	signals := make(map[string]*descriptor.Signal, len(m.Signals))
+	for _, s := range m.Signals {
+		signals[s.Name] = s
+	}
+	for name := range values {
+		if _, ok := signals[name]; !ok {
+			return nil, errors.Newf("unknown signal %s in message %s", name, m.Name)
+		}
+	}
+	// Set in DBC order, so errors do not depend on map order
+	for _, s := range m.Signals {
+		v, ok := values[s.Name]
+		if !ok {
+			continue
+		}
+		raw, err := rawBits(s, v)
+		if err != nil {
+			return nil, fmt.Errorf("signal %s: %w", s.Name, err)
+		}
+		enc.raw[s] = raw
+	}
+	for _, s := range m.Signals {
+		if _, ok := values[s.Name]; ok && s.IsMultiplexed {
+			if err := enc.selectSwitch(s); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sigs := findValidatedSignals(m)
+	if s := sigs.counter; s != nil {
+		if _, ok := enc.raw[s]; !ok {
+			enc.raw[s] = e.counters[m]
+		}
+	}
+
+	f := &can.Frame{
+		ID:         m.ID,
+		IsExtended: m.IsExtended,
+		Length:     m.Length,
+		IsFD:       m.Length > can.MaxDataLength,
+	}
+	for _, s := range m.Signals {
+		if !enc.isPresent(s) {
+			if _, ok := values[s.Name]; ok {
+				return nil, errors.Newf("signal %s is not selected by the value of its multiplexer switch", s.Name)
+			}
+			continue
+		}
+		setSignal(e.compiler, s, &f.Data, enc.value(s))
+	}
+
+	if s := sigs.checksum; s != nil && e.checksum != nil {
+		if _, ok := values[s.Name]; !ok {
+			counter, hasCounter := uint64(0), sigs.counter != nil
+			if hasCounter {
+				counter = enc.value(sigs.counter)
+			}
+			setSignal(e.compiler, s, &f.Data, 0)
+			if checksum, ok := e.checksum.Compute(ChecksumFrame{
+				ID:            f.ID,
+				IsExtended:    f.IsExtended,
+				Data:          f.Payload(),
+				ChecksumBytes: signalBytes(e.compiler, s),
+				Counter:       counter,
+				HasCounter:    hasCounter,
+			}); ok {
+				setSignal(e.compiler, s, &f.Data, checksum)
+			}
+		}
+	}
+
+	if s := sigs.counter; s != nil {
+		e.counters[m] = (enc.raw[s] + 1) & mask(s.Length)
+	}
+	return f, nil
+}
+
+// encoding holds the raw values of one Encode call.
+type encoding struct {
+	compiler *Compiler
+	// raw holds the bits of the given and implied signal values
+	raw     map[*descriptor.Signal]uint64
+	present map[*descriptor.Signal]bool
+}
+
+// value returns the bits of s: the given or implied value, else GenSigStartValue.
+func (enc *encoding) value(s *descriptor.Signal) uint64 {
+	if raw, ok := enc.raw[s]; ok {
+		return raw
+	}
+	if s.IsFloat {
+		return floatBits(s, float64(s.DefaultValue))
+	}
+	return uint64(int64(s.DefaultValue)) & mask(s.Length)
+}
+
+// selectSwitch sets the multiplexer switches s depends on, directly or through nested switches,
+// to a value selecting s. Switches with a given value must already select s.
+func (enc *encoding) selectSwitch(s *descriptor.Signal) error {
+	for depth := 0; s.IsMultiplexed && depth < len(enc.compiler.multiplexing); depth++ {
+		mux, ok := enc.compiler.multiplexing[s]
+		if !ok || len(mux.ranges) == 0 {
+			return errors.Newf("signal %s has no multiplexer switch", s.Name)
+		}
+		if raw, ok := enc.raw[mux.switchSignal]; ok {
+			if !inRanges(raw, mux.ranges) {
+				return errors.Newf("signal %s is not selected by %s=%d", s.Name, mux.switchSignal.Name, raw)
+			}
+		} else {
+			enc.raw[mux.switchSignal] = mux.ranges[0].Start
+		}
+		s = mux.switchSignal
+	}
+	return nil
+}
+
+// isPresent reports whether s is part of the frame under the switch values.
+func (enc *encoding) isPresent(s *descriptor.Signal) bool {
+	if present, ok := enc.present[s]; ok {
+		return present
+	}
+	enc.present[s] = false // cycle guard
+	present := !s.IsMultiplexed
+	if mux, ok := enc.compiler.multiplexing[s]; ok && enc.isPresent(mux.switchSignal) {
+		present = inRanges(enc.value(mux.switchSignal), mux.ranges)
+	}
+	enc.present[s] = present
+	return present
+}
+
+func inRanges(v uint64, ranges []MultiplexRange) bool {
+	for _, r := range ranges {
+		if v >= r.Start && v <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// rawBits converts the value v of s to the bits of the signal.
+func rawBits(s *descriptor.Signal, v any) (uint64, error) {
+	var physical float64
+	switch v := v.(type) {
+	case bool:
+		if v {
+			physical = 1
+		}
+	case int:
+		physical = float64(v)
+	case int64:
+		physical = float64(v)
+	case uint64:
+		physical = float64(v)
+	case float32:
+		physical = float64(v)
+	case float64:
+		physical = v
+	case string:
+		for _, vd := range s.ValueDescriptions {
+			if vd.Description == v {
+				return integerBits(s, float64(vd.Value))
+			}
+		}
+		var err error
+		if physical, err = strconv.ParseFloat(v, 64); err != nil {
+			b, err := strconv.ParseBool(v)
+			if err != nil || s.Length != 1 {
+				return 0, errors.Newf("%q is neither a number nor a value description", v)
+			}
+			if b {
+				physical = 1
+			}
+		}
+	default:
+		return 0, errors.Newf("unsupported value type %T", v)
+	}
+
+	if math.IsNaN(physical) || math.IsInf(physical, 0) {
+		return 0, errors.Newf("%g is not a finite value", physical)
+	}
+	if outOfRange(s, physical) {
+		return 0, errors.Newf("%g outside [%g, %g]", physical, s.Min, s.Max)
+	}
+	scale := s.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	raw := (physical - s.Offset) / scale
+	if s.IsFloat {
+		return floatBits(s, raw), nil
+	}
+	return integerBits(s, math.Round(raw))
+}
+
+// integerBits returns the two's complement bits of raw, which must fit the bit width of s.
+func integerBits(s *descriptor.Signal, raw float64) (uint64, error) {
+	// [lo, hi) with an exclusive hi, exact in float64 up to 64 bit signals
+	lo, hi := 0.0, math.Ldexp(1, int(s.Length))
+	if s.IsSigned {
+		lo, hi = -math.Ldexp(1, int(s.Length)-1), math.Ldexp(1, int(s.Length)-1)
+	}
+	if math.IsNaN(raw) || raw < lo || raw >= hi {
+		return 0, errors.Newf("raw value %g does not fit in %d bits", raw, s.Length)
+	}
+	if raw < 0 {
+		return uint64(int64(raw)) & mask(s.Length), nil
+	}
+	return uint64(raw), nil
+}
+
+// floatBits returns the IEEE 754 single or double bits of raw.
+func floatBits(s *descriptor.Signal, raw float64) uint64 {
+	if s.Length == 64 {
+		return math.Float64bits(raw)
+	}
+	return uint64(math.Float32bits(float32(raw)))
+}
+
+func mask(length uint8) uint64 {
+	if length >= 64 {
+		return math.MaxUint64
+	}
+	return 1<<length - 1
+}
+
+// setSignal writes the bits of s to data using the full (FD capable) start bit.
+func setSignal(c *Compiler, s *descriptor.Signal, data *can.Data, raw uint64) {
+	start := c.StartBit(s)
+	if s.IsBigEndian {
+		data.SetUnsignedBitsBigEndian(start, uint16(s.Length), raw)
+		return
+	}
+	data.SetUnsignedBitsLittleEndian(start, uint16(s.Length), raw)
+}
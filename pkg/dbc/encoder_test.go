@@ -0,0 +1,117 @@
+package dbc
+
+import (
+	"math"
+	"testing"
+
+	"go.einride.tech/can/pkg/descriptor"
+
+	"github.com/BIwashi/candecode/pkg/can"
+)
+
+func encoderTestMessages() []*descriptor.Message {
+	return []*descriptor.Message{
+		{
+			Name: "ENGINE", ID: 0x1A0, Length: 8,
+			Signals: []*descriptor.Signal{
+				{Name: "SPEED", Start: 0, Length: 16, Scale: 0.01},
+				{Name: "TEMP", Start: 23, Length: 8, IsBigEndian: true, IsSigned: true, Scale: 0.5, Min: -64, Max: 63.5},
+				{Name: "GEAR", Start: 24, Length: 3, Scale: 1, ValueDescriptions: []*descriptor.ValueDescription{
+					{Value: 0, Description: "P"}, {Value: 1, Description: "R"}, {Value: 2, Description: "N"}, {Value: 3, Description: "D"},
+				}},
+				{Name: "FLAG", Start: 27, Length: 1},
+				{Name: "COUNTER", Start: 52, Length: 4},
+				{Name: "CHECKSUM", Start: 56, Length: 8},
+			},
+		},
+		{
+			Name: "RATIOS", ID: 0x1A1, Length: 8,
+			Signals: []*descriptor.Signal{
+				{Name: "RATIO", Start: 0, Length: 32, IsFloat: true, Scale: 1},
+				{Name: "WIDE", Start: 32, Length: 32, Scale: 1},
+			},
+		},
+	}
+}
+
+func TestEncoderDecoderRoundTrip(t *testing.T) {
+	c := testCompiler(encoderTestMessages(), nil)
+	e := NewEncoder(c, WithEncoderChecksum(toyotaChecksum{}))
+	d := NewDecoder(c)
+
+	for counter := uint64(0); counter < 3; counter++ {
+		f, err := e.Encode("ENGINE", map[string]any{"SPEED": 123.45, "TEMP": "-12.5", "GEAR": "D", "FLAG": true})
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		signals, err := d.Decode(&can.TimedFrame{Frame: *f})
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+
+		if ds := signals["SPEED"]; ds.Raw != uint64(12345) {
+			t.Errorf("SPEED raw = %#v, want 12345", ds.Raw)
+		}
+		if ds := signals["TEMP"]; ds.Physical == nil || *ds.Physical != -12.5 {
+			t.Errorf("TEMP physical = %v, want -12.5", ds.Physical)
+		}
+		if ds := signals["GEAR"]; ds.Raw != uint64(3) || ds.Description != "D" {
+			t.Errorf("GEAR = %#v (%s), want 3 (D)", ds.Raw, ds.Description)
+		}
+		if ds := signals["FLAG"]; ds.Raw != true {
+			t.Errorf("FLAG raw = %#v, want true", ds.Raw)
+		}
+		if ds := signals["COUNTER"]; ds.Raw != counter {
+			t.Errorf("COUNTER raw = %#v, want %d", ds.Raw, counter)
+		}
+		want, _ := toyotaChecksum{}.Compute(ChecksumFrame{ID: f.ID, Data: f.Payload()})
+		if ds := signals["CHECKSUM"]; ds.Raw != want {
+			t.Errorf("CHECKSUM raw = %#v, want %d", ds.Raw, want)
+		}
+	}
+
+	f, err := e.Encode("RATIOS", map[string]any{"RATIO": float32(0.25), "WIDE": uint64(math.MaxUint32)})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	signals, err := d.Decode(&can.TimedFrame{Frame: *f})
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if ds := signals["RATIO"]; ds.Raw != 0.25 {
+		t.Errorf("RATIO raw = %#v, want 0.25", ds.Raw)
+	}
+	if ds := signals["WIDE"]; ds.Raw != uint64(math.MaxUint32) {
+		t.Errorf("WIDE raw = %#v, want %d", ds.Raw, uint64(math.MaxUint32))
+	}
+}
+
+func TestEncoderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		values  map[string]any
+	}{
+		{"unknown message", "NOPE", nil},
+		{"unknown signal", "ENGINE", map[string]any{"NOPE": 1}},
+		{"NaN string", "ENGINE", map[string]any{"SPEED": "NaN"}},
+		{"NaN", "ENGINE", map[string]any{"SPEED": math.NaN()}},
+		{"infinite string", "ENGINE", map[string]any{"SPEED": "+Inf"}},
+		{"infinite", "RATIOS", map[string]any{"RATIO": math.Inf(-1)}},
+		{"above max", "ENGINE", map[string]any{"TEMP": 64}},
+		{"below min", "ENGINE", map[string]any{"TEMP": int64(-65)}},
+		{"wider than the signal", "ENGINE", map[string]any{"SPEED": 655.36}},
+		{"negative unsigned", "ENGINE", map[string]any{"SPEED": -1}},
+		{"neither a number nor a description", "ENGINE", map[string]any{"GEAR": "X"}},
+		{"bool string for a wide signal", "ENGINE", map[string]any{"GEAR": "true"}},
+		{"unsupported type", "ENGINE", map[string]any{"SPEED": int32(1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEncoder(testCompiler(encoderTestMessages(), nil))
+			if f, err := e.Encode(tt.message, tt.values); err == nil {
+				t.Errorf("Encode() = % X, want an error", f.Payload())
+			}
+		})
+	}
+}
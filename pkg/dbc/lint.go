@@ -0,0 +1,138 @@
+package dbc
+
+import (
+	"math"
+
+	"go.einride.tech/can/pkg/dbc"
+	"go.einride.tech/can/pkg/descriptor"
+
+	"github.com/BIwashi/candecode/pkg/can"
+)
+
+// Lint returns the compiler diagnostics plus the findings of additional consistency checks:
+//   - duplicate message IDs
+//   - signals that extend past the message length
+//   - signals whose bits overlap (multiplexed signals only collide within the same multiplexer value)
+//   - min/max ranges the signal bit width cannot reach
+//   - multiplexer values the bit width of the switch cannot reach
+func (c *Compiler) Lint() []Diagnostic {
+	diags := c.Diagnostics()
+	diags = append(diags, c.lintDuplicateMessages()...)
+	for _, m := range c.db.Messages {
+		diags = append(diags, c.lintSignalLayout(m)...)
+		diags = append(diags, c.lintSignalRanges(m)...)
+		diags = append(diags, c.lintMultiplexRanges(m)...)
+	}
+	sortDiagnostics(diags)
+	return diags
+}
+
+func (c *Compiler) lintDuplicateMessages() []Diagnostic {
+	var (
+		diags []Diagnostic
+		seen  = make(map[dbc.MessageID]dbc.Identifier)
+	)
+	for _, def := range c.defs {
+		def, ok := def.(*dbc.MessageDef)
+		if !ok || def.MessageID == dbc.IndependentSignalsMessageID {
+			continue
+		}
+		if name, ok := seen[def.MessageID]; ok {
+			diags = append(diags, newDiagnostic(SeverityError, def.Pos,
+				"duplicate message id 0x%X: %s already used by %s", def.MessageID.ToCAN(), def.Name, name))
+			continue
+		}
+		seen[def.MessageID] = def.Name
+	}
+	return diags
+}
+
+func (c *Compiler) lintSignalLayout(m *descriptor.Message) []Diagnostic {
+	var (
+		diags   []Diagnostic
+		pos     = c.positions[m]
+		msgBits = uint16(m.Length) * 8
+		bits    = make([][]uint16, len(m.Signals))
+	)
+	for i, s := range m.Signals {
+		bits[i] = can.SignalBits(c.StartBit(s), uint16(s.Length), s.IsBigEndian)
+		for _, b := range bits[i] {
+			if b >= msgBits {
+				diags = append(diags, newDiagnostic(SeverityError, pos,
+					"signal %s of message %s extends past the message length of %d bytes", s.Name, m.Name, m.Length))
+				break
+			}
+		}
+	}
+
+	for i, a := range m.Signals {
+		used := make(map[uint16]struct{}, len(bits[i]))
+		for _, b := range bits[i] {
+			used[b] = struct{}{}
+		}
+		for j := i + 1; j < len(m.Signals); j++ {
+			b := m.Signals[j]
+			if c.exclusive(a, b) {
+				continue // never present in the same frame
+			}
+			for _, bit := range bits[j] {
+				if _, ok := used[bit]; ok {
+					diags = append(diags, newDiagnostic(SeverityError, pos,
+						"signals %s and %s of message %s overlap at bit %d", a.Name, b.Name, m.Name, bit))
+					break
+				}
+			}
+		}
+	}
+	return diags
+}
+
+func (c *Compiler) lintSignalRanges(m *descriptor.Message) []Diagnostic {
+	var diags []Diagnostic
+	for _, s := range m.Signals {
+		if s.IsFloat || s.Scale == 0 || (s.Min == 0 && s.Max == 0) {
+			continue
+		}
+		var (
+			rawMin = 0.0
+			rawMax = math.Ldexp(1, int(s.Length)) - 1
+		)
+		if s.IsSigned {
+			rawMin = -math.Ldexp(1, int(s.Length)-1)
+			rawMax = math.Ldexp(1, int(s.Length)-1) - 1
+		}
+		// Not descriptor.Signal.ToPhysical, which clamps to [Min, Max] and would hide the problem
+		var (
+			physMin = rawMin*s.Scale + s.Offset
+			physMax = rawMax*s.Scale + s.Offset
+			lo      = math.Min(physMin, physMax)
+			hi      = math.Max(physMin, physMax)
+			eps     = math.Abs(s.Scale) / 2 // tolerate rounding of min/max in the DBC
+		)
+		if s.Min < lo-eps || s.Max > hi+eps {
+			diags = append(diags, newDiagnostic(SeverityWarning, c.positions[m],
+				"range [%g, %g] of signal %s in message %s is not reachable with %d bits (reachable [%g, %g])",
+				s.Min, s.Max, s.Name, m.Name, s.Length, lo, hi))
+		}
+	}
+	return diags
+}
+
+func (c *Compiler) lintMultiplexRanges(m *descriptor.Message) []Diagnostic {
+	var diags []Diagnostic
+	for _, s := range m.Signals {
+		mux, ok := c.multiplexing[s]
+		if !ok {
+			continue
+		}
+		maxValue := uint64(1)<<mux.switchSignal.Length - 1
+		for _, r := range mux.ranges {
+			if r.Start > maxValue {
+				diags = append(diags, newDiagnostic(SeverityWarning, c.positions[m],
+					"multiplexer values %d-%d of signal %s in message %s are not reachable with the %d bits of switch %s",
+					r.Start, r.End, s.Name, m.Name, mux.switchSignal.Length, mux.switchSignal.Name))
+			}
+		}
+	}
+	return diags
+}
@@ -0,0 +1,141 @@
+package dbc
+
+import (
+	"strings"
+	"testing"
+
+	"go.einride.tech/can/pkg/dbc"
+)
+
+// compileSource compiles DBC source text.
+func compileSource(t *testing.T, source string) *Compiler {
+	t.Helper()
+	p := dbc.NewParser("test.dbc", []byte(source))
+	if err := p.Parse(); err != nil {
+		t.Fatalf("failed to parse DBC: %v", err)
+	}
+	return compile("test.dbc", []byte(source), p.Defs())
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name: "clean",
+			source: `
+BO_ 100 A: 8 ECU
+ SG_ S1 : 0|8@1+ (1,0) [0|255] "" ECU
+ SG_ S2 : 15|8@0+ (1,0) [0|255] "" ECU
+ SG_ S3 : 16|8@1- (0.5,0) [-64|63.5] "" ECU
+ SG_ S4 : 24|8@1+ (1,0) [0|255.4] "" ECU
+`,
+		},
+		{
+			name: "duplicate message id",
+			source: `
+BO_ 100 A: 8 ECU
+ SG_ S : 0|8@1+ (1,0) [0|255] "" ECU
+
+BO_ 100 B: 8 ECU
+ SG_ T : 0|8@1+ (1,0) [0|255] "" ECU
+`,
+			want: []string{"error: duplicate message id 0x64: B already used by A"},
+		},
+		{
+			name: "signal past the message length",
+			source: `
+BO_ 100 A: 2 ECU
+ SG_ LE : 8|16@1+ (1,0) [0|65535] "" ECU
+
+BO_ 101 B: 1 ECU
+ SG_ BE : 7|16@0+ (1,0) [0|65535] "" ECU
+`,
+			want: []string{
+				"error: signal LE of message A extends past the message length of 2 bytes",
+				"error: signal BE of message B extends past the message length of 1 bytes",
+			},
+		},
+		{
+			name: "overlapping signals",
+			source: `
+BO_ 100 A: 8 ECU
+ SG_ S1 : 0|8@1+ (1,0) [0|255] "" ECU
+ SG_ S2 : 4|8@1+ (1,0) [0|255] "" ECU
+`,
+			want: []string{"error: signals S1 and S2 of message A overlap at bit 4"},
+		},
+		{
+			name: "multiplexed signals only overlap for the same switch value",
+			source: `
+BO_ 100 A: 8 ECU
+ SG_ MODE M : 0|4@1+ (1,0) [0|15] "" ECU
+ SG_ A m0 : 8|8@1+ (1,0) [0|255] "" ECU
+ SG_ B m1 : 8|8@1+ (1,0) [0|255] "" ECU
+ SG_ C m0 : 12|8@1+ (1,0) [0|255] "" ECU
+ SG_ D : 2|4@1+ (1,0) [0|15] "" ECU
+`,
+			want: []string{
+				"error: signals MODE and D of message A overlap at bit 2",
+				"error: signals A and C of message A overlap at bit 12",
+			},
+		},
+		{
+			name: "overlapping extended multiplexer ranges",
+			source: `
+BO_ 100 A: 8 ECU
+ SG_ MODE M : 0|4@1+ (1,0) [0|15] "" ECU
+ SG_ A m0 : 8|8@1+ (1,0) [0|255] "" ECU
+ SG_ B m2 : 8|8@1+ (1,0) [0|255] "" ECU
+ SG_ C m4 : 8|8@1+ (1,0) [0|255] "" ECU
+
+SG_MUL_VAL_ 100 A MODE 0-1;
+SG_MUL_VAL_ 100 B MODE 1-2;
+SG_MUL_VAL_ 100 C MODE 3-4;
+`,
+			want: []string{"error: signals A and B of message A overlap at bit 8"},
+		},
+		{
+			name: "unreachable range",
+			source: `
+BO_ 100 A: 8 ECU
+ SG_ U : 0|8@1+ (1,0) [0|300] "" ECU
+ SG_ S : 8|8@1- (1,0) [-200|100] "" ECU
+`,
+			want: []string{
+				"warning: range [0, 300] of signal U in message A is not reachable with 8 bits (reachable [0, 255])",
+				"warning: range [-200, 100] of signal S in message A is not reachable with 8 bits (reachable [-128, 127])",
+			},
+		},
+		{
+			name: "unreachable multiplexer values",
+			source: `
+BO_ 100 A: 8 ECU
+ SG_ MODE M : 0|2@1+ (1,0) [0|3] "" ECU
+ SG_ A m3 : 8|8@1+ (1,0) [0|255] "" ECU
+ SG_ B m5 : 16|8@1+ (1,0) [0|255] "" ECU
+ SG_ C m0 : 24|8@1+ (1,0) [0|255] "" ECU
+
+SG_MUL_VAL_ 100 C MODE 2-3, 8-9;
+`,
+			want: []string{
+				// signals are ordered by multiplexer value
+				"warning: multiplexer values 8-9 of signal C in message A are not reachable with the 2 bits of switch MODE",
+				"warning: multiplexer values 5-5 of signal B in message A are not reachable with the 2 bits of switch MODE",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range compileSource(t, tt.source).Lint() {
+				got = append(got, d.Severity.String()+": "+d.Message)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Lint() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
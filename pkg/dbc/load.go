@@ -0,0 +1,67 @@
+package dbc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// LoadedFile is a DBC file compiled by LoadDecoder.
+type LoadedFile struct {
+	Path     string
+	Compiler *Compiler
+	// Buses are the buses mapped to the file, in mapping order; empty for the default DBC file only.
+	Buses []string
+}
+
+// LoadDecoder compiles the default DBC file defaultFile (optional) and the per-bus DBC files of
+// mappings given as bus=path, and returns a decoder over them with opts. A DBC file referenced
+// more than once is compiled only once. The files are also returned in order of first reference.
+func LoadDecoder(defaultFile string, mappings []string, opts ...DecoderOption) (*Decoder, []LoadedFile, error) {
+	var (
+		files     []LoadedFile
+		fileIndex = make(map[string]int)
+	)
+	compile := func(path, bus string) (*Compiler, error) {
+		i, ok := fileIndex[path]
+		if !ok {
+			c, err := NewCompiler(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create DBC compiler for %s: %w", path, err)
+			}
+			i = len(files)
+			fileIndex[path] = i
+			files = append(files, LoadedFile{Path: path, Compiler: c})
+		}
+		if bus != "" {
+			files[i].Buses = append(files[i].Buses, bus)
+		}
+		return files[i].Compiler, nil
+	}
+
+	var defaultCompiler *Compiler
+	if defaultFile != "" {
+		c, err := compile(defaultFile, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		defaultCompiler = c
+	}
+
+	decoderOpts := make([]DecoderOption, 0, len(opts)+len(mappings))
+	decoderOpts = append(decoderOpts, opts...)
+	for _, m := range mappings {
+		bus, path, ok := strings.Cut(m, "=")
+		if !ok || bus == "" || path == "" {
+			return nil, nil, errors.Newf("invalid DBC mapping %q, expected bus=path", m)
+		}
+		c, err := compile(path, bus)
+		if err != nil {
+			return nil, nil, err
+		}
+		decoderOpts = append(decoderOpts, WithBusCompiler(bus, c))
+	}
+
+	return NewDecoder(defaultCompiler, decoderOpts...), files, nil
+}
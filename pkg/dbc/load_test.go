@@ -0,0 +1,39 @@
+package dbc
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadDecoder(t *testing.T) {
+	dir := t.TempDir()
+	pt := filepath.Join(dir, "pt.dbc")
+	adas := filepath.Join(dir, "adas.dbc")
+	for _, path := range []string{pt, adas} {
+		if err := os.WriteFile(path, []byte("VERSION \"\"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, files, err := LoadDecoder(pt, []string{"can0=" + adas, "can1=" + pt, "can2=" + adas})
+	if err != nil {
+		t.Fatalf("LoadDecoder() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("LoadDecoder() files = %+v, want 2", files)
+	}
+	if files[0].Path != pt || !slices.Equal(files[0].Buses, []string{"can1"}) {
+		t.Errorf("files[0] = %s %v, want %s [can1]", files[0].Path, files[0].Buses, pt)
+	}
+	if files[1].Path != adas || !slices.Equal(files[1].Buses, []string{"can0", "can2"}) {
+		t.Errorf("files[1] = %s %v, want %s [can0 can2]", files[1].Path, files[1].Buses, adas)
+	}
+
+	for _, mappings := range [][]string{{"can0"}, {"=" + pt}, {"can0="}, {"can0=" + filepath.Join(dir, "missing.dbc")}} {
+		if _, _, err := LoadDecoder("", mappings); err == nil {
+			t.Errorf("LoadDecoder(%q) error = nil", mappings)
+		}
+	}
+}
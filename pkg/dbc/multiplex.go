@@ -0,0 +1,173 @@
+package dbc
+
+import (
+	"go.einride.tech/can/pkg/dbc"
+	"go.einride.tech/can/pkg/descriptor"
+
+	"github.com/BIwashi/candecode/pkg/can"
+)
+
+// MultiplexRange is an inclusive range of multiplexer switch values.
+type MultiplexRange struct {
+	Start uint64
+	End   uint64
+}
+
+// multiplexing is the multiplexer switch of a multiplexed signal and the switch values that select it.
+type multiplexing struct {
+	switchSignal *descriptor.Signal
+	ranges       []MultiplexRange
+}
+
+// addMultiplexing resolves the multiplexer switch of every multiplexed signal.
+//
+// Without extended multiplexing a message has a single switch and a multiplexed signal is present
+// when the switch equals its multiplexer value (m<n>). Extended multiplexing (SG_MUL_VAL_) names the
+// switch and value ranges per signal, allowing several switches and multiplexed switches (m<n>M)
+// that form a tree.
+func (c *Compiler) addMultiplexing() {
+	for _, m := range c.db.Messages {
+		var defaultSwitch *descriptor.Signal
+		for _, s := range m.Signals {
+			if s.IsMultiplexer && !s.IsMultiplexed {
+				defaultSwitch = s
+				break
+			}
+		}
+		for _, s := range m.Signals {
+			if !s.IsMultiplexed || defaultSwitch == nil {
+				continue
+			}
+			value := uint64(s.MultiplexerValue)
+			c.multiplexing[s] = multiplexing{
+				switchSignal: defaultSwitch,
+				ranges:       []MultiplexRange{{Start: value, End: value}},
+			}
+		}
+	}
+
+	for _, def := range c.defs {
+		def, ok := def.(*dbc.SignalMultiplexValueDef)
+		if !ok {
+			continue
+		}
+		signal, ok := c.db.Signal(def.MessageID.ToCAN(), string(def.Signal))
+		if !ok {
+			c.addDiagnostic(SeverityWarning, def.Pos, "SG_MUL_VAL_ for undeclared signal %s in message 0x%X", def.Signal, def.MessageID.ToCAN())
+			continue
+		}
+		switchSignal, ok := c.db.Signal(def.MessageID.ToCAN(), string(def.MultiplexerSwitch))
+		if !ok || !switchSignal.IsMultiplexer {
+			c.addDiagnostic(SeverityError, def.Pos, "SG_MUL_VAL_ of signal %s names %s, which is no multiplexer switch of message 0x%X",
+				def.Signal, def.MultiplexerSwitch, def.MessageID.ToCAN())
+			continue
+		}
+		if !signal.IsMultiplexed {
+			c.addDiagnostic(SeverityWarning, def.Pos, "SG_MUL_VAL_ for signal %s, which is not multiplexed", def.Signal)
+			continue
+		}
+		mux := multiplexing{switchSignal: switchSignal}
+		for _, r := range def.Ranges {
+			mux.ranges = append(mux.ranges, MultiplexRange{Start: r.RangeStart, End: r.RangeEnd})
+		}
+		c.multiplexing[signal] = mux
+	}
+}
+
+// Multiplexer returns the multiplexer switch of s and the switch values that select s.
+// ok is false for signals that are not multiplexed.
+func (c *Compiler) Multiplexer(s *descriptor.Signal) (switchSignal *descriptor.Signal, ranges []MultiplexRange, ok bool) {
+	mux, ok := c.multiplexing[s]
+	if !ok {
+		return nil, nil, false
+	}
+	return mux.switchSignal, mux.ranges, true
+}
+
+// exclusive reports whether a and b are never present in the same frame: both depend, directly
+// or through nested switches, on the same switch with disjoint values.
+func (c *Compiler) exclusive(a, b *descriptor.Signal) bool {
+	for sa := a; ; {
+		muxA, ok := c.multiplexing[sa]
+		if !ok {
+			return false
+		}
+		for sb := b; ; {
+			muxB, ok := c.multiplexing[sb]
+			if !ok {
+				break
+			}
+			if muxA.switchSignal == muxB.switchSignal && !overlaps(muxA.ranges, muxB.ranges) {
+				return true
+			}
+			sb = muxB.switchSignal
+		}
+		sa = muxA.switchSignal
+	}
+}
+
+func overlaps(a, b []MultiplexRange) bool {
+	for _, ra := range a {
+		for _, rb := range b {
+			if ra.Start <= rb.End && rb.Start <= ra.End {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// multiplexTree resolves which signals of a message are present in one frame.
+type multiplexTree struct {
+	compiler *Compiler
+	frame    *can.TimedFrame
+	// truncated frames leave the switches past their end undecided
+	truncated bool
+	present   map[*descriptor.Signal]bool
+	visiting  map[*descriptor.Signal]bool
+}
+
+func newMultiplexTree(c *Compiler, f *can.TimedFrame, truncated bool) *multiplexTree {
+	return &multiplexTree{
+		compiler:  c,
+		frame:     f,
+		truncated: truncated,
+		present:   make(map[*descriptor.Signal]bool),
+		visiting:  make(map[*descriptor.Signal]bool),
+	}
+}
+
+// isPresent reports whether s is part of the frame: it is not multiplexed, or its switch is present,
+// decodable and holds one of its switch values.
+func (t *multiplexTree) isPresent(s *descriptor.Signal) bool {
+	if present, ok := t.present[s]; ok {
+		return present
+	}
+	if !s.IsMultiplexed {
+		return true
+	}
+	mux, ok := t.compiler.multiplexing[s]
+	if !ok || t.visiting[s] {
+		return false // no switch, or a cycle of switches
+	}
+
+	t.visiting[s] = true
+	present := t.isPresent(mux.switchSignal)
+	delete(t.visiting, s)
+
+	if present && t.truncated && !signalFits(t.compiler, mux.switchSignal, t.frame.Length) {
+		present = false
+	}
+	if present {
+		value := unmarshalUnsigned(t.compiler, mux.switchSignal, &t.frame.Data)
+		present = false
+		for _, r := range mux.ranges {
+			if value >= r.Start && value <= r.End {
+				present = true
+				break
+			}
+		}
+	}
+	t.present[s] = present
+	return present
+}
@@ -0,0 +1,283 @@
+package dbc
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.einride.tech/can/pkg/dbc"
+	"go.einride.tech/can/pkg/descriptor"
+
+	"github.com/BIwashi/candecode/pkg/can"
+)
+
+// muxSignal is the definition of an 8 bit or narrower little-endian signal with a multiplexer
+// indicator as written in DBC files: "" (plain), "M" (switch), "m<n>" or "m<n>M" (multiplexed switch).
+func muxSignal(name string, start, size uint64, indicator string) dbc.SignalDef {
+	s := dbc.SignalDef{Name: dbc.Identifier(name), StartBit: start, Size: size, Factor: 1, Maximum: float64(uint64(1)<<size - 1)}
+	if indicator == "M" {
+		s.IsMultiplexerSwitch = true
+		return s
+	}
+	if rest, ok := strings.CutPrefix(indicator, "m"); ok {
+		s.IsMultiplexed = true
+		rest, s.IsMultiplexerSwitch = strings.CutSuffix(rest, "M")
+		value, err := strconv.ParseUint(rest, 10, 64)
+		if err != nil {
+			panic(err)
+		}
+		s.MultiplexerSwitch = value
+	}
+	return s
+}
+
+func muxValues(id dbc.MessageID, signal, switchSignal string, ranges ...uint64) *dbc.SignalMultiplexValueDef {
+	def := &dbc.SignalMultiplexValueDef{
+		MessageID:         id,
+		Signal:            dbc.Identifier(signal),
+		MultiplexerSwitch: dbc.Identifier(switchSignal),
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		def.Ranges = append(def.Ranges, dbc.SignalMultiplexValueRange{RangeStart: ranges[i], RangeEnd: ranges[i+1]})
+	}
+	return def
+}
+
+// multiplexTestCompiler compiles the definitions of:
+//
+//	BO_ 512 MUX: 8 ECU
+//	 SG_ MODE M : 0|4@1+ (1,0) [0|15] "" Vector__XXX
+//	 SG_ A m0 : 8|8@1+ (1,0) [0|255] "" Vector__XXX
+//	 SG_ B m1 : 8|8@1+ (1,0) [0|255] "" Vector__XXX
+//	 SG_ SUB m2M : 8|4@1+ (1,0) [0|15] "" Vector__XXX
+//	 SG_ C m0 : 16|8@1+ (1,0) [0|255] "" Vector__XXX
+//	 SG_ D m5 : 16|8@1+ (1,0) [0|255] "" Vector__XXX
+//	 SG_ E m7 : 24|8@1+ (1,0) [0|255] "" Vector__XXX
+//	 SG_ F m4 : 32|8@1+ (1,0) [0|255] "" Vector__XXX
+//	 SG_ G m3 : 56|8@1+ (1,0) [0|255] "" Vector__XXX
+//
+//	BO_ 768 CYCLE: 8 ECU
+//	 SG_ X m1M : 0|4@1+ (1,0) [0|15] "" Vector__XXX
+//	 SG_ Y m1M : 4|4@1+ (1,0) [0|15] "" Vector__XXX
+//
+//	SG_MUL_VAL_ 512 SUB MODE 2-3;
+//	SG_MUL_VAL_ 512 C SUB 0-1;
+//	SG_MUL_VAL_ 512 D SUB 5-9;
+//	SG_MUL_VAL_ 512 F MODE 5-6, 10-10;
+//	SG_MUL_VAL_ 768 X Y 1-1;
+//	SG_MUL_VAL_ 768 Y X 1-1;
+//	SG_MUL_VAL_ 512 NOPE MODE 1-1;
+//	SG_MUL_VAL_ 512 A B 1-1;
+//	SG_MUL_VAL_ 512 MODE SUB 1-1;
+//
+// C and D are nested two levels deep (MODE selects SUB, SUB selects C and D), F overrides its m4 value,
+// X and Y select each other and the last three SG_MUL_VAL_ are invalid.
+func multiplexTestCompiler() *Compiler {
+	return compile("mux.dbc", nil, []dbc.Def{
+		&dbc.MessageDef{MessageID: 512, Name: "MUX", Size: 8, Signals: []dbc.SignalDef{
+			muxSignal("MODE", 0, 4, "M"),
+			muxSignal("A", 8, 8, "m0"),
+			muxSignal("B", 8, 8, "m1"),
+			muxSignal("SUB", 8, 4, "m2M"),
+			muxSignal("C", 16, 8, "m0"),
+			muxSignal("D", 16, 8, "m5"),
+			muxSignal("E", 24, 8, "m7"),
+			muxSignal("F", 32, 8, "m4"),
+			muxSignal("G", 56, 8, "m3"),
+		}},
+		&dbc.MessageDef{MessageID: 768, Name: "CYCLE", Size: 8, Signals: []dbc.SignalDef{
+			muxSignal("X", 0, 4, "m1M"),
+			muxSignal("Y", 4, 4, "m1M"),
+		}},
+		muxValues(512, "SUB", "MODE", 2, 3),
+		muxValues(512, "C", "SUB", 0, 1),
+		muxValues(512, "D", "SUB", 5, 9),
+		muxValues(512, "F", "MODE", 5, 6, 10, 10),
+		muxValues(768, "X", "Y", 1, 1),
+		muxValues(768, "Y", "X", 1, 1),
+		muxValues(512, "NOPE", "MODE", 1, 1),
+		muxValues(512, "A", "B", 1, 1),
+		muxValues(512, "MODE", "SUB", 1, 1),
+	})
+}
+
+func testSignal(t *testing.T, c *Compiler, message, name string) *descriptor.Signal {
+	t.Helper()
+	m, ok := c.MessageByName(message)
+	if !ok {
+		t.Fatalf("no message %s", message)
+	}
+	for _, s := range m.Signals {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("no signal %s in %s", name, message)
+	return nil
+}
+
+func TestMultiplexer(t *testing.T) {
+	c := multiplexTestCompiler()
+	tests := []struct {
+		signal     string
+		wantSwitch string
+		wantRanges []MultiplexRange
+	}{
+		{"MODE", "", nil},
+		{"A", "MODE", []MultiplexRange{{0, 0}}},
+		{"B", "MODE", []MultiplexRange{{1, 1}}},
+		{"SUB", "MODE", []MultiplexRange{{2, 3}}},
+		{"C", "SUB", []MultiplexRange{{0, 1}}},
+		{"D", "SUB", []MultiplexRange{{5, 9}}},
+		{"E", "MODE", []MultiplexRange{{7, 7}}},
+		{"F", "MODE", []MultiplexRange{{5, 6}, {10, 10}}},
+	}
+	for _, tt := range tests {
+		switchSignal, ranges, ok := c.Multiplexer(testSignal(t, c, "MUX", tt.signal))
+		var gotSwitch string
+		if ok {
+			gotSwitch = switchSignal.Name
+		}
+		if gotSwitch != tt.wantSwitch || len(ranges) != len(tt.wantRanges) {
+			t.Errorf("Multiplexer(%s) = %s %v, want %s %v", tt.signal, gotSwitch, ranges, tt.wantSwitch, tt.wantRanges)
+			continue
+		}
+		for i := range ranges {
+			if ranges[i] != tt.wantRanges[i] {
+				t.Errorf("Multiplexer(%s) = %s %v, want %s %v", tt.signal, gotSwitch, ranges, tt.wantSwitch, tt.wantRanges)
+				break
+			}
+		}
+	}
+
+	var messages []string
+	for _, d := range c.Diagnostics() {
+		messages = append(messages, d.Message)
+	}
+	want := []string{
+		"SG_MUL_VAL_ for undeclared signal NOPE in message 0x200",
+		"SG_MUL_VAL_ of signal A names B, which is no multiplexer switch of message 0x200",
+		"SG_MUL_VAL_ for signal MODE, which is not multiplexed",
+	}
+	for _, w := range want {
+		found := false
+		for _, m := range messages {
+			found = found || m == w
+		}
+		if !found {
+			t.Errorf("diagnostics %q do not include %q", messages, w)
+		}
+	}
+}
+
+func TestMultiplexTreeIsPresent(t *testing.T) {
+	c := multiplexTestCompiler()
+	tests := []struct {
+		name      string
+		mode, sub byte
+		length    uint8
+		want      []string
+	}{
+		{name: "default switch value", mode: 0, length: 8, want: []string{"MODE", "A"}},
+		{name: "second default switch value", mode: 1, length: 8, want: []string{"MODE", "B"}},
+		{name: "nested switch, first range", mode: 2, sub: 1, length: 8, want: []string{"MODE", "SUB", "C"}},
+		{name: "nested switch, second range", mode: 3, sub: 7, length: 8, want: []string{"MODE", "SUB", "D", "G"}},
+		{name: "nested switch value without signals", mode: 2, sub: 4, length: 8, want: []string{"MODE", "SUB"}},
+		{name: "override replaces the m value", mode: 4, length: 8, want: []string{"MODE"}},
+		{name: "override range", mode: 6, length: 8, want: []string{"MODE", "F"}},
+		{name: "override single value range", mode: 10, length: 8, want: []string{"MODE", "F"}},
+		{name: "default switch of a single value", mode: 7, length: 8, want: []string{"MODE", "E"}},
+		{name: "truncated nested switch", mode: 2, sub: 1, length: 1, want: []string{"MODE", "SUB"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &can.TimedFrame{Frame: can.Frame{ID: 0x200, Length: tt.length}}
+			f.Data[0], f.Data[1] = tt.mode, tt.sub
+			tree := newMultiplexTree(c, f, tt.length < 8)
+			m, _ := c.Message(0x200)
+			var got []string
+			for _, s := range m.Signals {
+				if tree.isPresent(s) {
+					got = append(got, s.Name)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(sortedLike(m, tt.want), ",") {
+				t.Errorf("present = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// sortedLike orders names as the signals of m.
+func sortedLike(m *descriptor.Message, names []string) []string {
+	var sorted []string
+	for _, s := range m.Signals {
+		for _, name := range names {
+			if s.Name == name {
+				sorted = append(sorted, name)
+			}
+		}
+	}
+	return sorted
+}
+
+func TestMultiplexTreeCycle(t *testing.T) {
+	c := multiplexTestCompiler()
+	f := &can.TimedFrame{Frame: can.Frame{ID: 0x300, Length: 8}}
+	f.Data[0] = 0x11 // X = Y = 1
+	tree := newMultiplexTree(c, f, false)
+	for _, name := range []string{"X", "Y"} {
+		if tree.isPresent(testSignal(t, c, "CYCLE", name)) {
+			t.Errorf("%s selected by a cycle of switches is present", name)
+		}
+	}
+
+	if _, err := NewEncoder(c).Encode("CYCLE", map[string]any{"X": 1}); err == nil {
+		t.Error("Encode() of a signal selected by a cycle of switches succeeded")
+	}
+}
+
+func TestMultiplexExclusive(t *testing.T) {
+	c := multiplexTestCompiler()
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"A", "B", true},
+		{"A", "A", false},
+		{"A", "C", true},    // C is nested under MODE 2-3
+		{"C", "D", true},    // same nested switch, disjoint ranges
+		{"C", "SUB", false}, // C is selected through SUB
+		{"SUB", "G", false}, // MODE 2-3 overlaps 3
+		{"D", "G", false},
+		{"E", "F", true},
+		{"F", "A", true},
+		{"B", "MODE", false},
+		{"MODE", "B", false},
+	}
+	for _, tt := range tests {
+		a, b := testSignal(t, c, "MUX", tt.a), testSignal(t, c, "MUX", tt.b)
+		if got := c.exclusive(a, b); got != tt.want {
+			t.Errorf("exclusive(%s, %s) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+		if got := c.exclusive(b, a); got != tt.want {
+			t.Errorf("exclusive(%s, %s) = %t, want %t", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestEncoderSelectsNestedSwitches(t *testing.T) {
+	c := multiplexTestCompiler()
+	e := NewEncoder(c)
+	f, err := e.Encode("MUX", map[string]any{"D": 0x42})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if f.Data[0] != 2 || f.Data[1] != 5 || f.Data[2] != 0x42 {
+		t.Errorf("Encode() = % X, want MODE=2, SUB=5, D=0x42", f.Payload())
+	}
+
+	if _, err := e.Encode("MUX", map[string]any{"MODE": 1, "C": 1}); err == nil {
+		t.Error("Encode() of C with MODE=1 succeeded")
+	}
+}
@@ -0,0 +1,381 @@
+package dbc
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"go.einride.tech/can/pkg/descriptor"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	candecodeproto "github.com/BIwashi/candecode/pkg/proto"
+)
+
+// Schema holds protobuf message types generated at runtime from a DBC file,
+// one message type per DBC message with one typed field per signal:
+//   - signals with value descriptions become enums
+//   - unscaled integer signals become int64 (raw value), or uint64 for unsigned 64 bit signals
+//   - every other signal becomes double (physical value)
+//
+// All signal fields have explicit presence, so multiplexed signals that are not part of a frame
+// and signals missing from short frames are left unset. Every message also has a timestamp field
+// (field number 1) and, numbered after the signals, a length_outcome field
+// (candecode.proto.v1.LengthOutcome), a validity field (candecode.proto.v1.Validity) and the
+// out_of_range and undefined_enum fields listing the names of the flagged signals of a frame.
+//
+// Each message type has a file of its own (candecode/dbc/<dbc-basename>/<message>.proto), so the
+// schema of one message does not carry the definitions of the whole DBC.
+type Schema struct {
+	messages map[*descriptor.Message]protoreflect.MessageDescriptor
+	fields   map[*descriptor.Signal]protoreflect.FieldDescriptor
+}
+
+type fieldKind int
+
+const (
+	fieldKindDouble fieldKind = iota
+	fieldKindInt64
+	fieldKindUint64
+	fieldKindEnum
+)
+
+// Schema returns the protobuf schema generated from the DBC file. It is built on first use.
+func (c *Compiler) Schema() (*Schema, error) {
+	if c.schema != nil {
+		return c.schema, nil
+	}
+	s, err := newSchema(c)
+	if err != nil {
+		return nil, err
+	}
+	c.schema = s
+	return s, nil
+}
+
+func newSchema(c *Compiler) (*Schema, error) {
+	var (
+		base     = filepath.Base(c.SourceFile())
+		baseName = protoIdent(strings.TrimSuffix(base, filepath.Ext(base)))
+		pkg      = "candecode.dbc." + baseName
+		topScope = make(nameScope)
+		sigNames = make(map[*descriptor.Signal]string)
+		s        = &Schema{
+			messages: make(map[*descriptor.Message]protoreflect.MessageDescriptor),
+			fields:   make(map[*descriptor.Signal]protoreflect.FieldDescriptor),
+		}
+	)
+
+	for _, m := range c.db.Messages {
+		var (
+			scope = make(nameScope)
+			mdp   = &descriptorpb.DescriptorProto{
+				Name: proto.String(topScope.unique(protoIdent(m.Name))),
+			}
+			fdp = &descriptorpb.FileDescriptorProto{
+				Name:    proto.String("candecode/dbc/" + baseName + "/" + mdp.GetName() + ".proto"),
+				Package: proto.String(pkg),
+				Dependency: []string{
+					timestamppb.File_google_protobuf_timestamp_proto.Path(),
+					candecodeproto.File_pkg_proto_dbc_proto.Path(),
+				},
+				Syntax: proto.String("proto3"),
+			}
+		)
+
+		mdp.Field = append(mdp.Field, &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(scope.unique("timestamp")),
+			Number:   proto.Int32(1),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+			TypeName: proto.String(".google.protobuf.Timestamp"),
+		})
+
+		lengthOutcome := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(scope.unique("length_outcome")),
+			Number:   proto.Int32(int32(len(m.Signals) + 2)),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum(),
+			TypeName: proto.String("." + string(candecodeproto.LengthOutcome(0).Descriptor().FullName())),
+		}
+		validity := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(scope.unique("validity")),
+			Number:   proto.Int32(int32(len(m.Signals) + 3)),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+			TypeName: proto.String("." + string((&candecodeproto.Validity{}).ProtoReflect().Descriptor().FullName())),
+		}
+
+		// field names are reserved first so they take precedence over generated enum names
+		for _, s := range m.Signals {
+			sigNames[s] = scope.unique(protoIdent(s.Name))
+		}
+		for i, s := range m.Signals {
+			var (
+				name  = sigNames[s]
+				field = &descriptorpb.FieldDescriptorProto{
+					Name:           proto.String(name),
+					Number:         proto.Int32(int32(i + 2)),
+					Label:          descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Proto3Optional: proto.Bool(true),
+					OneofIndex:     proto.Int32(int32(len(mdp.OneofDecl))),
+				}
+			)
+			mdp.OneofDecl = append(mdp.OneofDecl, &descriptorpb.OneofDescriptorProto{
+				Name: proto.String(scope.unique("_" + name)),
+			})
+
+			switch signalFieldKind(s) {
+			case fieldKindEnum:
+				enum := newEnum(scope, name, s)
+				mdp.EnumType = append(mdp.EnumType, enum)
+				field.Type = descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum()
+				field.TypeName = proto.String("." + fdp.GetPackage() + "." + mdp.GetName() + "." + enum.GetName())
+			case fieldKindInt64:
+				field.Type = descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum()
+			case fieldKindUint64:
+				field.Type = descriptorpb.FieldDescriptorProto_TYPE_UINT64.Enum()
+			default:
+				field.Type = descriptorpb.FieldDescriptorProto_TYPE_DOUBLE.Enum()
+			}
+			mdp.Field = append(mdp.Field, field)
+		}
+		outOfRange := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(scope.unique("out_of_range")),
+			Number: proto.Int32(int32(len(m.Signals) + 4)),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+			Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+		undefinedEnum := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(scope.unique("undefined_enum")),
+			Number: proto.Int32(int32(len(m.Signals) + 5)),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+			Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+		mdp.Field = append(mdp.Field, lengthOutcome, validity, outOfRange, undefinedEnum)
+		fdp.MessageType = append(fdp.MessageType, mdp)
+
+		file, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+		if err != nil {
+			return nil, errors.Wrapf(err, "build protobuf schema of message %s from dbc", m.Name)
+		}
+		md := file.Messages().Get(0)
+		s.messages[m] = md
+		for _, sig := range m.Signals {
+			s.fields[sig] = md.Fields().ByName(protoreflect.Name(sigNames[sig]))
+		}
+	}
+	return s, nil
+}
+
+// Message returns the generated message type of m.
+func (s *Schema) Message(m *descriptor.Message) (protoreflect.MessageDescriptor, bool) {
+	md, ok := s.messages[m]
+	return md, ok
+}
+
+// NewMessage builds a typed message of m from the decoded signals of one frame.
+// validity may be nil when the frame was not validated.
+func (s *Schema) NewMessage(
+	m *descriptor.Message,
+	ts time.Time,
+	outcome LengthOutcome,
+	validity *candecodeproto.Validity,
+	decoded map[string]DecodedSignal,
+) (*dynamicpb.Message, error) {
+	md, ok := s.messages[m]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("no schema for message: %s", m.Name))
+	}
+
+	msg := dynamicpb.NewMessage(md)
+	msg.Set(md.Fields().ByNumber(1), protoreflect.ValueOfMessage(timestamppb.New(ts).ProtoReflect()))
+	msg.Set(
+		md.Fields().ByNumber(protoreflect.FieldNumber(len(m.Signals)+2)),
+		protoreflect.ValueOfEnum(protoreflect.EnumNumber(outcome)), // LengthOutcome mirrors the proto enum
+	)
+	if validity != nil {
+		msg.Set(
+			md.Fields().ByNumber(protoreflect.FieldNumber(len(m.Signals)+3)),
+			protoreflect.ValueOfMessage(validity.ProtoReflect()),
+		)
+	}
+
+	var (
+		outOfRange    = msg.Mutable(md.Fields().ByNumber(protoreflect.FieldNumber(len(m.Signals) + 4))).List()
+		undefinedEnum = msg.Mutable(md.Fields().ByNumber(protoreflect.FieldNumber(len(m.Signals) + 5))).List()
+	)
+	for _, sig := range m.Signals {
+		d, ok := decoded[sig.Name]
+		if !ok || d.Missing {
+			continue // multiplexed signal not present in this frame, or past the end of a short frame
+		}
+		if d.OutOfRange {
+			outOfRange.Append(protoreflect.ValueOfString(sig.Name))
+		}
+		if d.UndefinedEnum {
+			undefinedEnum.Append(protoreflect.ValueOfString(sig.Name))
+		}
+		fd := s.fields[sig]
+		switch fd.Kind() {
+		case protoreflect.EnumKind:
+			msg.Set(fd, protoreflect.ValueOfEnum(protoreflect.EnumNumber(rawInt64(d.Raw))))
+		case protoreflect.Int64Kind:
+			msg.Set(fd, protoreflect.ValueOfInt64(rawInt64(d.Raw)))
+		case protoreflect.Uint64Kind:
+			raw, _ := d.Raw.(uint64)
+			msg.Set(fd, protoreflect.ValueOfUint64(raw))
+		default:
+			if d.Physical != nil {
+				msg.Set(fd, protoreflect.ValueOfFloat64(*d.Physical))
+				continue
+			}
+			msg.Set(fd, protoreflect.ValueOfFloat64(rawFloat64(d.Raw)))
+		}
+	}
+	return msg, nil
+}
+
+func signalFieldKind(s *descriptor.Signal) fieldKind {
+	if s.IsFloat {
+		return fieldKindDouble
+	}
+	// enum numbers are int32; wider signals keep their numeric value
+	fitsEnum := s.Length < 32 || (s.IsSigned && s.Length == 32)
+	if len(s.ValueDescriptions) > 0 && fitsEnum {
+		return fieldKindEnum
+	}
+	if s.Scale == 1 && s.Offset == 0 {
+		if !s.IsSigned && s.Length > 63 {
+			return fieldKindUint64 // would wrap to negative numbers as int64
+		}
+		return fieldKindInt64
+	}
+	return fieldKindDouble
+}
+
+// newEnum builds the enum type of a signal from its value descriptions.
+// Enum values share the message scope, so they are prefixed with the field name.
+// proto3 requires the first value to be zero; an UNSPECIFIED value is added when 0 is not described.
+func newEnum(scope nameScope, fieldName string, s *descriptor.Signal) *descriptorpb.EnumDescriptorProto {
+	var (
+		prefix = strings.ToUpper(fieldName) + "_"
+		enum   = &descriptorpb.EnumDescriptorProto{
+			Name: proto.String(scope.unique(fieldName + "Enum")),
+		}
+		values = make([]*descriptor.ValueDescription, 0, len(s.ValueDescriptions))
+		seen   = make(map[int64]bool)
+	)
+	for _, vd := range s.ValueDescriptions {
+		if seen[vd.Value] || vd.Value < math.MinInt32 || vd.Value > math.MaxInt32 {
+			continue // aliases would require allow_alias
+		}
+		seen[vd.Value] = true
+		values = append(values, vd)
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		// zero first, then ascending
+		if (values[i].Value == 0) != (values[j].Value == 0) {
+			return values[i].Value == 0
+		}
+		return values[i].Value < values[j].Value
+	})
+
+	if !seen[0] {
+		enum.Value = append(enum.Value, &descriptorpb.EnumValueDescriptorProto{
+			Name:   proto.String(scope.unique(prefix + "UNSPECIFIED")),
+			Number: proto.Int32(0),
+		})
+	}
+	for _, vd := range values {
+		enum.Value = append(enum.Value, &descriptorpb.EnumValueDescriptorProto{
+			Name:   proto.String(scope.unique(prefix + strings.ToUpper(protoIdent(vd.Description)))),
+			Number: proto.Int32(int32(vd.Value)),
+		})
+	}
+	return enum
+}
+
+// nameScope hands out unique protobuf identifiers within one scope. Names are compared without
+// case and underscores, which also rules out proto3 JSON name conflicts.
+type nameScope map[string]bool
+
+func (s nameScope) unique(name string) string {
+	n := name
+	for i := 2; s[scopeKey(n)]; i++ {
+		n = fmt.Sprintf("%s_%d", name, i)
+	}
+	s[scopeKey(n)] = true
+	return n
+}
+
+func scopeKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// protoIdent turns an arbitrary DBC name or value description into a protobuf identifier.
+func protoIdent(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z'):
+			b.WriteRune(r)
+		case '0' <= r && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+func rawInt64(raw any) int64 {
+	switch v := raw.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case int64:
+		return v
+	case uint64:
+		return int64(v)
+	case float64:
+		return int64(v)
+	default:
+		return 0
+	}
+}
+
+func rawFloat64(raw any) float64 {
+	switch v := raw.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return 0
+	}
+}
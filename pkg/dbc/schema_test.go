@@ -0,0 +1,155 @@
+package dbc
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.einride.tech/can/pkg/descriptor"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/BIwashi/candecode/pkg/can"
+)
+
+func schemaTestMessages() []*descriptor.Message {
+	return []*descriptor.Message{
+		{
+			Name: "ENGINE", ID: 0x100, Length: 8,
+			Signals: []*descriptor.Signal{{Name: "RPM", Length: 16, Scale: 0.25}},
+		},
+		{
+			Name: "BRAKE", ID: 0x200, Length: 8,
+			Signals: []*descriptor.Signal{{Name: "PRESSURE", Length: 8, Scale: 1}},
+		},
+	}
+}
+
+func TestSchemaOneFilePerMessage(t *testing.T) {
+	c := testCompiler(schemaTestMessages(), nil)
+	s, err := c.Schema()
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	for _, m := range c.db.Messages {
+		md, ok := s.Message(m)
+		if !ok {
+			t.Fatalf("no schema for %s", m.Name)
+		}
+		file := md.ParentFile()
+		if got, want := file.Path(), "candecode/dbc/test/"+m.Name+".proto"; got != want {
+			t.Errorf("file of %s = %s, want %s", m.Name, got, want)
+		}
+		if got, want := string(md.FullName()), "candecode.dbc.test."+m.Name; got != want {
+			t.Errorf("full name of %s = %s, want %s", m.Name, got, want)
+		}
+		if n := file.Messages().Len(); n != 1 {
+			t.Errorf("file of %s has %d messages, want 1", m.Name, n)
+		}
+	}
+}
+
+func TestSchemaSameBaseName(t *testing.T) {
+	a := testCompiler(schemaTestMessages(), nil)
+	a.db.SourceFile = "a/vehicle.dbc"
+	b := testCompiler(schemaTestMessages()[:1], nil)
+	b.db.SourceFile = "b/vehicle.dbc"
+	b.db.Messages[0].Signals = append(b.db.Messages[0].Signals, &descriptor.Signal{Name: "TORQUE", Start: 16, Length: 8})
+
+	sa, err := a.Schema()
+	if err != nil {
+		t.Fatalf("Schema() of a error = %v", err)
+	}
+	sb, err := b.Schema()
+	if err != nil {
+		t.Fatalf("Schema() of b error = %v", err)
+	}
+	mda, _ := sa.Message(a.db.Messages[0])
+	mdb, _ := sb.Message(b.db.Messages[0])
+	if mda.FullName() != mdb.FullName() {
+		t.Fatalf("full names differ: %s, %s", mda.FullName(), mdb.FullName())
+	}
+	if mda == mdb {
+		t.Fatal("messages of two DBC files share a descriptor")
+	}
+	if mdb.Fields().ByName("TORQUE") == nil || mda.Fields().ByName("TORQUE") != nil {
+		t.Error("TORQUE field is not only in the schema of b")
+	}
+}
+
+func TestSchemaNewMessage(t *testing.T) {
+	c := compileSource(t, `
+BO_ 256 ENGINE: 8 ECU
+ SG_ MODE : 0|2@1+ (1,0) [0|3] "" ECU
+ SG_ SEL M : 2|1@1+ (1,0) [0|1] "" ECU
+ SG_ TEMP : 8|8@1- (1,-40) [-168|87] "degC" ECU
+ SG_ COUNT : 16|16@1+ (1,0) [0|65535] "" ECU
+ SG_ SPEED : 32|16@1+ (0.01,0) [0|655.35] "km/h" ECU
+ SG_ X m1 : 48|8@1+ (1,0) [0|255] "" ECU
+ SG_ Y m0 : 56|8@1+ (1,0) [0|255] "" ECU
+
+BO_ 512 WIDE: 8 ECU
+ SG_ U64 : 0|64@1+ (1,0) [0|0] "" ECU
+ SG_ S64 : 0|64@1- (1,0) [0|0] "" ECU
+
+VAL_ 256 MODE 0 "Off" 1 "On" 2 "Auto" ;
+`)
+	schema, err := c.Schema()
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	decoder := NewDecoder(c)
+
+	ts := time.Unix(1700000000, 0)
+	tests := []struct {
+		id   uint32
+		data can.Data
+		want map[string]any // unset fields are nil
+	}{
+		{
+			id:   0x100,
+			data: can.Data{0x06, 0xF6, 0x39, 0x30, 0x10, 0x27, 0x2A, 0x00},
+			// MODE 2, SEL 1, TEMP -10 - 40, COUNT 12345, SPEED 10000 * 0.01, X 42
+			want: map[string]any{
+				"MODE": protoreflect.EnumNumber(2), "SEL": int64(1), "TEMP": -50.0, "COUNT": int64(12345),
+				"SPEED": 100.0, "X": int64(42), "Y": nil,
+			},
+		},
+		{
+			id:   0x200,
+			data: can.Data{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+			want: map[string]any{"U64": uint64(math.MaxUint64), "S64": int64(-1)},
+		},
+	}
+	for _, tt := range tests {
+		f := &can.TimedFrame{Frame: can.Frame{ID: tt.id, Length: 8, Data: tt.data}, Timestamp: ts}
+		decoded, err := decoder.Decode(f)
+		if err != nil {
+			t.Fatalf("Decode(0x%X) error = %v", tt.id, err)
+		}
+		m, _ := c.Message(tt.id)
+		msg, err := schema.NewMessage(m, ts, LengthMatch, nil, decoded)
+		if err != nil {
+			t.Fatalf("NewMessage(%s) error = %v", m.Name, err)
+		}
+		md := msg.Descriptor()
+		for name, want := range tt.want {
+			fd := md.Fields().ByName(protoreflect.Name(name))
+			if fd == nil {
+				t.Fatalf("%s has no field %s", m.Name, name)
+			}
+			if want == nil {
+				if msg.Has(fd) {
+					t.Errorf("%s.%s = %v, want unset", m.Name, name, msg.Get(fd))
+				}
+				continue
+			}
+			if got := msg.Get(fd).Interface(); !msg.Has(fd) || got != want {
+				t.Errorf("%s.%s = %v (%T), want %v (%T)", m.Name, name, got, got, want, want)
+			}
+		}
+		if got := msg.Get(md.Fields().ByNumber(1)).Message().Interface().(*timestamppb.Timestamp).AsTime(); !got.Equal(ts) {
+			t.Errorf("%s timestamp = %s, want %s", m.Name, got, ts)
+		}
+	}
+}
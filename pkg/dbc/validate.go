@@ -0,0 +1,223 @@
+package dbc
+
+import (
+	"sort"
+	"strings"
+
+	"go.einride.tech/can/pkg/descriptor"
+
+	"github.com/BIwashi/candecode/pkg/can"
+)
+
+// ChecksumStatus is the result of the checksum check of a frame.
+// Values match candecode.proto.v1.ChecksumStatus.
+type ChecksumStatus int
+
+const (
+	// ChecksumUnchecked means the message has no checksum signal or no algorithm applies to it.
+	ChecksumUnchecked ChecksumStatus = iota
+	// ChecksumValid means the checksum signal holds the computed checksum.
+	ChecksumValid
+	// ChecksumInvalid means the checksum signal differs from the computed checksum.
+	ChecksumInvalid
+)
+
+// CounterStatus is the result of the rolling counter check of a frame.
+// Values match candecode.proto.v1.CounterStatus.
+type CounterStatus int
+
+const (
+	// CounterUnchecked means the message has no counter signal.
+	CounterUnchecked CounterStatus = iota
+	// CounterOK means the counter advanced by one (or the frame is the first of its message).
+	CounterOK
+	// CounterSkip means counter values were skipped since the previous frame.
+	CounterSkip
+	// CounterRepeat means the counter did not advance since the previous frame.
+	CounterRepeat
+)
+
+// Validity is the result of the checksum and rolling counter checks of a frame.
+type Validity struct {
+	Checksum ChecksumStatus
+	Counter  CounterStatus
+	// Skipped is the number of counter values missing before the frame (CounterSkip).
+	Skipped uint64
+}
+
+// Valid reports whether neither check failed.
+func (v Validity) Valid() bool {
+	return v.Checksum != ChecksumInvalid && v.Counter != CounterSkip && v.Counter != CounterRepeat
+}
+
+// Validator checks the checksum and rolling counter signals of opendbc style messages.
+//
+// Checksum signals are named CHECKSUM (or end in CHECKSUM, CHKSUM or CRC), counter signals COUNTER
+// (or end in COUNTER or MSGCOUNT). The checksum algorithm is detected per DBC from its file name
+// (see DetectChecksum) unless one is forced with WithChecksum.
+// Counters are tracked per (bus, message), so frames must be validated in capture order.
+type Validator struct {
+	checksum Checksum
+	detected map[*Compiler]Checksum
+	counters map[counterKey]uint64
+	signals  map[*descriptor.Message]validatedSignals
+	noDetect bool
+}
+
+type ValidatorOption interface {
+	apply(*Validator)
+}
+
+type checksumOption struct {
+	checksum Checksum
+}
+
+func (o checksumOption) apply(v *Validator) {
+	v.checksum = o.checksum
+	v.noDetect = true
+}
+
+// WithChecksum validates the checksums of every DBC with c instead of the algorithm detected from
+// the DBC file name. A nil c disables checksum validation.
+func WithChecksum(c Checksum) ValidatorOption {
+	return checksumOption{checksum: c}
+}
+
+type counterKey struct {
+	bus     string
+	message *descriptor.Message
+}
+
+// validatedSignals are the checksum and counter signals of a message, nil when absent.
+type validatedSignals struct {
+	checksum *descriptor.Signal
+	counter  *descriptor.Signal
+}
+
+func NewValidator(opts ...ValidatorOption) *Validator {
+	v := &Validator{
+		detected: make(map[*Compiler]Checksum),
+		counters: make(map[counterKey]uint64),
+		signals:  make(map[*descriptor.Message]validatedSignals),
+	}
+	for _, o := range opts {
+		o.apply(v)
+	}
+	return v
+}
+
+// Validate checks frame f of message, defined in the DBC of compiler c.
+// Signals that do not fit in a short frame are not checked.
+func (v *Validator) Validate(c *Compiler, f *can.TimedFrame, message *descriptor.Message) Validity {
+	var (
+		validity Validity
+		sigs     = v.validatedSignals(message)
+		counter  uint64
+		hasCount bool
+	)
+
+	if sigs.counter != nil && signalFits(c, sigs.counter, f.Length) {
+		counter = unmarshalUnsigned(c, sigs.counter, &f.Data)
+		hasCount = true
+
+		key := counterKey{bus: f.Interface, message: message}
+		validity.Counter = CounterOK
+		if last, ok := v.counters[key]; ok {
+			modulus := uint64(1) << sigs.counter.Length
+			switch diff := (counter + modulus - last) % modulus; diff {
+			case 0:
+				validity.Counter = CounterRepeat
+			case 1:
+			default:
+				validity.Counter = CounterSkip
+				validity.Skipped = diff - 1
+			}
+		}
+		v.counters[key] = counter
+	}
+
+	if sigs.checksum != nil && signalFits(c, sigs.checksum, f.Length) {
+		if checksum := v.checksumOf(c); checksum != nil {
+			expected, ok := checksum.Compute(ChecksumFrame{
+				ID:            f.ID,
+				IsExtended:    f.IsExtended,
+				Data:          f.Payload(),
+				ChecksumBytes: signalBytes(c, sigs.checksum),
+				Counter:       counter,
+				HasCounter:    hasCount,
+			})
+			if ok {
+				mask := uint64(1)<<sigs.checksum.Length - 1
+				validity.Checksum = ChecksumValid
+				if unmarshalUnsigned(c, sigs.checksum, &f.Data) != expected&mask {
+					validity.Checksum = ChecksumInvalid
+				}
+			}
+		}
+	}
+
+	return validity
+}
+
+// checksumOf returns the checksum algorithm of the DBC of c, nil when none applies.
+func (v *Validator) checksumOf(c *Compiler) Checksum {
+	if v.noDetect {
+		return v.checksum
+	}
+	checksum, ok := v.detected[c]
+	if !ok {
+		checksum, _ = DetectChecksum(c.SourceFile())
+		v.detected[c] = checksum
+	}
+	return checksum
+}
+
+func (v *Validator) validatedSignals(m *descriptor.Message) validatedSignals {
+	sigs, ok := v.signals[m]
+	if !ok {
+		sigs = findValidatedSignals(m)
+		v.signals[m] = sigs
+	}
+	return sigs
+}
+
+// findValidatedSignals returns the checksum and counter signals of m. Multiplexed signals are not considered.
+func findValidatedSignals(m *descriptor.Message) validatedSignals {
+	var sigs validatedSignals
+	for _, s := range m.Signals {
+		if s.IsMultiplexed {
+			continue
+		}
+		name := strings.ToUpper(s.Name)
+		switch {
+		case sigs.checksum == nil && isChecksumSignal(name):
+			sigs.checksum = s
+		case sigs.counter == nil && isCounterSignal(name):
+			sigs.counter = s
+		}
+	}
+	return sigs
+}
+
+func isChecksumSignal(name string) bool {
+	return strings.HasSuffix(name, "CHECKSUM") || strings.HasSuffix(name, "CHKSUM") ||
+		name == "CRC" || strings.HasSuffix(name, "_CRC")
+}
+
+func isCounterSignal(name string) bool {
+	return strings.HasSuffix(name, "COUNTER") || strings.HasSuffix(name, "MSGCOUNT")
+}
+
+// signalBytes returns the ascending indexes of the payload bytes holding bits of s.
+func signalBytes(c *Compiler, s *descriptor.Signal) []int {
+	seen := make(map[int]bool)
+	var idx []int
+	for _, bit := range can.SignalBits(c.StartBit(s), uint16(s.Length), s.IsBigEndian) {
+		if i := int(bit / 8); !seen[i] {
+			seen[i] = true
+			idx = append(idx, i)
+		}
+	}
+	sort.Ints(idx)
+	return idx
+}
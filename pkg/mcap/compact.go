@@ -0,0 +1,124 @@
+package mcap
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+	"github.com/foxglove/mcap/go/mcap"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	candecodeproto "github.com/BIwashi/candecode/pkg/proto"
+)
+
+// messageDefinitionMetadataName is the name of the MCAP Metadata records holding MessageDefinition.
+const messageDefinitionMetadataName = "candecode.message_definition"
+
+// WriteSignalSample writes a compact, value-only record of one signal on the per-signal channel
+// /can/<Bus>/<MessageName>/<SignalName>.
+//
+// The static definition is not repeated per record: the signal definition is stored in the
+// channel metadata ("definition", protojson encoded Signal, and attr.<AttributeName> for its DBC
+// attributes) and def is written once as a Metadata record.
+func (w *Writer) WriteSignalSample(def *candecodeproto.MessageDefinition, signalName string, sample *candecodeproto.SignalSample) error {
+	if def == nil || sample == nil {
+		return errors.New("nil MessageDefinition or SignalSample")
+	}
+	if err := w.ensureMessageDefinition(def); err != nil {
+		return err
+	}
+
+	var (
+		hexID    = fmt.Sprintf("0x%X", def.GetCanId())
+		metadata = frameMetadata(def.GetBus(), def.GetInterfaceIndex(), hexID, def.GetName(), def.GetIsExtended())
+	)
+	metadata["signal"] = signalName
+	for _, sig := range def.GetSignals() {
+		if sig.GetName() != signalName {
+			continue
+		}
+		if sig.GetUnit() != "" {
+			metadata["unit"] = sig.GetUnit()
+		}
+		data, err := protojson.Marshal(sig)
+		if err != nil {
+			return errors.Wrap(err, "marshal signal definition")
+		}
+		metadata["definition"] = string(data)
+		addAttributes(metadata, "attr.", sig.GetAttributes())
+		break
+	}
+
+	channelID, err := w.ensureChannel(channelSpec{
+		key:      signalChannelKey(def.GetBus(), hexID, signalName),
+		schema:   sample.ProtoReflect().Descriptor(),
+		topic:    fmt.Sprintf("/can/%s/%s/%s", def.GetBus(), def.GetName(), signalName),
+		metadata: metadata,
+	})
+	if err != nil {
+		return errors.Wrap(err, "ensure channel")
+	}
+
+	return w.writeMessage(channelID, recordTime(sample.GetTimestamp()), sample)
+}
+
+// WriteMessageSample writes a compact record of one frame on the per-message channel
+// /can/<Bus>/<MessageName>. def is written once as a Metadata record.
+func (w *Writer) WriteMessageSample(def *candecodeproto.MessageDefinition, sample *candecodeproto.MessageSample) error {
+	if def == nil || sample == nil {
+		return errors.New("nil MessageDefinition or MessageSample")
+	}
+	if err := w.ensureMessageDefinition(def); err != nil {
+		return err
+	}
+
+	var (
+		hexID    = fmt.Sprintf("0x%X", def.GetCanId())
+		metadata = frameMetadata(def.GetBus(), def.GetInterfaceIndex(), hexID, def.GetName(), def.GetIsExtended())
+	)
+	addMessageAttributes(metadata, def)
+
+	channelID, err := w.ensureChannel(channelSpec{
+		key:      messageChannelKey(def.GetBus(), hexID),
+		schema:   sample.ProtoReflect().Descriptor(),
+		topic:    fmt.Sprintf("/can/%s/%s", def.GetBus(), def.GetName()),
+		metadata: metadata,
+	})
+	if err != nil {
+		return errors.Wrap(err, "ensure channel")
+	}
+
+	return w.writeMessage(channelID, recordTime(sample.GetTimestamp()), sample)
+}
+
+// ensureMessageDefinition writes def as a Metadata record on first use of its (bus, CAN ID).
+func (w *Writer) ensureMessageDefinition(def *candecodeproto.MessageDefinition) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var (
+		hexID = fmt.Sprintf("0x%X", def.GetCanId())
+		key   = messageChannelKey(def.GetBus(), hexID)
+	)
+	if w.definitions[key] {
+		return nil
+	}
+
+	data, err := protojson.Marshal(def)
+	if err != nil {
+		return errors.Wrap(err, "marshal MessageDefinition")
+	}
+	if err := w.writer.WriteMetadata(&mcap.Metadata{
+		Name: messageDefinitionMetadataName,
+		Metadata: map[string]string{
+			"bus":        def.GetBus(),
+			"can_id":     hexID,
+			"message":    def.GetName(),
+			"definition": string(data),
+		},
+	}); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("write metadata (message=%s)", def.GetName()))
+	}
+
+	w.definitions[key] = true
+	return nil
+}
@@ -0,0 +1,176 @@
+package mcap
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// timestampFullName is the well-known Timestamp type. It is written as {"sec", "nsec"},
+// the time representation Foxglove recognizes in JSON messages.
+const timestampFullName protoreflect.FullName = "google.protobuf.Timestamp"
+
+// jsonSchema builds the JSON Schema of the JSON records written for desc (see marshalJSON).
+// Field names are the protobuf field names.
+func jsonSchema(desc protoreflect.MessageDescriptor) ([]byte, error) {
+	schema := jsonSchemaMessage(desc, make(map[protoreflect.FullName]bool))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = string(desc.FullName())
+	return json.Marshal(schema)
+}
+
+func jsonSchemaMessage(desc protoreflect.MessageDescriptor, visiting map[protoreflect.FullName]bool) map[string]any {
+	if desc.FullName() == timestampFullName {
+		return map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"sec":  map[string]any{"type": "integer", "minimum": 0},
+				"nsec": map[string]any{"type": "integer", "minimum": 0},
+			},
+		}
+	}
+	if visiting[desc.FullName()] {
+		return map[string]any{"type": "object"} // recursive message, left open
+	}
+	visiting[desc.FullName()] = true
+	defer delete(visiting, desc.FullName())
+
+	properties := make(map[string]any)
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		var prop map[string]any
+		switch {
+		case fd.IsMap():
+			// JSON object keyed by the map key, see jsonMessage
+			prop = map[string]any{
+				"type":                 "object",
+				"additionalProperties": jsonSchemaField(fd.MapValue(), visiting),
+			}
+		case fd.IsList():
+			prop = map[string]any{"type": "array", "items": jsonSchemaField(fd, visiting)}
+		default:
+			prop = jsonSchemaField(fd, visiting)
+		}
+		properties[string(fd.Name())] = prop
+	}
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+func jsonSchemaField(fd protoreflect.FieldDescriptor, visiting map[protoreflect.FullName]bool) map[string]any {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.StringKind:
+		return map[string]any{"type": "string"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		// NaN and infinities are written as null
+		return map[string]any{"type": []string{"number", "null"}}
+	case protoreflect.EnumKind:
+		var (
+			values = fd.Enum().Values()
+			names  = make([]string, 0, values.Len())
+		)
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		// undeclared enum numbers are written as integers
+		return map[string]any{
+			"anyOf": []any{
+				map[string]any{"type": "string", "enum": names},
+				map[string]any{"type": "integer"},
+			},
+		}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return jsonSchemaMessage(fd.Message(), visiting)
+	default:
+		return map[string]any{"type": "integer"}
+	}
+}
+
+// marshalJSON encodes msg as JSON for MCAP json message encoding.
+//
+// Unlike protojson, 64-bit integers are written as JSON numbers, timestamps as {"sec", "nsec"}
+// and fields without presence are always written, so every record of a channel has the same keys.
+// Unset oneof members and optional fields are omitted.
+func marshalJSON(msg proto.Message) ([]byte, error) {
+	return json.Marshal(jsonMessage(msg.ProtoReflect()))
+}
+
+func jsonMessage(m protoreflect.Message) map[string]any {
+	desc := m.Descriptor()
+	if desc.FullName() == timestampFullName {
+		fields := desc.Fields()
+		return map[string]any{
+			"sec":  m.Get(fields.ByName("seconds")).Int(),
+			"nsec": m.Get(fields.ByName("nanos")).Int(),
+		}
+	}
+
+	obj := make(map[string]any)
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.HasPresence() && !m.Has(fd) {
+			continue
+		}
+		v := m.Get(fd)
+		if fd.IsMap() {
+			// JSON object keyed by the map key (e.g. attributes)
+			entries := make(map[string]any, v.Map().Len())
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				entries[k.String()] = jsonValue(fd.MapValue(), mv)
+				return true
+			})
+			obj[string(fd.Name())] = entries
+			continue
+		}
+		if fd.IsList() {
+			list := v.List()
+			items := make([]any, 0, list.Len())
+			for j := 0; j < list.Len(); j++ {
+				items = append(items, jsonValue(fd, list.Get(j)))
+			}
+			obj[string(fd.Name())] = items
+			continue
+		}
+		obj[string(fd.Name())] = jsonValue(fd, v)
+	}
+	return obj
+}
+
+func jsonValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return v.Bool()
+	case protoreflect.StringKind:
+		return v.String()
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil
+		}
+		return f
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return jsonMessage(v.Message())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return v.Uint()
+	default:
+		return v.Int()
+	}
+}
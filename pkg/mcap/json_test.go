@@ -0,0 +1,289 @@
+package mcap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	candecodeproto "github.com/BIwashi/candecode/pkg/proto"
+)
+
+func TestMarshalJSONMap(t *testing.T) {
+	ds := &candecodeproto.DecodedSignal{
+		Name: "SPEED",
+		Signal: &candecodeproto.Signal{
+			Name: "SPEED",
+			Attributes: map[string]string{
+				"GenSigStartValue": "0",
+				"SPN":              "84",
+			},
+		},
+	}
+
+	data, err := marshalJSON(ds)
+	if err != nil {
+		t.Fatalf("marshalJSON: %v", err)
+	}
+	var got struct {
+		Signal struct {
+			Attributes map[string]string `json:"attributes"`
+		} `json:"signal"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal %s: %v", data, err)
+	}
+	if !reflect.DeepEqual(got.Signal.Attributes, ds.GetSignal().GetAttributes()) {
+		t.Errorf("attributes = %v, want %v", got.Signal.Attributes, ds.GetSignal().GetAttributes())
+	}
+
+	// an empty map is written as an empty object
+	data, err = marshalJSON(&candecodeproto.Signal{Name: "SPEED"})
+	if err != nil {
+		t.Fatalf("marshalJSON: %v", err)
+	}
+	var empty map[string]any
+	if err := json.Unmarshal(data, &empty); err != nil {
+		t.Fatalf("unmarshal %s: %v", data, err)
+	}
+	if attrs, ok := empty["attributes"].(map[string]any); !ok || len(attrs) != 0 {
+		t.Errorf("attributes = %#v, want empty object", empty["attributes"])
+	}
+}
+
+func TestMarshalJSONSchema(t *testing.T) {
+	var (
+		ts  = timestamppb.New(time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC))
+		sig = &candecodeproto.Signal{
+			Name:              "GEAR",
+			Length:            4,
+			Scale:             1,
+			ValueDescriptions: []*candecodeproto.ValueDescription{{Value: 0, Description: "P"}, {Value: -1, Description: "ERR"}},
+			ReceiverNodes:     []string{"ECU1", "ECU2"},
+			Attributes:        map[string]string{"GenSigStartValue": "0"},
+		}
+		validity = &candecodeproto.Validity{
+			Checksum: candecodeproto.ChecksumStatus_CHECKSUM_STATUS_VALID,
+			Counter:  candecodeproto.CounterStatus_COUNTER_STATUS_SKIP,
+			Skipped:  math.MaxUint64,
+		}
+	)
+
+	tests := []struct {
+		name string
+		msg  proto.Message
+	}{
+		{
+			name: "signal",
+			msg: &candecodeproto.DecodedSignal{
+				MessageName:   "TRANSMISSION",
+				Name:          "GEAR",
+				Raw:           &candecodeproto.DecodedSignal_RawS{RawS: -1},
+				Physical:      proto.Float64(-1),
+				Description:   "ERR",
+				Signal:        sig,
+				Timestamp:     ts,
+				CanId:         0x1FFFFFFF,
+				IsExtended:    true,
+				FrameBytes:    []byte{0x0F},
+				Bus:           "can0",
+				LengthOutcome: candecodeproto.LengthOutcome_LENGTH_OUTCOME_PADDED,
+				Validity:      validity,
+				Direction:     candecodeproto.Direction_DIRECTION_RX,
+			},
+		},
+		{
+			name: "signal raw bytes, NaN physical and undeclared enum",
+			msg: &candecodeproto.DecodedSignal{
+				Name:      "VIN",
+				Raw:       &candecodeproto.DecodedSignal_RawBytes{RawBytes: []byte("WDB")},
+				Physical:  proto.Float64(math.NaN()),
+				Timestamp: ts,
+				Direction: candecodeproto.Direction(7),
+			},
+		},
+		{
+			name: "message",
+			msg: &candecodeproto.DecodedMessage{
+				Name:      "TRANSMISSION",
+				Timestamp: ts,
+				CanId:     0x100,
+				Bus:       "can0",
+				Signals: []*candecodeproto.SignalValue{
+					{Name: "GEAR", Raw: &candecodeproto.SignalValue_RawU{RawU: math.MaxUint64}, Physical: proto.Float64(3), Unit: "-"},
+					{Name: "TORQUE", Raw: &candecodeproto.SignalValue_RawF{RawF: 12.5}, Physical: proto.Float64(12.5)},
+					{Name: "LOCKED", Raw: &candecodeproto.SignalValue_RawB{RawB: true}},
+					{Name: "TAIL", Missing: true},
+				},
+			},
+		},
+		{
+			name: "compact signal",
+			msg: &candecodeproto.SignalSample{
+				Timestamp:     ts,
+				Raw:           &candecodeproto.SignalSample_RawU{RawU: 3},
+				Physical:      proto.Float64(3),
+				LengthOutcome: candecodeproto.LengthOutcome_LENGTH_OUTCOME_TRUNCATED,
+				Validity:      validity,
+				UndefinedEnum: true,
+				OutOfRange:    true,
+			},
+		},
+		{
+			name: "compact message",
+			msg: &candecodeproto.MessageSample{
+				Timestamp: ts,
+				Signals:   []*candecodeproto.SignalValue{{Name: "GEAR", Raw: &candecodeproto.SignalValue_RawS{RawS: -2}}},
+			},
+		},
+		{
+			name: "message definition",
+			msg: &candecodeproto.MessageDefinition{
+				Name:       "TRANSMISSION",
+				CanId:      0x100,
+				Length:     8,
+				Bus:        "can0",
+				Signals:    []*candecodeproto.Signal{sig, {Name: "TORQUE"}},
+				Attributes: map[string]string{"GenMsgCycleTime": "100", "GenMsgSendType": "Cyclic"},
+			},
+		},
+		{
+			name: "raw",
+			msg: &candecodeproto.RawFrame{
+				Timestamp: ts,
+				CanId:     0x7FF,
+				IsRemote:  true,
+				IsFd:      true,
+				Brs:       true,
+				Dlc:       15,
+				Data:      make([]byte, 64),
+				Bus:       "can1",
+			},
+		},
+		{
+			name: "timing",
+			msg: &candecodeproto.TimingViolation{
+				Timestamp:   ts,
+				Kind:        candecodeproto.TimingViolationKind_TIMING_VIOLATION_KIND_JITTER,
+				Bus:         "can0",
+				CanId:       0x100,
+				Message:     "TRANSMISSION",
+				CycleTimeMs: 100,
+				GapMs:       math.Inf(1),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemaData, err := jsonSchema(tt.msg.ProtoReflect().Descriptor())
+			if err != nil {
+				t.Fatalf("jsonSchema: %v", err)
+			}
+			var schema map[string]any
+			if err := json.Unmarshal(schemaData, &schema); err != nil {
+				t.Fatalf("unmarshal schema: %v", err)
+			}
+
+			data, err := marshalJSON(tt.msg)
+			if err != nil {
+				t.Fatalf("marshalJSON: %v", err)
+			}
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.UseNumber()
+			var record any
+			if err := dec.Decode(&record); err != nil {
+				t.Fatalf("unmarshal record %s: %v", data, err)
+			}
+			for _, problem := range validateJSON("$", schema, record) {
+				t.Errorf("%s\nrecord: %s", problem, data)
+			}
+		})
+	}
+}
+
+// validateJSON checks v against the subset of JSON Schema written by jsonSchema and returns the
+// violations. Object keys must be declared in properties or match additionalProperties.
+func validateJSON(path string, schema map[string]any, v any) []string {
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		for _, alt := range anyOf {
+			if len(validateJSON(path, alt.(map[string]any), v)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: %v matches no anyOf alternative", path, v)}
+	}
+
+	var types []string
+	switch typ := schema["type"].(type) {
+	case string:
+		types = []string{typ}
+	case []any:
+		for _, t := range typ {
+			types = append(types, t.(string))
+		}
+	}
+	if !slices.Contains(types, jsonType(v)) && !(jsonType(v) == "integer" && slices.Contains(types, "number")) {
+		return []string{fmt.Sprintf("%s: %s %v, want %v", path, jsonType(v), v, types)}
+	}
+
+	var problems []string
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, v) {
+		problems = append(problems, fmt.Sprintf("%s: %v not in enum %v", path, v, enum))
+	}
+	if minimum, ok := schema["minimum"].(float64); ok {
+		if n, err := v.(json.Number).Float64(); err != nil || n < minimum {
+			problems = append(problems, fmt.Sprintf("%s: %v below minimum %v", path, v, minimum))
+		}
+	}
+	switch v := v.(type) {
+	case []any:
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range v {
+			problems = append(problems, validateJSON(fmt.Sprintf("%s[%d]", path, i), items, item)...)
+		}
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		additional, _ := schema["additionalProperties"].(map[string]any)
+		for key, value := range v {
+			prop, ok := properties[key].(map[string]any)
+			if !ok {
+				prop = additional
+			}
+			if prop == nil {
+				problems = append(problems, fmt.Sprintf("%s.%s: undeclared property", path, key))
+				continue
+			}
+			problems = append(problems, validateJSON(path+"."+key, prop, value)...)
+		}
+	}
+	return problems
+}
+
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
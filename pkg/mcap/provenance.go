@@ -0,0 +1,122 @@
+package mcap
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/foxglove/mcap/go/mcap"
+)
+
+const (
+	// ProvenanceMetadataName is the name of the MCAP Metadata record describing how the file was produced.
+	ProvenanceMetadataName = "candecode.provenance"
+	// DBCMediaType is the media type of DBC file attachments.
+	DBCMediaType = "text/x-dbc"
+)
+
+// Provenance describes the inputs and settings of a conversion.
+type Provenance struct {
+	// Version is the candecode version.
+	Version string
+	// Args is the command line, without the program name.
+	Args []string
+	// ConvertedAt is the time of the conversion. It is left out of the record when zero,
+	// which keeps the output reproducible.
+	ConvertedAt time.Time
+	// InputFile is the path of the source capture, InputFormat its format (pcapng, candump) and
+	// InputSHA256 its hex encoded SHA-256.
+	InputFile   string
+	InputFormat string
+	InputSHA256 string
+	// DBCs are the DBC files used for decoding.
+	DBCs []DBCFile
+}
+
+// DBCFile is a DBC file used for decoding.
+type DBCFile struct {
+	Path string
+	// Version is the VERSION string of the DBC file.
+	Version string
+	// Buses are the buses decoded with this file; empty for the default DBC.
+	Buses []string
+	// Data is the file content.
+	Data []byte
+	// Attributes are the network attributes of the DBC file (e.g. BusType, DBName), defaults included.
+	Attributes map[string]string
+}
+
+// WriteProvenance embeds every DBC file as an Attachment (text/x-dbc) and writes a
+// candecode.provenance Metadata record with:
+//   - candecode_version, converted_at (RFC 3339, unless zero), command_line (JSON array)
+//   - input_file, input_format, input_sha256
+//   - dbc.<n>.attachment, dbc.<n>.path, dbc.<n>.sha256, dbc.<n>.version and dbc.<n>.buses
+//     ("default" or a comma separated list) for every DBC file
+//   - dbc.<n>.attr.<AttributeName> for the network attributes of every DBC file
+func (w *Writer) WriteProvenance(p Provenance) error {
+	args, err := json.Marshal(p.Args)
+	if err != nil {
+		return errors.Wrap(err, "marshal command line")
+	}
+
+	metadata := map[string]string{
+		"candecode_version": p.Version,
+		"command_line":      string(args),
+		"input_file":        filepath.Base(p.InputFile),
+		"input_format":      p.InputFormat,
+		"input_sha256":      p.InputSHA256,
+	}
+	var createTime uint64
+	if !p.ConvertedAt.IsZero() {
+		metadata["converted_at"] = p.ConvertedAt.UTC().Format(time.RFC3339Nano)
+		createTime = uint64(p.ConvertedAt.UnixNano())
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, dbcFile := range p.DBCs {
+		var (
+			name   = filepath.Base(dbcFile.Path)
+			sum    = sha256.Sum256(dbcFile.Data)
+			prefix = fmt.Sprintf("dbc.%d.", i)
+			buses  = "default"
+		)
+		if len(dbcFile.Buses) > 0 {
+			buses = strings.Join(dbcFile.Buses, ",")
+		}
+		metadata[prefix+"attachment"] = name
+		metadata[prefix+"path"] = dbcFile.Path
+		metadata[prefix+"sha256"] = hex.EncodeToString(sum[:])
+		metadata[prefix+"version"] = dbcFile.Version
+		metadata[prefix+"buses"] = buses
+		for attr, value := range dbcFile.Attributes {
+			metadata[prefix+"attr."+attr] = value
+		}
+
+		if err := w.writer.WriteAttachment(&mcap.Attachment{
+			LogTime:    createTime,
+			CreateTime: createTime,
+			Name:       name,
+			MediaType:  DBCMediaType,
+			DataSize:   uint64(len(dbcFile.Data)),
+			Data:       bytes.NewReader(dbcFile.Data),
+		}); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("write attachment (name=%s)", name))
+		}
+	}
+
+	if err := w.writer.WriteMetadata(&mcap.Metadata{
+		Name:     ProvenanceMetadataName,
+		Metadata: metadata,
+	}); err != nil {
+		return errors.Wrap(err, "write provenance metadata")
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package mcap
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"google.golang.org/protobuf/proto"
+
+	candecodeproto "github.com/BIwashi/candecode/pkg/proto"
+)
+
+// typedChannelKey builds the internal key of a per-message channel with a typed schema.
+func typedChannelKey(bus, hexID string) string {
+	return "typed:" + bus + ":" + hexID
+}
+
+// WriteTypedMessage writes msg, a message of the typed schema generated from the DBC
+// (see dbc.Schema), on the per-message channel /can/<Bus>/<MessageName>.
+//
+// The schema is registered under the generated message name, so plot paths use the real
+// signal names. Units are stored in the channel metadata as unit.<SignalName>, DBC attributes
+// as described at addMessageAttributes, and def is written once as a Metadata record.
+func (w *Writer) WriteTypedMessage(def *candecodeproto.MessageDefinition, ts time.Time, msg proto.Message) error {
+	if def == nil || msg == nil {
+		return errors.New("nil MessageDefinition or typed message")
+	}
+	if err := w.ensureMessageDefinition(def); err != nil {
+		return err
+	}
+
+	var (
+		hexID    = fmt.Sprintf("0x%X", def.GetCanId())
+		metadata = frameMetadata(def.GetBus(), def.GetInterfaceIndex(), hexID, def.GetName(), def.GetIsExtended())
+	)
+	for _, sig := range def.GetSignals() {
+		if sig.GetUnit() != "" {
+			metadata["unit."+sig.GetName()] = sig.GetUnit()
+		}
+	}
+	addMessageAttributes(metadata, def)
+
+	channelID, err := w.ensureChannel(channelSpec{
+		key:      typedChannelKey(def.GetBus(), hexID),
+		schema:   msg.ProtoReflect().Descriptor(),
+		topic:    fmt.Sprintf("/can/%s/%s", def.GetBus(), def.GetName()),
+		metadata: metadata,
+	})
+	if err != nil {
+		return errors.Wrap(err, "ensure channel")
+	}
+
+	return w.writeMessage(channelID, ts, msg)
+}
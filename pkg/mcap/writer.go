@@ -10,28 +10,91 @@ import (
 	"github.com/foxglove/mcap/go/mcap"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/descriptorpb"
-	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	candecodeproto "github.com/BIwashi/candecode/pkg/proto"
 )
 
-// Writer writes DecodedSignal proto messages into an MCAP file.
+// deterministic marshals protobuf messages with a stable byte layout.
+var deterministic = proto.MarshalOptions{Deterministic: true}
+
+// Writer writes DecodedSignal / DecodedMessage proto messages into an MCAP file.
 //
 // Design decisions:
-//   - Single protobuf schema (candecode.proto.v1.DecodedSignal) reused by all channels.
-//   - Channel granularity = (CAN message, Signal) i.e. one signal per channel/topic.
-//   - Topic naming: /can/<MessageName>/<SignalName>
-//   - Channel metadata includes: can_id (hex), message (dbc BO_ name), signal, unit (if any), is_extended.
+//   - Protobuf schemas (candecode.proto.v1.DecodedSignal, candecode.proto.v1.DecodedMessage) are
+//     registered lazily on first use and shared by all channels of the same record type.
+//   - Signal channels: one channel per (bus, CAN message, Signal), topic /can/<Bus>/<MessageName>/<SignalName>.
+//   - Message channels: one channel per (bus, CAN message), topic /can/<Bus>/<MessageName>; each record
+//     holds every decoded signal of a frame.
+//   - Channel metadata includes: bus, interface_index, can_id (hex), message (dbc BO_ name),
+//     is_extended, plus signal and unit (if any) for signal channels.
+//   - Raw channels: one channel per bus, topic /can/<Bus>/raw, holding every captured frame as RawFrame.
+//   - Cycle time violations are written as TimingViolation on a single /diagnostics/timing channel.
+//   - Compact records (SignalSample, MessageSample) carry values only; the static definitions are
+//     written once per message as a candecode.message_definition Metadata record (see compact.go).
+//   - Typed records use a schema generated from the DBC per message, with one field per signal
+//     (see typed.go).
+//   - Records are protobuf encoded by default; WithEncoding(EncodingJSON) registers JSON Schemas
+//     derived from the same protobuf descriptors and writes JSON records on the same topics (see json.go).
+//
+// A new channel is created lazily on first occurrence of its key,
+// so the same CAN ID seen on two buses never shares a channel.
 //
-// A new channel is created lazily on first occurrence of a (can_id, signal_name) combination.
+// Output is reproducible: schema and channel IDs are allocated in order of first use, records are
+// marshaled deterministically and publish times default to the capture time (see WithPublishTime).
+// Callers must write records in a stable order.
 type Writer struct {
-	mu         sync.Mutex
-	writer     *mcap.Writer
-	schemaID   uint16
-	nextChanID uint16
-	channels   map[string]uint16 // key: canID_hex + ":" + signalName
-	channelSqc map[uint16]uint32 // key: channelID, value: sequence number
+	mu           sync.Mutex
+	writer       *mcap.Writer
+	nextSchemaID uint16
+	schemas      map[protoreflect.MessageDescriptor]uint16 // key: message type
+	nextChanID   uint16
+	channels     map[string]uint16 // key: see signalChannelKey / messageChannelKey
+	channelSqc   map[uint16]uint32 // key: channelID, value: sequence number
+	definitions  map[string]bool   // key: messageChannelKey; MessageDefinition metadata already written
+	encoding     Encoding
+	publishTime  PublishTimeSource
+}
+
+// Encoding is the MCAP message encoding of the written records.
+type Encoding string
+
+const (
+	EncodingProtobuf Encoding = "protobuf"
+	EncodingJSON     Encoding = "json"
+)
+
+// PublishTimeSource selects the MCAP publish time of records.
+type PublishTimeSource string
+
+const (
+	// PublishTimeCapture uses the capture time of the record (same as its log time),
+	// so converting the same input twice produces identical output.
+	PublishTimeCapture PublishTimeSource = "capture"
+	// PublishTimeWallClock uses the time the record is written.
+	PublishTimeWallClock PublishTimeSource = "wallclock"
+)
+
+// ParsePublishTime parses a publish time source as accepted on the command line: capture or wallclock.
+func ParsePublishTime(s string) (PublishTimeSource, error) {
+	switch PublishTimeSource(s) {
+	case PublishTimeCapture, PublishTimeWallClock:
+		return PublishTimeSource(s), nil
+	default:
+		return "", errors.Newf("unsupported publish time source: %s (available: capture, wallclock)", s)
+	}
+}
+
+// ParseEncoding parses an encoding name as accepted on the command line: protobuf or json.
+func ParseEncoding(s string) (Encoding, error) {
+	switch Encoding(s) {
+	case EncodingProtobuf, EncodingJSON:
+		return Encoding(s), nil
+	default:
+		return "", errors.Newf("unsupported encoding: %s (available: protobuf, json)", s)
+	}
 }
 
 type WriterOption interface {
@@ -42,15 +105,90 @@ type writerOptions struct {
 	chunked     bool
 	compression mcap.CompressionFormat
 	chunkSize   int64
+	encoding    Encoding
+	publishTime PublishTimeSource
+}
+
+type compressionOption mcap.CompressionFormat
+
+func (o compressionOption) apply(opts *writerOptions) {
+	opts.compression = mcap.CompressionFormat(o)
+}
+
+// WithCompression sets the chunk compression format (zstd by default).
+func WithCompression(compression mcap.CompressionFormat) WriterOption {
+	return compressionOption(compression)
+}
+
+type chunkSizeOption int64
+
+func (o chunkSizeOption) apply(opts *writerOptions) {
+	opts.chunkSize = int64(o)
+}
+
+// WithChunkSize sets the target size of uncompressed chunks in bytes (100MB by default).
+// Small chunks let streaming readers such as Foxglove start showing data sooner.
+func WithChunkSize(size int64) WriterOption {
+	return chunkSizeOption(size)
+}
+
+type unchunkedOption struct{}
+
+func (unchunkedOption) apply(opts *writerOptions) {
+	opts.chunked = false
+}
+
+// WithUnchunked writes records directly to the file without chunks (and without compression).
+func WithUnchunked() WriterOption {
+	return unchunkedOption{}
+}
+
+type encodingOption Encoding
+
+func (o encodingOption) apply(opts *writerOptions) {
+	opts.encoding = Encoding(o)
+}
+
+// WithEncoding sets the message encoding of all records (protobuf by default).
+func WithEncoding(encoding Encoding) WriterOption {
+	return encodingOption(encoding)
+}
+
+type publishTimeOption PublishTimeSource
+
+func (o publishTimeOption) apply(opts *writerOptions) {
+	opts.publishTime = PublishTimeSource(o)
+}
+
+// WithPublishTime sets the source of record publish times (capture time by default).
+func WithPublishTime(source PublishTimeSource) WriterOption {
+	return publishTimeOption(source)
 }
 
-// NewWriter initializes an MCAP writer with the DecodedSignal schema registered.
+// ParseCompression parses a compression name as accepted on the command line: zstd, lz4 or none.
+func ParseCompression(s string) (mcap.CompressionFormat, error) {
+	switch s {
+	case "zstd":
+		return mcap.CompressionZSTD, nil
+	case "lz4":
+		return mcap.CompressionLZ4, nil
+	case "none", "":
+		return mcap.CompressionNone, nil
+	default:
+		return "", errors.Newf("unsupported compression: %s (available: zstd, lz4, none)", s)
+	}
+}
+
+// NewWriter initializes an MCAP writer and writes the header. Schemas and channels are
+// registered on first use.
 // The provided io.Writer should be an opened file (will not be closed here).
 func NewWriter(out io.Writer, opts ...WriterOption) (*Writer, error) {
 	opt := &writerOptions{
 		chunked:     true,
 		chunkSize:   100 * 1024 * 1024, // 100MB chunks
 		compression: mcap.CompressionZSTD,
+		encoding:    EncodingProtobuf,
+		publishTime: PublishTimeCapture,
 	}
 	for _, o := range opts {
 		o.apply(opt)
@@ -72,121 +210,215 @@ func NewWriter(out io.Writer, opts ...WriterOption) (*Writer, error) {
 		return nil, errors.Wrap(err, "write header")
 	}
 
+	return &Writer{
+		writer:       w,
+		nextSchemaID: 1, // first schema will get ID=1
+		schemas:      make(map[protoreflect.MessageDescriptor]uint16),
+		nextChanID:   1, // first channel will get ID=1
+		channels:     make(map[string]uint16),
+		channelSqc:   make(map[uint16]uint32),
+		definitions:  make(map[string]bool),
+		encoding:     opt.encoding,
+		publishTime:  opt.publishTime,
+	}, nil
+}
+
+// channelSpec describes a channel to be created on first use.
+type channelSpec struct {
+	key      string
+	schema   protoreflect.MessageDescriptor
+	topic    string
+	metadata map[string]string
+}
+
+// signalChannelKey builds the internal key of a per-signal channel.
+func signalChannelKey(bus, hexID, signalName string) string {
+	return "signal:" + bus + ":" + hexID + ":" + signalName
+}
+
+// messageChannelKey builds the internal key of a per-message channel.
+func messageChannelKey(bus, hexID string) string {
+	return "message:" + bus + ":" + hexID
+}
+
+// rawChannelKey builds the internal key of a per-bus raw frame channel.
+func rawChannelKey(bus string) string {
+	return "raw:" + bus
+}
+
+// timingChannelKey is the internal key of the timing diagnostics channel.
+const timingChannelKey = "diagnostics:timing"
+
+// timingTopic is the topic of cycle time violations.
+const timingTopic = "/diagnostics/timing"
+
+// frameMetadata returns the channel metadata shared by signal and message channels.
+func frameMetadata(bus string, ifaceIndex uint32, hexID, messageName string, isExtended bool) map[string]string {
+	return map[string]string{
+		"bus":             bus,
+		"interface_index": fmt.Sprintf("%d", ifaceIndex),
+		"can_id":          hexID,
+		"message":         messageName,
+		"is_extended":     fmt.Sprintf("%t", isExtended),
+	}
+}
+
+// addAttributes adds DBC attribute values to channel metadata as <prefix><AttributeName>.
+func addAttributes(metadata map[string]string, prefix string, attributes map[string]string) {
+	for name, value := range attributes {
+		metadata[prefix+name] = value
+	}
+}
+
+// addMessageAttributes adds the DBC attributes of def to the metadata of a per-message channel:
+// attr.<AttributeName> for message attributes and attr.<SignalName>.<AttributeName> for signal attributes.
+func addMessageAttributes(metadata map[string]string, def *candecodeproto.MessageDefinition) {
+	addAttributes(metadata, "attr.", def.GetAttributes())
+	for _, sig := range def.GetSignals() {
+		addAttributes(metadata, "attr."+sig.GetName()+".", sig.GetAttributes())
+	}
+}
+
+// ensureSchema registers the schema of desc once; returns schema ID.
+// Schemas are keyed by message type, not by name: typed schemas of two DBC files with the same
+// base name share their full names but not their fields.
+// Caller must hold w.mu.
+func (w *Writer) ensureSchema(desc protoreflect.MessageDescriptor) (uint16, error) {
+	name := string(desc.FullName())
+	if id, ok := w.schemas[desc]; ok {
+		return id, nil
+	}
+
 	var (
+		schemaEncoding string
+		data           []byte
+		err            error
+	)
+	switch w.encoding {
+	case EncodingJSON:
+		// JSON Schema of the records written by marshalJSON
+		schemaEncoding = "jsonschema"
+		data, err = jsonSchema(desc)
+		if err != nil {
+			return 0, errors.Wrap(err, "marshal JSON Schema")
+		}
+	default:
 		// Prepare schema descriptor bytes as FileDescriptorSet (include dependencies).
-		fdMain      = protodesc.ToFileDescriptorProto(candecodeproto.File_pkg_proto_dbc_proto)
-		fdTimestamp = protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto)
-		fdSet       = &descriptorpb.FileDescriptorSet{
-			File: []*descriptorpb.FileDescriptorProto{
-				fdMain,
-				fdTimestamp,
-			},
+		schemaEncoding = "protobuf"
+		data, err = deterministic.Marshal(fileDescriptorSet(desc.ParentFile()))
+		if err != nil {
+			return 0, errors.Wrap(err, "marshal FileDescriptorSet")
 		}
-	)
-
-	data, err := proto.Marshal(fdSet)
-	if err != nil {
-		return nil, errors.Wrap(err, "marshal FileDescriptorSet")
 	}
 
-	// Set mcap schema (protobuf encoded FileDescriptorSet)
-	schemaID := uint16(1)
-	if err := w.WriteSchema(&mcap.Schema{
+	schemaID := w.nextSchemaID
+	if err := w.writer.WriteSchema(&mcap.Schema{
 		ID:       schemaID,
-		Name:     "candecode.proto.v1.DecodedSignal",
-		Encoding: "protobuf",
+		Name:     name,
+		Encoding: schemaEncoding,
 		Data:     data,
 	}); err != nil {
-		return nil, errors.Wrap(err, "write schema")
+		return 0, errors.Wrap(err, fmt.Sprintf("write schema (name=%s)", name))
 	}
+	w.nextSchemaID++
 
-	return &Writer{
-		writer:     w,
-		schemaID:   schemaID,
-		nextChanID: 1, // first channel will get ID=1
-		channels:   make(map[string]uint16),
-		channelSqc: make(map[uint16]uint32),
-	}, nil
+	w.schemas[desc] = schemaID
+	return schemaID, nil
 }
 
-// channelKey builds internal key.
-func channelKey(hexID, signalName string) string {
-	return hexID + ":" + signalName
+// fileDescriptorSet collects fd and its transitive imports, dependencies first.
+func fileDescriptorSet(fd protoreflect.FileDescriptor) *descriptorpb.FileDescriptorSet {
+	var (
+		fdSet = &descriptorpb.FileDescriptorSet{}
+		seen  = make(map[string]bool)
+		visit func(protoreflect.FileDescriptor)
+	)
+	visit = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			visit(imports.Get(i).FileDescriptor)
+		}
+		fdSet.File = append(fdSet.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	visit(fd)
+	return fdSet
 }
 
-// ensureChannel ensures a channel exists for a given signal; returns channel ID.
-func (w *Writer) ensureChannel(canID uint32, isExtended bool, messageName, signalName, unit string) (uint16, error) {
+// ensureChannel ensures a channel exists for spec; returns channel ID.
+func (w *Writer) ensureChannel(spec channelSpec) (uint16, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	var (
-		hexID = fmt.Sprintf("0x%X", canID)
-		key   = channelKey(hexID, signalName)
-	)
-	if id, ok := w.channels[key]; ok {
+	if id, ok := w.channels[spec.key]; ok {
 		return id, nil
 	}
 
+	schemaID, err := w.ensureSchema(spec.schema)
+	if err != nil {
+		return 0, err
+	}
+
 	// allocate new channel id (post-increment style so first channel=1)
-	var (
-		chID     = w.nextChanID
-		topic    = fmt.Sprintf("/can/%s/%s", messageName, signalName)
-		metadata = map[string]string{
-			"can_id":      hexID,
-			"message":     messageName,
-			"signal":      signalName,
-			"is_extended": fmt.Sprintf("%t", isExtended),
-		}
-	)
+	chID := w.nextChanID
 	w.nextChanID++
-	if unit != "" {
-		metadata["unit"] = unit
-	}
 
 	if err := w.writer.WriteChannel(&mcap.Channel{
 		ID:              chID,
-		SchemaID:        w.schemaID,
-		Topic:           topic,
-		MessageEncoding: "protobuf",
-		Metadata:        metadata,
+		SchemaID:        schemaID,
+		Topic:           spec.topic,
+		MessageEncoding: string(w.encoding),
+		Metadata:        spec.metadata,
 	}); err != nil {
-		return 0, errors.Wrap(err, fmt.Sprintf("write channel (topic=%s)", topic))
+		return 0, errors.Wrap(err, fmt.Sprintf("write channel (topic=%s)", spec.topic))
 	}
 
-	w.channels[key] = chID
+	w.channels[spec.key] = chID
 	return chID, nil
 }
 
-// WriteDecodedSignal writes a single DecodedSignal proto instance as an MCAP message.
-// ds.Timestamp must be set. LogTime/PublishTime use that timestamp.
-func (w *Writer) WriteDecodedSignal(ds *candecodeproto.DecodedSignal) error {
-	if ds == nil {
-		return errors.New("nil DecodedSignal")
+// recordTime converts a record timestamp; unset timestamps fall back to zero time.
+func recordTime(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
 	}
+	return t.AsTime()
+}
 
-	var ts time.Time // fallback to zero time
-	if t := ds.GetTimestamp(); t != nil {
-		ts = t.AsTime()
+// writeMessage marshals msg in the writer encoding and appends it to the channel.
+func (w *Writer) writeMessage(channelID uint16, ts time.Time, msg proto.Message) error {
+	var (
+		data []byte
+		err  error
+	)
+	if w.encoding == EncodingJSON {
+		data, err = marshalJSON(msg)
+	} else {
+		data, err = deterministic.Marshal(msg)
 	}
-
-	channelID, err := w.ensureChannel(ds.GetCanId(), ds.GetIsExtended(), ds.GetMessageName(), ds.GetName(), ds.GetSignal().GetUnit())
 	if err != nil {
-		return errors.Wrap(err, "ensure channel")
+		return errors.Wrap(err, fmt.Sprintf("marshal %s", msg.ProtoReflect().Descriptor().Name()))
 	}
 
-	data, err := proto.Marshal(ds)
-	if err != nil {
-		return errors.Wrap(err, "marshal DecodedSignal")
-	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
 
 	seq := w.channelSqc[channelID]
 	w.channelSqc[channelID]++
 
+	publishTime := ts
+	if w.publishTime == PublishTimeWallClock {
+		publishTime = time.Now()
+	}
+
 	if err := w.writer.WriteMessage(&mcap.Message{
 		ChannelID:   channelID,
 		Sequence:    seq,
 		LogTime:     uint64(ts.UnixNano()),
-		PublishTime: uint64(time.Now().UnixNano()),
+		PublishTime: uint64(publishTime.UnixNano()),
 		Data:        data,
 	}); err != nil {
 		return errors.Wrap(err, "write message")
@@ -194,6 +426,103 @@ func (w *Writer) WriteDecodedSignal(ds *candecodeproto.DecodedSignal) error {
 	return nil
 }
 
+// WriteDecodedSignal writes a single DecodedSignal proto instance as an MCAP message.
+// ds.Timestamp must be set. LogTime/PublishTime use that timestamp.
+func (w *Writer) WriteDecodedSignal(ds *candecodeproto.DecodedSignal) error {
+	if ds == nil {
+		return errors.New("nil DecodedSignal")
+	}
+
+	var (
+		hexID    = fmt.Sprintf("0x%X", ds.GetCanId())
+		metadata = frameMetadata(ds.GetBus(), ds.GetInterfaceIndex(), hexID, ds.GetMessageName(), ds.GetIsExtended())
+	)
+	metadata["signal"] = ds.GetName()
+	if unit := ds.GetSignal().GetUnit(); unit != "" {
+		metadata["unit"] = unit
+	}
+	addAttributes(metadata, "attr.", ds.GetSignal().GetAttributes())
+
+	channelID, err := w.ensureChannel(channelSpec{
+		key:      signalChannelKey(ds.GetBus(), hexID, ds.GetName()),
+		schema:   ds.ProtoReflect().Descriptor(),
+		topic:    fmt.Sprintf("/can/%s/%s/%s", ds.GetBus(), ds.GetMessageName(), ds.GetName()),
+		metadata: metadata,
+	})
+	if err != nil {
+		return errors.Wrap(err, "ensure channel")
+	}
+
+	return w.writeMessage(channelID, recordTime(ds.GetTimestamp()), ds)
+}
+
+// WriteDecodedMessage writes all decoded signals of one frame as a single MCAP message
+// on the per-message channel /can/<Bus>/<MessageName>.
+// The DBC attributes of def are stored in the channel metadata (see addMessageAttributes).
+func (w *Writer) WriteDecodedMessage(def *candecodeproto.MessageDefinition, dm *candecodeproto.DecodedMessage) error {
+	if def == nil || dm == nil {
+		return errors.New("nil MessageDefinition or DecodedMessage")
+	}
+
+	var (
+		hexID    = fmt.Sprintf("0x%X", dm.GetCanId())
+		metadata = frameMetadata(dm.GetBus(), dm.GetInterfaceIndex(), hexID, dm.GetName(), dm.GetIsExtended())
+	)
+	addMessageAttributes(metadata, def)
+
+	channelID, err := w.ensureChannel(channelSpec{
+		key:      messageChannelKey(dm.GetBus(), hexID),
+		schema:   dm.ProtoReflect().Descriptor(),
+		topic:    fmt.Sprintf("/can/%s/%s", dm.GetBus(), dm.GetName()),
+		metadata: metadata,
+	})
+	if err != nil {
+		return errors.Wrap(err, "ensure channel")
+	}
+
+	return w.writeMessage(channelID, recordTime(dm.GetTimestamp()), dm)
+}
+
+// WriteRawFrame writes one captured frame, decoded or not, on the per-bus channel /can/<Bus>/raw.
+func (w *Writer) WriteRawFrame(rf *candecodeproto.RawFrame) error {
+	if rf == nil {
+		return errors.New("nil RawFrame")
+	}
+
+	channelID, err := w.ensureChannel(channelSpec{
+		key:    rawChannelKey(rf.GetBus()),
+		schema: rf.ProtoReflect().Descriptor(),
+		topic:  fmt.Sprintf("/can/%s/raw", rf.GetBus()),
+		metadata: map[string]string{
+			"bus":             rf.GetBus(),
+			"interface_index": fmt.Sprintf("%d", rf.GetInterfaceIndex()),
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "ensure channel")
+	}
+
+	return w.writeMessage(channelID, recordTime(rf.GetTimestamp()), rf)
+}
+
+// WriteTimingViolation writes one cycle time violation on /diagnostics/timing.
+func (w *Writer) WriteTimingViolation(tv *candecodeproto.TimingViolation) error {
+	if tv == nil {
+		return errors.New("nil TimingViolation")
+	}
+
+	channelID, err := w.ensureChannel(channelSpec{
+		key:    timingChannelKey,
+		schema: tv.ProtoReflect().Descriptor(),
+		topic:  timingTopic,
+	})
+	if err != nil {
+		return errors.Wrap(err, "ensure channel")
+	}
+
+	return w.writeMessage(channelID, recordTime(tv.GetTimestamp()), tv)
+}
+
 // Close finalizes the MCAP file.
 func (w *Writer) Close() error {
 	w.mu.Lock()
@@ -9,31 +9,89 @@ import (
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 	"github.com/google/gopacket/pcapgo"
-	ecan "go.einride.tech/can"
 
 	"github.com/BIwashi/candecode/pkg/can"
 )
 
 // Reader reads CAN frames from PCAPNG file
 type Reader struct {
-	reader      *pcapgo.NgReader
-	linkType    layers.LinkType
-	packetCount uint64
+	reader       *pcapgo.NgReader
+	packetCount  uint64
+	skippedCount uint64
+	errorFrames  bool
+}
+
+type ReaderOption interface {
+	apply(*Reader)
+}
+
+type errorFramesOption struct{}
+
+func (errorFramesOption) apply(r *Reader) {
+	r.errorFrames = true
+}
+
+// WithErrorFrames returns SocketCAN error frames (Frame.IsError) instead of skipping them.
+func WithErrorFrames() ReaderOption {
+	return errorFramesOption{}
+}
+
+// Interface describes a capture interface (one Interface Description Block) of the PCAPNG file.
+type Interface struct {
+	Index    int
+	Name     string
+	LinkType layers.LinkType
 }
 
 // NewReader creates a new PCAPNG reader
-func NewReader(r io.Reader) (*Reader, error) {
-	ngReader, err := pcapgo.NewNgReader(r, pcapgo.DefaultNgReaderOptions)
+func NewReader(r io.Reader, opts ...ReaderOption) (*Reader, error) {
+	// Captures may mix interfaces with different link types (e.g. SocketCAN and Linux SLL),
+	// so the link type is resolved per packet instead of taken from the first interface.
+	ngOpts := pcapgo.DefaultNgReaderOptions
+	ngOpts.WantMixedLinkType = true
+
+	ngReader, err := pcapgo.NewNgReader(r, ngOpts)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create pcapng reader")
 	}
 
-	// Get link type from the first interface
-	linkType := ngReader.LinkType()
+	reader := &Reader{
+		reader: ngReader,
+	}
+	for _, o := range opts {
+		o.apply(reader)
+	}
+	return reader, nil
+}
+
+// Interfaces returns the capture interfaces read so far.
+// Interface Description Blocks may appear anywhere in a section, so the list can grow while reading.
+func (r *Reader) Interfaces() []Interface {
+	ifaces := make([]Interface, 0, r.reader.NInterfaces())
+	for i := 0; i < r.reader.NInterfaces(); i++ {
+		iface, err := r.iface(i)
+		if err != nil {
+			continue
+		}
+		ifaces = append(ifaces, iface)
+	}
+	return ifaces
+}
 
-	return &Reader{
-		reader:   ngReader,
-		linkType: linkType,
+// iface resolves the interface with the given ID. Unnamed interfaces are called if<ID>.
+func (r *Reader) iface(i int) (Interface, error) {
+	ngIface, err := r.reader.Interface(i)
+	if err != nil {
+		return Interface{}, errors.Wrap(err, "failed to resolve interface")
+	}
+	name := ngIface.Name
+	if name == "" {
+		name = fmt.Sprintf("if%d", i)
+	}
+	return Interface{
+		Index:    i,
+		Name:     name,
+		LinkType: ngIface.LinkType,
 	}, nil
 }
 
@@ -42,7 +100,7 @@ func (r *Reader) ReadNext() (*can.TimedFrame, error) {
 	for {
 		data, ci, err := r.reader.ReadPacketData()
 		if err != nil {
-			if err == io.EOF {
+			if errors.Is(err, io.EOF) {
 				return nil, io.EOF
 			}
 			return nil, errors.Wrap(err, "failed to read packet data")
@@ -50,29 +108,41 @@ func (r *Reader) ReadNext() (*can.TimedFrame, error) {
 
 		r.packetCount++
 
-		// Parse the packet based on link type
-		packet := gopacket.NewPacket(data, r.linkType, gopacket.Default)
+		iface, err := r.iface(ci.InterfaceIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		// Parse the packet based on the link type of its interface
+		packet := gopacket.NewPacket(data, iface.LinkType, gopacket.Default)
 
 		// Extract CAN frame based on the link type
-		canFrame, err := r.extractCANFrame(packet, ci)
+		canFrame, err := r.extractCANFrame(packet, iface.LinkType, ci)
 		if err != nil {
 			// Skip non-CAN packets
+			r.skippedCount++
 			continue
 		}
+		canFrame.InterfaceIndex = iface.Index
+		canFrame.Interface = iface.Name
 
 		return canFrame, nil
 	}
 }
 
 // extractCANFrame extracts CAN frame from the packet
-func (r *Reader) extractCANFrame(packet gopacket.Packet, ci gopacket.CaptureInfo) (*can.TimedFrame, error) {
-	var payload []byte
-	switch r.linkType {
+func (r *Reader) extractCANFrame(packet gopacket.Packet, linkType layers.LinkType, ci gopacket.CaptureInfo) (*can.TimedFrame, error) {
+	var (
+		payload []byte
+		isFD    bool
+	)
+	switch linkType {
 	case layers.LinkTypeLinuxSLL:
 		// Check if this is a Linux SLL (Linux cooked capture) packet
 		if sllLayer := packet.Layer(layers.LayerTypeLinuxSLL); sllLayer != nil {
 			sll := sllLayer.(*layers.LinuxSLL)
 			payload = sll.Payload
+			isFD = sll.EthernetType == ethPCANFD
 		} else {
 			// Try to parse as raw data
 			payload = packet.Data()
@@ -81,16 +151,17 @@ func (r *Reader) extractCANFrame(packet gopacket.Packet, ci gopacket.CaptureInfo
 		// Check if this is raw CAN
 		payload = packet.Data()
 	default:
-		return nil, fmt.Errorf("unsupported link type: %v", r.linkType)
+		return nil, fmt.Errorf("unsupported link type: %v", linkType)
 	}
 
-	canFrame, isError, err := r.extractRawCANFrame(payload, ci)
+	canFrame, isError, err := r.extractRawCANFrame(payload, isFD, ci)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to extract RawCAN frame")
 	}
-	if isError {
+	if isError && !r.errorFrames {
 		return nil, errors.New("error in RawCAN frame")
 	}
+	canFrame.IsError = isError
 
 	return canFrame, nil
 }
@@ -101,10 +172,22 @@ const (
 	idFlagError    = 0x20000000
 	idMaskExtended = 0x1fffffff
 	idMaskStandard = 0x7ff
+
+	// SocketCAN canfd_frame flags (byte 5 of the frame header).
+	fdFlagBRS = 0x01 // bit rate switch
+	fdFlagESI = 0x02 // error state indicator
+	fdFlagFDF = 0x04 // FD frame, set by newer kernels
+
+	// ethPCANFD is the Linux SLL protocol type of CAN FD frames (ETH_P_CANFD).
+	ethPCANFD layers.EthernetType = 0x000D
+	// canFDMTU is the size of a SocketCAN canfd_frame.
+	canFDMTU = 8 + can.MaxFDDataLength
 )
 
-// extractRawCANFrame extracts CAN frame from raw CAN format
-func (r *Reader) extractRawCANFrame(data []byte, ci gopacket.CaptureInfo) (*can.TimedFrame, bool, error) {
+// extractRawCANFrame extracts CAN frame from raw CAN format.
+// Both SocketCAN can_frame and canfd_frame layouts are accepted; isFD forces the latter
+// when the link layer already tells us the protocol.
+func (r *Reader) extractRawCANFrame(data []byte, isFD bool, ci gopacket.CaptureInfo) (*can.TimedFrame, bool, error) {
 	// Raw CAN frame format (similar to SocketCAN but without SLL header)
 	if len(data) < 8 {
 		return nil, false, errors.New(fmt.Sprintf("data too short for CAN frame: %d", len(data)))
@@ -128,25 +211,42 @@ func (r *Reader) extractRawCANFrame(data []byte, ci gopacket.CaptureInfo) (*can.
 		canID = canIDRaw & idMaskStandard
 	}
 
-	// Get data length
-	dataLen := data[4]
-	if dataLen > 8 {
-		dataLen = 8
+	var (
+		// Get data length and FD flags
+		dataLen = data[4]
+		fdFlags = data[5]
+	)
+	if fdFlags&fdFlagFDF != 0 || len(data) == canFDMTU || dataLen > can.MaxDataLength {
+		isFD = true
+	}
+
+	maxLen := uint8(can.MaxDataLength)
+	if isFD {
+		maxLen = can.MaxFDDataLength
+	} else {
+		// can_frame has no flags byte; the padding must not be read as BRS/ESI.
+		fdFlags = 0
+	}
+	if dataLen > maxLen {
+		dataLen = maxLen
 	}
 
 	// Extract data
-	var canData ecan.Data
+	var canData can.Data
 	if len(data) >= 8+int(dataLen) {
-		copy(canData[:], data[8:8+dataLen])
+		copy(canData[:], data[8:8+int(dataLen)])
 	}
 
 	return &can.TimedFrame{
-		Frame: ecan.Frame{
+		Frame: can.Frame{
 			ID:         canID,
 			Length:     dataLen,
 			Data:       canData,
-			IsRemote:   isRemote,
+			IsRemote:   isRemote && !isFD, // CAN FD has no remote frames
 			IsExtended: isExtended,
+			IsFD:       isFD,
+			BRS:        fdFlags&fdFlagBRS != 0,
+			ESI:        fdFlags&fdFlagESI != 0,
 		},
 		Timestamp: ci.Timestamp,
 	}, isError, nil
@@ -157,6 +257,12 @@ func (r *Reader) GetPacketCount() uint64 {
 	return r.packetCount
 }
 
+// GetSkippedCount returns the number of packets skipped because they hold no CAN frame
+// (other link types, error frames, truncated packets).
+func (r *Reader) GetSkippedCount() uint64 {
+	return r.skippedCount
+}
+
 // ReadFrame provides backward-compatible name expected by converter code.
 func (r *Reader) ReadFrame() (*can.TimedFrame, error) {
 	return r.ReadNext()
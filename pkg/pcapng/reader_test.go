@@ -0,0 +1,214 @@
+package pcapng
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// socketCANFrame builds a SocketCAN can_frame (16 bytes) or canfd_frame (size bytes).
+func socketCANFrame(size int, id uint32, length, flags byte, payload ...byte) []byte {
+	data := make([]byte, size)
+	binary.LittleEndian.PutUint32(data[0:4], id)
+	data[4] = length
+	data[5] = flags
+	copy(data[8:], payload)
+	return data
+}
+
+func TestExtractRawCANFrame(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		isFD       bool
+		wantID     uint32
+		wantLength uint8
+		wantFD     bool
+		wantBRS    bool
+		wantESI    bool
+		wantRemote bool
+		wantExt    bool
+		wantError  bool
+	}{
+		{
+			name: "classic can_frame", data: socketCANFrame(16, 0x123, 8, 0, 1, 2, 3, 4, 5, 6, 7, 8),
+			wantID: 0x123, wantLength: 8,
+		},
+		{
+			name: "classic padding is not read as flags", data: socketCANFrame(16, 0x123, 2, fdFlagBRS|fdFlagESI, 1, 2),
+			wantID: 0x123, wantLength: 2,
+		},
+		{
+			name: "extended remote frame", data: socketCANFrame(16, 0x18FEF100|idFlagExtended|idFlagRemote, 4, 0),
+			wantID: 0x18FEF100, wantLength: 4, wantExt: true, wantRemote: true,
+		},
+		{
+			name: "error frame", data: socketCANFrame(16, 0x004|idFlagError, 8, 0),
+			wantID: 0x004, wantLength: 8, wantError: true,
+		},
+		{
+			name: "canfd_frame detected by its size", data: socketCANFrame(canFDMTU, 0x1A0, 8, fdFlagBRS),
+			wantID: 0x1A0, wantLength: 8, wantFD: true, wantBRS: true,
+		},
+		{
+			name: "canfd_frame detected by the FDF flag", data: socketCANFrame(16, 0x1A0, 8, fdFlagFDF|fdFlagESI),
+			wantID: 0x1A0, wantLength: 8, wantFD: true, wantESI: true,
+		},
+		{
+			name: "canfd_frame detected by its length", data: socketCANFrame(canFDMTU, 0x1A0, 12, 0),
+			wantID: 0x1A0, wantLength: 12, wantFD: true,
+		},
+		{
+			name: "canfd_frame from the link layer", data: socketCANFrame(16, 0x1A0, 8, fdFlagBRS), isFD: true,
+			wantID: 0x1A0, wantLength: 8, wantFD: true, wantBRS: true,
+		},
+		{
+			name: "FD has no remote frames", data: socketCANFrame(canFDMTU, 0x1A0|idFlagRemote, 64, 0),
+			wantID: 0x1A0, wantLength: 64, wantFD: true,
+		},
+		{
+			name: "length clamped to 64", data: socketCANFrame(canFDMTU, 0x1A0, 80, 0),
+			wantID: 0x1A0, wantLength: 64, wantFD: true,
+		},
+	}
+	r := &Reader{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := time.Unix(1700000000, 0)
+			f, isError, err := r.extractRawCANFrame(tt.data, tt.isFD, gopacket.CaptureInfo{Timestamp: ts})
+			if err != nil {
+				t.Fatalf("extractRawCANFrame() error = %v", err)
+			}
+			if f.ID != tt.wantID || f.Length != tt.wantLength || f.IsFD != tt.wantFD || f.BRS != tt.wantBRS ||
+				f.ESI != tt.wantESI || f.IsRemote != tt.wantRemote || f.IsExtended != tt.wantExt || isError != tt.wantError {
+				t.Errorf("extractRawCANFrame() = %+v (error frame %t)", f.Frame, isError)
+			}
+			if !f.Timestamp.Equal(ts) {
+				t.Errorf("timestamp = %s, want %s", f.Timestamp, ts)
+			}
+		})
+	}
+}
+
+func TestExtractRawCANFramePayload(t *testing.T) {
+	payload := make([]byte, 64)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	f, _, err := (&Reader{}).extractRawCANFrame(socketCANFrame(canFDMTU, 0x1A0, 64, 0, payload...), false, gopacket.CaptureInfo{})
+	if err != nil {
+		t.Fatalf("extractRawCANFrame() error = %v", err)
+	}
+	if got := f.Payload(); string(got) != string(payload) {
+		t.Errorf("payload = % X, want % X", got, payload)
+	}
+	if f.DLC() != 15 {
+		t.Errorf("DLC() = %d, want 15", f.DLC())
+	}
+
+	if _, _, err := (&Reader{}).extractRawCANFrame(make([]byte, 7), false, gopacket.CaptureInfo{}); err == nil {
+		t.Error("extractRawCANFrame() of a 7 byte packet succeeded")
+	}
+}
+
+// sllFrame prepends a Linux SLL header with the given protocol to a SocketCAN frame.
+func sllFrame(protocol uint16, frame []byte) []byte {
+	header := make([]byte, 16)
+	binary.BigEndian.PutUint16(header[2:4], 280) // ARPHRD_CAN
+	binary.BigEndian.PutUint16(header[14:16], protocol)
+	return append(header, frame...)
+}
+
+func TestReaderInterfaces(t *testing.T) {
+	var buf bytes.Buffer
+	ngIface := pcapgo.DefaultNgInterface
+	ngIface.Name = "can0"
+	ngIface.LinkType = 227 // LINKTYPE_CAN_SOCKETCAN
+	w, err := pcapgo.NewNgWriterInterface(&buf, ngIface, pcapgo.DefaultNgWriterOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an unnamed Linux SLL interface and an Ethernet interface
+	for _, iface := range []pcapgo.NgInterface{
+		{LinkType: layers.LinkTypeLinuxSLL, TimestampResolution: 9},
+		{Name: "eth0", LinkType: layers.LinkTypeEthernet, TimestampResolution: 9},
+	} {
+		if _, err := w.AddInterface(iface); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ts := time.Unix(1700000000, 0)
+	for i, p := range []struct {
+		iface int
+		data  []byte
+	}{
+		{0, socketCANFrame(16, 0x123, 1, 0, 0xAA)},
+		{1, sllFrame(0x000C, socketCANFrame(16, 0x456, 2, 0, 0xBB, 0xCC))},
+		{2, make([]byte, 60)},
+		{1, sllFrame(uint16(ethPCANFD), socketCANFrame(canFDMTU, 0x1A0, 12, fdFlagBRS))},
+	} {
+		ci := gopacket.CaptureInfo{
+			Timestamp: ts.Add(time.Duration(i) * time.Millisecond), InterfaceIndex: p.iface,
+			CaptureLength: len(p.data), Length: len(p.data),
+		}
+		if err := w.WritePacket(ci, p.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	want := []struct {
+		id     uint32
+		index  int
+		name   string
+		length uint8
+		isFD   bool
+	}{
+		{0x123, 0, "can0", 1, false},
+		{0x456, 1, "if1", 2, false},
+		{0x1A0, 1, "if1", 12, true},
+	}
+	for i, w := range want {
+		f, err := r.ReadFrame()
+		if err != nil {
+			t.Fatalf("frame %d: ReadFrame() error = %v", i, err)
+		}
+		if f.ID != w.id || f.InterfaceIndex != w.index || f.Interface != w.name || f.Length != w.length || f.IsFD != w.isFD {
+			t.Errorf("frame %d = %+v on %d %s, want 0x%X on %d %s", i, f.Frame, f.InterfaceIndex, f.Interface, w.id, w.index, w.name)
+		}
+	}
+	if _, err := r.ReadFrame(); !errors.Is(err, io.EOF) {
+		t.Errorf("ReadFrame() at the end error = %v, want EOF", err)
+	}
+	if r.GetPacketCount() != 4 || r.GetSkippedCount() != 1 {
+		t.Errorf("packets = %d, skipped = %d, want 4, 1", r.GetPacketCount(), r.GetSkippedCount())
+	}
+
+	wantIfaces := []Interface{
+		{Index: 0, Name: "can0", LinkType: 227},
+		{Index: 1, Name: "if1", LinkType: layers.LinkTypeLinuxSLL},
+		{Index: 2, Name: "eth0", LinkType: layers.LinkTypeEthernet},
+	}
+	ifaces := r.Interfaces()
+	if len(ifaces) != len(wantIfaces) {
+		t.Fatalf("Interfaces() = %+v, want %+v", ifaces, wantIfaces)
+	}
+	for i := range wantIfaces {
+		if ifaces[i] != wantIfaces[i] {
+			t.Errorf("Interfaces()[%d] = %+v, want %+v", i, ifaces[i], wantIfaces[i])
+		}
+	}
+}
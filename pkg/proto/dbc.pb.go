@@ -22,44 +22,1182 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type TimingViolationKind int32
+
+const (
+	TimingViolationKind_TIMING_VIOLATION_KIND_UNSPECIFIED TimingViolationKind = 0
+	// No frame for longer than the timeout factor times the cycle time.
+	TimingViolationKind_TIMING_VIOLATION_KIND_TIMEOUT TimingViolationKind = 1
+	// The interval deviates from the cycle time by more than the jitter tolerance.
+	TimingViolationKind_TIMING_VIOLATION_KIND_JITTER TimingViolationKind = 2
+	// The message never appears on the bus.
+	TimingViolationKind_TIMING_VIOLATION_KIND_MISSING TimingViolationKind = 3
+)
+
+// Enum value maps for TimingViolationKind.
+var (
+	TimingViolationKind_name = map[int32]string{
+		0: "TIMING_VIOLATION_KIND_UNSPECIFIED",
+		1: "TIMING_VIOLATION_KIND_TIMEOUT",
+		2: "TIMING_VIOLATION_KIND_JITTER",
+		3: "TIMING_VIOLATION_KIND_MISSING",
+	}
+	TimingViolationKind_value = map[string]int32{
+		"TIMING_VIOLATION_KIND_UNSPECIFIED": 0,
+		"TIMING_VIOLATION_KIND_TIMEOUT":     1,
+		"TIMING_VIOLATION_KIND_JITTER":      2,
+		"TIMING_VIOLATION_KIND_MISSING":     3,
+	}
+)
+
+func (x TimingViolationKind) Enum() *TimingViolationKind {
+	p := new(TimingViolationKind)
+	*p = x
+	return p
+}
+
+func (x TimingViolationKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TimingViolationKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_proto_dbc_proto_enumTypes[0].Descriptor()
+}
+
+func (TimingViolationKind) Type() protoreflect.EnumType {
+	return &file_pkg_proto_dbc_proto_enumTypes[0]
+}
+
+func (x TimingViolationKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TimingViolationKind.Descriptor instead.
+func (TimingViolationKind) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{0}
+}
+
+// LengthOutcome records how the frame length compared with the DBC message length
+// under the length policy of the conversion.
+type LengthOutcome int32
+
+const (
+	// The frame has the DBC message length.
+	LengthOutcome_LENGTH_OUTCOME_MATCH LengthOutcome = 0
+	// The frame is shorter; signals past its end are missing (lenient).
+	LengthOutcome_LENGTH_OUTCOME_TRUNCATED LengthOutcome = 1
+	// The frame is longer; the extra bytes are ignored.
+	LengthOutcome_LENGTH_OUTCOME_PADDED LengthOutcome = 2
+	// The frame is shorter and was decoded as if zero padded (ignore-length).
+	LengthOutcome_LENGTH_OUTCOME_IGNORED LengthOutcome = 3
+)
+
+// Enum value maps for LengthOutcome.
+var (
+	LengthOutcome_name = map[int32]string{
+		0: "LENGTH_OUTCOME_MATCH",
+		1: "LENGTH_OUTCOME_TRUNCATED",
+		2: "LENGTH_OUTCOME_PADDED",
+		3: "LENGTH_OUTCOME_IGNORED",
+	}
+	LengthOutcome_value = map[string]int32{
+		"LENGTH_OUTCOME_MATCH":     0,
+		"LENGTH_OUTCOME_TRUNCATED": 1,
+		"LENGTH_OUTCOME_PADDED":    2,
+		"LENGTH_OUTCOME_IGNORED":   3,
+	}
+)
+
+func (x LengthOutcome) Enum() *LengthOutcome {
+	p := new(LengthOutcome)
+	*p = x
+	return p
+}
+
+func (x LengthOutcome) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LengthOutcome) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_proto_dbc_proto_enumTypes[1].Descriptor()
+}
+
+func (LengthOutcome) Type() protoreflect.EnumType {
+	return &file_pkg_proto_dbc_proto_enumTypes[1]
+}
+
+func (x LengthOutcome) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LengthOutcome.Descriptor instead.
+func (LengthOutcome) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{1}
+}
+
+type ChecksumStatus int32
+
+const (
+	// The message has no checksum signal or no algorithm applies to it.
+	ChecksumStatus_CHECKSUM_STATUS_UNCHECKED ChecksumStatus = 0
+	ChecksumStatus_CHECKSUM_STATUS_VALID     ChecksumStatus = 1
+	ChecksumStatus_CHECKSUM_STATUS_INVALID   ChecksumStatus = 2
+)
+
+// Enum value maps for ChecksumStatus.
+var (
+	ChecksumStatus_name = map[int32]string{
+		0: "CHECKSUM_STATUS_UNCHECKED",
+		1: "CHECKSUM_STATUS_VALID",
+		2: "CHECKSUM_STATUS_INVALID",
+	}
+	ChecksumStatus_value = map[string]int32{
+		"CHECKSUM_STATUS_UNCHECKED": 0,
+		"CHECKSUM_STATUS_VALID":     1,
+		"CHECKSUM_STATUS_INVALID":   2,
+	}
+)
+
+func (x ChecksumStatus) Enum() *ChecksumStatus {
+	p := new(ChecksumStatus)
+	*p = x
+	return p
+}
+
+func (x ChecksumStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ChecksumStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_proto_dbc_proto_enumTypes[2].Descriptor()
+}
+
+func (ChecksumStatus) Type() protoreflect.EnumType {
+	return &file_pkg_proto_dbc_proto_enumTypes[2]
+}
+
+func (x ChecksumStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ChecksumStatus.Descriptor instead.
+func (ChecksumStatus) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{2}
+}
+
+type CounterStatus int32
+
+const (
+	// The message has no counter signal.
+	CounterStatus_COUNTER_STATUS_UNCHECKED CounterStatus = 0
+	// The counter advanced by one, or the frame is the first of its message.
+	CounterStatus_COUNTER_STATUS_OK CounterStatus = 1
+	// Counter values were skipped (lost or dropped frames).
+	CounterStatus_COUNTER_STATUS_SKIP CounterStatus = 2
+	// The counter did not advance (repeated or replayed frame).
+	CounterStatus_COUNTER_STATUS_REPEAT CounterStatus = 3
+)
+
+// Enum value maps for CounterStatus.
+var (
+	CounterStatus_name = map[int32]string{
+		0: "COUNTER_STATUS_UNCHECKED",
+		1: "COUNTER_STATUS_OK",
+		2: "COUNTER_STATUS_SKIP",
+		3: "COUNTER_STATUS_REPEAT",
+	}
+	CounterStatus_value = map[string]int32{
+		"COUNTER_STATUS_UNCHECKED": 0,
+		"COUNTER_STATUS_OK":        1,
+		"COUNTER_STATUS_SKIP":      2,
+		"COUNTER_STATUS_REPEAT":    3,
+	}
+)
+
+func (x CounterStatus) Enum() *CounterStatus {
+	p := new(CounterStatus)
+	*p = x
+	return p
+}
+
+func (x CounterStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CounterStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_proto_dbc_proto_enumTypes[3].Descriptor()
+}
+
+func (CounterStatus) Type() protoreflect.EnumType {
+	return &file_pkg_proto_dbc_proto_enumTypes[3]
+}
+
+func (x CounterStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CounterStatus.Descriptor instead.
+func (CounterStatus) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{3}
+}
+
+// Direction is the direction of a frame as seen by the capturing node.
+type Direction int32
+
+const (
+	// The capture does not record the direction (pcapng).
+	Direction_DIRECTION_UNSPECIFIED Direction = 0
+	Direction_DIRECTION_RX          Direction = 1
+	Direction_DIRECTION_TX          Direction = 2
+)
+
+// Enum value maps for Direction.
+var (
+	Direction_name = map[int32]string{
+		0: "DIRECTION_UNSPECIFIED",
+		1: "DIRECTION_RX",
+		2: "DIRECTION_TX",
+	}
+	Direction_value = map[string]int32{
+		"DIRECTION_UNSPECIFIED": 0,
+		"DIRECTION_RX":          1,
+		"DIRECTION_TX":          2,
+	}
+)
+
+func (x Direction) Enum() *Direction {
+	p := new(Direction)
+	*p = x
+	return p
+}
+
+func (x Direction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Direction) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_proto_dbc_proto_enumTypes[4].Descriptor()
+}
+
+func (Direction) Type() protoreflect.EnumType {
+	return &file_pkg_proto_dbc_proto_enumTypes[4]
+}
+
+func (x Direction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Direction.Descriptor instead.
+func (Direction) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{4}
+}
+
 type DecodedSignal struct {
 	state       protoimpl.MessageState `protogen:"open.v1"`
 	MessageName string                 `protobuf:"bytes,1,opt,name=message_name,json=messageName,proto3" json:"message_name,omitempty"`
 	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	// Types that are valid to be assigned to Raw:
 	//
-	//	*DecodedSignal_RawU
-	//	*DecodedSignal_RawS
-	//	*DecodedSignal_RawF
-	//	*DecodedSignal_RawB
-	//	*DecodedSignal_RawBytes
-	Raw           isDecodedSignal_Raw    `protobuf_oneof:"raw"`
-	Physical      *float64               `protobuf:"fixed64,8,opt,name=physical,proto3,oneof" json:"physical,omitempty"`
-	Description   string                 `protobuf:"bytes,9,opt,name=description,proto3" json:"description,omitempty"`
-	Signal        *Signal                `protobuf:"bytes,10,opt,name=signal,proto3" json:"signal,omitempty"`
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	CanId         uint32                 `protobuf:"varint,12,opt,name=can_id,json=canId,proto3" json:"can_id,omitempty"`
-	IsExtended    bool                   `protobuf:"varint,13,opt,name=is_extended,json=isExtended,proto3" json:"is_extended,omitempty"`
-	FrameBytes    []byte                 `protobuf:"bytes,14,opt,name=frame_bytes,json=frameBytes,proto3" json:"frame_bytes,omitempty"`
+	//	*DecodedSignal_RawU
+	//	*DecodedSignal_RawS
+	//	*DecodedSignal_RawF
+	//	*DecodedSignal_RawB
+	//	*DecodedSignal_RawBytes
+	Raw            isDecodedSignal_Raw    `protobuf_oneof:"raw"`
+	Physical       *float64               `protobuf:"fixed64,8,opt,name=physical,proto3,oneof" json:"physical,omitempty"`
+	Description    string                 `protobuf:"bytes,9,opt,name=description,proto3" json:"description,omitempty"`
+	Signal         *Signal                `protobuf:"bytes,10,opt,name=signal,proto3" json:"signal,omitempty"`
+	Timestamp      *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	CanId          uint32                 `protobuf:"varint,12,opt,name=can_id,json=canId,proto3" json:"can_id,omitempty"`
+	IsExtended     bool                   `protobuf:"varint,13,opt,name=is_extended,json=isExtended,proto3" json:"is_extended,omitempty"`
+	FrameBytes     []byte                 `protobuf:"bytes,14,opt,name=frame_bytes,json=frameBytes,proto3" json:"frame_bytes,omitempty"`
+	IsFd           bool                   `protobuf:"varint,15,opt,name=is_fd,json=isFd,proto3" json:"is_fd,omitempty"`
+	Brs            bool                   `protobuf:"varint,16,opt,name=brs,proto3" json:"brs,omitempty"`
+	Esi            bool                   `protobuf:"varint,17,opt,name=esi,proto3" json:"esi,omitempty"`
+	Bus            string                 `protobuf:"bytes,18,opt,name=bus,proto3" json:"bus,omitempty"`
+	InterfaceIndex uint32                 `protobuf:"varint,19,opt,name=interface_index,json=interfaceIndex,proto3" json:"interface_index,omitempty"`
+	LengthOutcome  LengthOutcome          `protobuf:"varint,20,opt,name=length_outcome,json=lengthOutcome,proto3,enum=candecode.proto.v1.LengthOutcome" json:"length_outcome,omitempty"`
+	// validity is set when checksums and counters are validated (convert --validate).
+	Validity *Validity `protobuf:"bytes,21,opt,name=validity,proto3" json:"validity,omitempty"`
+	// out_of_range is set when the physical value lies outside the DBC [min, max] range.
+	OutOfRange bool `protobuf:"varint,22,opt,name=out_of_range,json=outOfRange,proto3" json:"out_of_range,omitempty"`
+	// undefined_enum is set when the signal has value descriptions but none matches the raw value.
+	UndefinedEnum bool      `protobuf:"varint,23,opt,name=undefined_enum,json=undefinedEnum,proto3" json:"undefined_enum,omitempty"`
+	Direction     Direction `protobuf:"varint,24,opt,name=direction,proto3,enum=candecode.proto.v1.Direction" json:"direction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DecodedSignal) Reset() {
+	*x = DecodedSignal{}
+	mi := &file_pkg_proto_dbc_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecodedSignal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecodedSignal) ProtoMessage() {}
+
+func (x *DecodedSignal) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_dbc_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecodedSignal.ProtoReflect.Descriptor instead.
+func (*DecodedSignal) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DecodedSignal) GetMessageName() string {
+	if x != nil {
+		return x.MessageName
+	}
+	return ""
+}
+
+func (x *DecodedSignal) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DecodedSignal) GetRaw() isDecodedSignal_Raw {
+	if x != nil {
+		return x.Raw
+	}
+	return nil
+}
+
+func (x *DecodedSignal) GetRawU() uint64 {
+	if x != nil {
+		if x, ok := x.Raw.(*DecodedSignal_RawU); ok {
+			return x.RawU
+		}
+	}
+	return 0
+}
+
+func (x *DecodedSignal) GetRawS() int64 {
+	if x != nil {
+		if x, ok := x.Raw.(*DecodedSignal_RawS); ok {
+			return x.RawS
+		}
+	}
+	return 0
+}
+
+func (x *DecodedSignal) GetRawF() float64 {
+	if x != nil {
+		if x, ok := x.Raw.(*DecodedSignal_RawF); ok {
+			return x.RawF
+		}
+	}
+	return 0
+}
+
+func (x *DecodedSignal) GetRawB() bool {
+	if x != nil {
+		if x, ok := x.Raw.(*DecodedSignal_RawB); ok {
+			return x.RawB
+		}
+	}
+	return false
+}
+
+func (x *DecodedSignal) GetRawBytes() []byte {
+	if x != nil {
+		if x, ok := x.Raw.(*DecodedSignal_RawBytes); ok {
+			return x.RawBytes
+		}
+	}
+	return nil
+}
+
+func (x *DecodedSignal) GetPhysical() float64 {
+	if x != nil && x.Physical != nil {
+		return *x.Physical
+	}
+	return 0
+}
+
+func (x *DecodedSignal) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *DecodedSignal) GetSignal() *Signal {
+	if x != nil {
+		return x.Signal
+	}
+	return nil
+}
+
+func (x *DecodedSignal) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *DecodedSignal) GetCanId() uint32 {
+	if x != nil {
+		return x.CanId
+	}
+	return 0
+}
+
+func (x *DecodedSignal) GetIsExtended() bool {
+	if x != nil {
+		return x.IsExtended
+	}
+	return false
+}
+
+func (x *DecodedSignal) GetFrameBytes() []byte {
+	if x != nil {
+		return x.FrameBytes
+	}
+	return nil
+}
+
+func (x *DecodedSignal) GetIsFd() bool {
+	if x != nil {
+		return x.IsFd
+	}
+	return false
+}
+
+func (x *DecodedSignal) GetBrs() bool {
+	if x != nil {
+		return x.Brs
+	}
+	return false
+}
+
+func (x *DecodedSignal) GetEsi() bool {
+	if x != nil {
+		return x.Esi
+	}
+	return false
+}
+
+func (x *DecodedSignal) GetBus() string {
+	if x != nil {
+		return x.Bus
+	}
+	return ""
+}
+
+func (x *DecodedSignal) GetInterfaceIndex() uint32 {
+	if x != nil {
+		return x.InterfaceIndex
+	}
+	return 0
+}
+
+func (x *DecodedSignal) GetLengthOutcome() LengthOutcome {
+	if x != nil {
+		return x.LengthOutcome
+	}
+	return LengthOutcome_LENGTH_OUTCOME_MATCH
+}
+
+func (x *DecodedSignal) GetValidity() *Validity {
+	if x != nil {
+		return x.Validity
+	}
+	return nil
+}
+
+func (x *DecodedSignal) GetOutOfRange() bool {
+	if x != nil {
+		return x.OutOfRange
+	}
+	return false
+}
+
+func (x *DecodedSignal) GetUndefinedEnum() bool {
+	if x != nil {
+		return x.UndefinedEnum
+	}
+	return false
+}
+
+func (x *DecodedSignal) GetDirection() Direction {
+	if x != nil {
+		return x.Direction
+	}
+	return Direction_DIRECTION_UNSPECIFIED
+}
+
+type isDecodedSignal_Raw interface {
+	isDecodedSignal_Raw()
+}
+
+type DecodedSignal_RawU struct {
+	RawU uint64 `protobuf:"varint,3,opt,name=raw_u,json=rawU,proto3,oneof"`
+}
+
+type DecodedSignal_RawS struct {
+	RawS int64 `protobuf:"zigzag64,4,opt,name=raw_s,json=rawS,proto3,oneof"`
+}
+
+type DecodedSignal_RawF struct {
+	RawF float64 `protobuf:"fixed64,5,opt,name=raw_f,json=rawF,proto3,oneof"`
+}
+
+type DecodedSignal_RawB struct {
+	RawB bool `protobuf:"varint,6,opt,name=raw_b,json=rawB,proto3,oneof"`
+}
+
+type DecodedSignal_RawBytes struct {
+	RawBytes []byte `protobuf:"bytes,7,opt,name=raw_bytes,json=rawBytes,proto3,oneof"`
+}
+
+func (*DecodedSignal_RawU) isDecodedSignal_Raw() {}
+
+func (*DecodedSignal_RawS) isDecodedSignal_Raw() {}
+
+func (*DecodedSignal_RawF) isDecodedSignal_Raw() {}
+
+func (*DecodedSignal_RawB) isDecodedSignal_Raw() {}
+
+func (*DecodedSignal_RawBytes) isDecodedSignal_Raw() {}
+
+// DecodedMessage holds all decoded signals of one CAN frame.
+type DecodedMessage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Timestamp      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	CanId          uint32                 `protobuf:"varint,3,opt,name=can_id,json=canId,proto3" json:"can_id,omitempty"`
+	IsExtended     bool                   `protobuf:"varint,4,opt,name=is_extended,json=isExtended,proto3" json:"is_extended,omitempty"`
+	FrameBytes     []byte                 `protobuf:"bytes,5,opt,name=frame_bytes,json=frameBytes,proto3" json:"frame_bytes,omitempty"`
+	IsFd           bool                   `protobuf:"varint,6,opt,name=is_fd,json=isFd,proto3" json:"is_fd,omitempty"`
+	Brs            bool                   `protobuf:"varint,7,opt,name=brs,proto3" json:"brs,omitempty"`
+	Esi            bool                   `protobuf:"varint,8,opt,name=esi,proto3" json:"esi,omitempty"`
+	Bus            string                 `protobuf:"bytes,9,opt,name=bus,proto3" json:"bus,omitempty"`
+	InterfaceIndex uint32                 `protobuf:"varint,10,opt,name=interface_index,json=interfaceIndex,proto3" json:"interface_index,omitempty"`
+	Signals        []*SignalValue         `protobuf:"bytes,11,rep,name=signals,proto3" json:"signals,omitempty"`
+	LengthOutcome  LengthOutcome          `protobuf:"varint,12,opt,name=length_outcome,json=lengthOutcome,proto3,enum=candecode.proto.v1.LengthOutcome" json:"length_outcome,omitempty"`
+	Validity       *Validity              `protobuf:"bytes,13,opt,name=validity,proto3" json:"validity,omitempty"`
+	Direction      Direction              `protobuf:"varint,14,opt,name=direction,proto3,enum=candecode.proto.v1.Direction" json:"direction,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DecodedMessage) Reset() {
+	*x = DecodedMessage{}
+	mi := &file_pkg_proto_dbc_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecodedMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecodedMessage) ProtoMessage() {}
+
+func (x *DecodedMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_dbc_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecodedMessage.ProtoReflect.Descriptor instead.
+func (*DecodedMessage) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DecodedMessage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DecodedMessage) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *DecodedMessage) GetCanId() uint32 {
+	if x != nil {
+		return x.CanId
+	}
+	return 0
+}
+
+func (x *DecodedMessage) GetIsExtended() bool {
+	if x != nil {
+		return x.IsExtended
+	}
+	return false
+}
+
+func (x *DecodedMessage) GetFrameBytes() []byte {
+	if x != nil {
+		return x.FrameBytes
+	}
+	return nil
+}
+
+func (x *DecodedMessage) GetIsFd() bool {
+	if x != nil {
+		return x.IsFd
+	}
+	return false
+}
+
+func (x *DecodedMessage) GetBrs() bool {
+	if x != nil {
+		return x.Brs
+	}
+	return false
+}
+
+func (x *DecodedMessage) GetEsi() bool {
+	if x != nil {
+		return x.Esi
+	}
+	return false
+}
+
+func (x *DecodedMessage) GetBus() string {
+	if x != nil {
+		return x.Bus
+	}
+	return ""
+}
+
+func (x *DecodedMessage) GetInterfaceIndex() uint32 {
+	if x != nil {
+		return x.InterfaceIndex
+	}
+	return 0
+}
+
+func (x *DecodedMessage) GetSignals() []*SignalValue {
+	if x != nil {
+		return x.Signals
+	}
+	return nil
+}
+
+func (x *DecodedMessage) GetLengthOutcome() LengthOutcome {
+	if x != nil {
+		return x.LengthOutcome
+	}
+	return LengthOutcome_LENGTH_OUTCOME_MATCH
+}
+
+func (x *DecodedMessage) GetValidity() *Validity {
+	if x != nil {
+		return x.Validity
+	}
+	return nil
+}
+
+func (x *DecodedMessage) GetDirection() Direction {
+	if x != nil {
+		return x.Direction
+	}
+	return Direction_DIRECTION_UNSPECIFIED
+}
+
+// RawFrame is one CAN frame as captured, decoded or not.
+type RawFrame struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	CanId      uint32                 `protobuf:"varint,2,opt,name=can_id,json=canId,proto3" json:"can_id,omitempty"`
+	IsExtended bool                   `protobuf:"varint,3,opt,name=is_extended,json=isExtended,proto3" json:"is_extended,omitempty"`
+	IsRemote   bool                   `protobuf:"varint,4,opt,name=is_remote,json=isRemote,proto3" json:"is_remote,omitempty"`
+	IsFd       bool                   `protobuf:"varint,5,opt,name=is_fd,json=isFd,proto3" json:"is_fd,omitempty"`
+	Brs        bool                   `protobuf:"varint,6,opt,name=brs,proto3" json:"brs,omitempty"`
+	Esi        bool                   `protobuf:"varint,7,opt,name=esi,proto3" json:"esi,omitempty"`
+	// dlc is the data length code; for CAN FD frames it encodes lengths 12..64 as 9..15.
+	Dlc            uint32    `protobuf:"varint,8,opt,name=dlc,proto3" json:"dlc,omitempty"`
+	Data           []byte    `protobuf:"bytes,9,opt,name=data,proto3" json:"data,omitempty"`
+	Bus            string    `protobuf:"bytes,10,opt,name=bus,proto3" json:"bus,omitempty"`
+	InterfaceIndex uint32    `protobuf:"varint,11,opt,name=interface_index,json=interfaceIndex,proto3" json:"interface_index,omitempty"`
+	Direction      Direction `protobuf:"varint,12,opt,name=direction,proto3,enum=candecode.proto.v1.Direction" json:"direction,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RawFrame) Reset() {
+	*x = RawFrame{}
+	mi := &file_pkg_proto_dbc_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RawFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RawFrame) ProtoMessage() {}
+
+func (x *RawFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_dbc_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RawFrame.ProtoReflect.Descriptor instead.
+func (*RawFrame) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RawFrame) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *RawFrame) GetCanId() uint32 {
+	if x != nil {
+		return x.CanId
+	}
+	return 0
+}
+
+func (x *RawFrame) GetIsExtended() bool {
+	if x != nil {
+		return x.IsExtended
+	}
+	return false
+}
+
+func (x *RawFrame) GetIsRemote() bool {
+	if x != nil {
+		return x.IsRemote
+	}
+	return false
+}
+
+func (x *RawFrame) GetIsFd() bool {
+	if x != nil {
+		return x.IsFd
+	}
+	return false
+}
+
+func (x *RawFrame) GetBrs() bool {
+	if x != nil {
+		return x.Brs
+	}
+	return false
+}
+
+func (x *RawFrame) GetEsi() bool {
+	if x != nil {
+		return x.Esi
+	}
+	return false
+}
+
+func (x *RawFrame) GetDlc() uint32 {
+	if x != nil {
+		return x.Dlc
+	}
+	return 0
+}
+
+func (x *RawFrame) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *RawFrame) GetBus() string {
+	if x != nil {
+		return x.Bus
+	}
+	return ""
+}
+
+func (x *RawFrame) GetInterfaceIndex() uint32 {
+	if x != nil {
+		return x.InterfaceIndex
+	}
+	return 0
+}
+
+func (x *RawFrame) GetDirection() Direction {
+	if x != nil {
+		return x.Direction
+	}
+	return Direction_DIRECTION_UNSPECIFIED
+}
+
+// TimingViolation is a deviation of a cyclic message from its DBC cycle time (GenMsgCycleTime),
+// written on /diagnostics/timing.
+type TimingViolation struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp      *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Kind           TimingViolationKind    `protobuf:"varint,2,opt,name=kind,proto3,enum=candecode.proto.v1.TimingViolationKind" json:"kind,omitempty"`
+	Bus            string                 `protobuf:"bytes,3,opt,name=bus,proto3" json:"bus,omitempty"`
+	InterfaceIndex uint32                 `protobuf:"varint,4,opt,name=interface_index,json=interfaceIndex,proto3" json:"interface_index,omitempty"`
+	CanId          uint32                 `protobuf:"varint,5,opt,name=can_id,json=canId,proto3" json:"can_id,omitempty"`
+	IsExtended     bool                   `protobuf:"varint,6,opt,name=is_extended,json=isExtended,proto3" json:"is_extended,omitempty"`
+	Message        string                 `protobuf:"bytes,7,opt,name=message,proto3" json:"message,omitempty"`
+	CycleTimeMs    float64                `protobuf:"fixed64,8,opt,name=cycle_time_ms,json=cycleTimeMs,proto3" json:"cycle_time_ms,omitempty"`
+	// gap_ms is the observed interval; 0 for missing messages.
+	GapMs         float64 `protobuf:"fixed64,9,opt,name=gap_ms,json=gapMs,proto3" json:"gap_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimingViolation) Reset() {
+	*x = TimingViolation{}
+	mi := &file_pkg_proto_dbc_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimingViolation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimingViolation) ProtoMessage() {}
+
+func (x *TimingViolation) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_dbc_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimingViolation.ProtoReflect.Descriptor instead.
+func (*TimingViolation) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TimingViolation) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *TimingViolation) GetKind() TimingViolationKind {
+	if x != nil {
+		return x.Kind
+	}
+	return TimingViolationKind_TIMING_VIOLATION_KIND_UNSPECIFIED
+}
+
+func (x *TimingViolation) GetBus() string {
+	if x != nil {
+		return x.Bus
+	}
+	return ""
+}
+
+func (x *TimingViolation) GetInterfaceIndex() uint32 {
+	if x != nil {
+		return x.InterfaceIndex
+	}
+	return 0
+}
+
+func (x *TimingViolation) GetCanId() uint32 {
+	if x != nil {
+		return x.CanId
+	}
+	return 0
+}
+
+func (x *TimingViolation) GetIsExtended() bool {
+	if x != nil {
+		return x.IsExtended
+	}
+	return false
+}
+
+func (x *TimingViolation) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *TimingViolation) GetCycleTimeMs() float64 {
+	if x != nil {
+		return x.CycleTimeMs
+	}
+	return 0
+}
+
+func (x *TimingViolation) GetGapMs() float64 {
+	if x != nil {
+		return x.GapMs
+	}
+	return 0
+}
+
+// SignalValue is the decoded value of a single signal within a DecodedMessage.
+type SignalValue struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Types that are valid to be assigned to Raw:
+	//
+	//	*SignalValue_RawU
+	//	*SignalValue_RawS
+	//	*SignalValue_RawF
+	//	*SignalValue_RawB
+	//	*SignalValue_RawBytes
+	Raw         isSignalValue_Raw `protobuf_oneof:"raw"`
+	Physical    *float64          `protobuf:"fixed64,7,opt,name=physical,proto3,oneof" json:"physical,omitempty"`
+	Description string            `protobuf:"bytes,8,opt,name=description,proto3" json:"description,omitempty"`
+	Unit        string            `protobuf:"bytes,9,opt,name=unit,proto3" json:"unit,omitempty"`
+	// missing is set for signals past the end of a short frame (lenient length policy); raw is unset.
+	Missing       bool `protobuf:"varint,10,opt,name=missing,proto3" json:"missing,omitempty"`
+	OutOfRange    bool `protobuf:"varint,11,opt,name=out_of_range,json=outOfRange,proto3" json:"out_of_range,omitempty"`
+	UndefinedEnum bool `protobuf:"varint,12,opt,name=undefined_enum,json=undefinedEnum,proto3" json:"undefined_enum,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalValue) Reset() {
+	*x = SignalValue{}
+	mi := &file_pkg_proto_dbc_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalValue) ProtoMessage() {}
+
+func (x *SignalValue) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_dbc_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalValue.ProtoReflect.Descriptor instead.
+func (*SignalValue) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SignalValue) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SignalValue) GetRaw() isSignalValue_Raw {
+	if x != nil {
+		return x.Raw
+	}
+	return nil
+}
+
+func (x *SignalValue) GetRawU() uint64 {
+	if x != nil {
+		if x, ok := x.Raw.(*SignalValue_RawU); ok {
+			return x.RawU
+		}
+	}
+	return 0
+}
+
+func (x *SignalValue) GetRawS() int64 {
+	if x != nil {
+		if x, ok := x.Raw.(*SignalValue_RawS); ok {
+			return x.RawS
+		}
+	}
+	return 0
+}
+
+func (x *SignalValue) GetRawF() float64 {
+	if x != nil {
+		if x, ok := x.Raw.(*SignalValue_RawF); ok {
+			return x.RawF
+		}
+	}
+	return 0
+}
+
+func (x *SignalValue) GetRawB() bool {
+	if x != nil {
+		if x, ok := x.Raw.(*SignalValue_RawB); ok {
+			return x.RawB
+		}
+	}
+	return false
+}
+
+func (x *SignalValue) GetRawBytes() []byte {
+	if x != nil {
+		if x, ok := x.Raw.(*SignalValue_RawBytes); ok {
+			return x.RawBytes
+		}
+	}
+	return nil
+}
+
+func (x *SignalValue) GetPhysical() float64 {
+	if x != nil && x.Physical != nil {
+		return *x.Physical
+	}
+	return 0
+}
+
+func (x *SignalValue) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *SignalValue) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *SignalValue) GetMissing() bool {
+	if x != nil {
+		return x.Missing
+	}
+	return false
+}
+
+func (x *SignalValue) GetOutOfRange() bool {
+	if x != nil {
+		return x.OutOfRange
+	}
+	return false
+}
+
+func (x *SignalValue) GetUndefinedEnum() bool {
+	if x != nil {
+		return x.UndefinedEnum
+	}
+	return false
+}
+
+type isSignalValue_Raw interface {
+	isSignalValue_Raw()
+}
+
+type SignalValue_RawU struct {
+	RawU uint64 `protobuf:"varint,2,opt,name=raw_u,json=rawU,proto3,oneof"`
+}
+
+type SignalValue_RawS struct {
+	RawS int64 `protobuf:"zigzag64,3,opt,name=raw_s,json=rawS,proto3,oneof"`
+}
+
+type SignalValue_RawF struct {
+	RawF float64 `protobuf:"fixed64,4,opt,name=raw_f,json=rawF,proto3,oneof"`
+}
+
+type SignalValue_RawB struct {
+	RawB bool `protobuf:"varint,5,opt,name=raw_b,json=rawB,proto3,oneof"`
+}
+
+type SignalValue_RawBytes struct {
+	RawBytes []byte `protobuf:"bytes,6,opt,name=raw_bytes,json=rawBytes,proto3,oneof"`
+}
+
+func (*SignalValue_RawU) isSignalValue_Raw() {}
+
+func (*SignalValue_RawS) isSignalValue_Raw() {}
+
+func (*SignalValue_RawF) isSignalValue_Raw() {}
+
+func (*SignalValue_RawB) isSignalValue_Raw() {}
+
+func (*SignalValue_RawBytes) isSignalValue_Raw() {}
+
+// SignalSample is the compact, value-only record of a single signal.
+// The static signal definition is stored once in the channel metadata and a MessageDefinition
+// metadata record instead of in every record.
+type SignalSample struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Types that are valid to be assigned to Raw:
+	//
+	//	*SignalSample_RawU
+	//	*SignalSample_RawS
+	//	*SignalSample_RawF
+	//	*SignalSample_RawB
+	//	*SignalSample_RawBytes
+	Raw           isSignalSample_Raw `protobuf_oneof:"raw"`
+	Physical      *float64           `protobuf:"fixed64,7,opt,name=physical,proto3,oneof" json:"physical,omitempty"`
+	Description   string             `protobuf:"bytes,8,opt,name=description,proto3" json:"description,omitempty"`
+	LengthOutcome LengthOutcome      `protobuf:"varint,9,opt,name=length_outcome,json=lengthOutcome,proto3,enum=candecode.proto.v1.LengthOutcome" json:"length_outcome,omitempty"`
+	Validity      *Validity          `protobuf:"bytes,10,opt,name=validity,proto3" json:"validity,omitempty"`
+	OutOfRange    bool               `protobuf:"varint,11,opt,name=out_of_range,json=outOfRange,proto3" json:"out_of_range,omitempty"`
+	UndefinedEnum bool               `protobuf:"varint,12,opt,name=undefined_enum,json=undefinedEnum,proto3" json:"undefined_enum,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DecodedSignal) Reset() {
-	*x = DecodedSignal{}
-	mi := &file_pkg_proto_dbc_proto_msgTypes[0]
+func (x *SignalSample) Reset() {
+	*x = SignalSample{}
+	mi := &file_pkg_proto_dbc_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DecodedSignal) String() string {
+func (x *SignalSample) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DecodedSignal) ProtoMessage() {}
+func (*SignalSample) ProtoMessage() {}
 
-func (x *DecodedSignal) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_dbc_proto_msgTypes[0]
+func (x *SignalSample) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_dbc_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -70,159 +1208,341 @@ func (x *DecodedSignal) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DecodedSignal.ProtoReflect.Descriptor instead.
-func (*DecodedSignal) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{0}
-}
-
-func (x *DecodedSignal) GetMessageName() string {
-	if x != nil {
-		return x.MessageName
-	}
-	return ""
+// Deprecated: Use SignalSample.ProtoReflect.Descriptor instead.
+func (*SignalSample) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *DecodedSignal) GetName() string {
+func (x *SignalSample) GetTimestamp() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Name
+		return x.Timestamp
 	}
-	return ""
+	return nil
 }
 
-func (x *DecodedSignal) GetRaw() isDecodedSignal_Raw {
+func (x *SignalSample) GetRaw() isSignalSample_Raw {
 	if x != nil {
 		return x.Raw
 	}
 	return nil
 }
 
-func (x *DecodedSignal) GetRawU() uint64 {
+func (x *SignalSample) GetRawU() uint64 {
 	if x != nil {
-		if x, ok := x.Raw.(*DecodedSignal_RawU); ok {
+		if x, ok := x.Raw.(*SignalSample_RawU); ok {
 			return x.RawU
 		}
 	}
 	return 0
 }
 
-func (x *DecodedSignal) GetRawS() int64 {
+func (x *SignalSample) GetRawS() int64 {
 	if x != nil {
-		if x, ok := x.Raw.(*DecodedSignal_RawS); ok {
+		if x, ok := x.Raw.(*SignalSample_RawS); ok {
 			return x.RawS
 		}
 	}
 	return 0
 }
 
-func (x *DecodedSignal) GetRawF() float64 {
+func (x *SignalSample) GetRawF() float64 {
 	if x != nil {
-		if x, ok := x.Raw.(*DecodedSignal_RawF); ok {
+		if x, ok := x.Raw.(*SignalSample_RawF); ok {
 			return x.RawF
 		}
 	}
 	return 0
 }
 
-func (x *DecodedSignal) GetRawB() bool {
+func (x *SignalSample) GetRawB() bool {
 	if x != nil {
-		if x, ok := x.Raw.(*DecodedSignal_RawB); ok {
+		if x, ok := x.Raw.(*SignalSample_RawB); ok {
 			return x.RawB
 		}
 	}
 	return false
 }
 
-func (x *DecodedSignal) GetRawBytes() []byte {
+func (x *SignalSample) GetRawBytes() []byte {
 	if x != nil {
-		if x, ok := x.Raw.(*DecodedSignal_RawBytes); ok {
+		if x, ok := x.Raw.(*SignalSample_RawBytes); ok {
 			return x.RawBytes
 		}
 	}
 	return nil
 }
 
-func (x *DecodedSignal) GetPhysical() float64 {
+func (x *SignalSample) GetPhysical() float64 {
 	if x != nil && x.Physical != nil {
 		return *x.Physical
 	}
 	return 0
 }
 
-func (x *DecodedSignal) GetDescription() string {
+func (x *SignalSample) GetDescription() string {
 	if x != nil {
 		return x.Description
 	}
 	return ""
 }
 
-func (x *DecodedSignal) GetSignal() *Signal {
+func (x *SignalSample) GetLengthOutcome() LengthOutcome {
 	if x != nil {
-		return x.Signal
+		return x.LengthOutcome
+	}
+	return LengthOutcome_LENGTH_OUTCOME_MATCH
+}
+
+func (x *SignalSample) GetValidity() *Validity {
+	if x != nil {
+		return x.Validity
 	}
 	return nil
 }
 
-func (x *DecodedSignal) GetTimestamp() *timestamppb.Timestamp {
+func (x *SignalSample) GetOutOfRange() bool {
+	if x != nil {
+		return x.OutOfRange
+	}
+	return false
+}
+
+func (x *SignalSample) GetUndefinedEnum() bool {
+	if x != nil {
+		return x.UndefinedEnum
+	}
+	return false
+}
+
+type isSignalSample_Raw interface {
+	isSignalSample_Raw()
+}
+
+type SignalSample_RawU struct {
+	RawU uint64 `protobuf:"varint,2,opt,name=raw_u,json=rawU,proto3,oneof"`
+}
+
+type SignalSample_RawS struct {
+	RawS int64 `protobuf:"zigzag64,3,opt,name=raw_s,json=rawS,proto3,oneof"`
+}
+
+type SignalSample_RawF struct {
+	RawF float64 `protobuf:"fixed64,4,opt,name=raw_f,json=rawF,proto3,oneof"`
+}
+
+type SignalSample_RawB struct {
+	RawB bool `protobuf:"varint,5,opt,name=raw_b,json=rawB,proto3,oneof"`
+}
+
+type SignalSample_RawBytes struct {
+	RawBytes []byte `protobuf:"bytes,6,opt,name=raw_bytes,json=rawBytes,proto3,oneof"`
+}
+
+func (*SignalSample_RawU) isSignalSample_Raw() {}
+
+func (*SignalSample_RawS) isSignalSample_Raw() {}
+
+func (*SignalSample_RawF) isSignalSample_Raw() {}
+
+func (*SignalSample_RawB) isSignalSample_Raw() {}
+
+func (*SignalSample_RawBytes) isSignalSample_Raw() {}
+
+// MessageSample is the compact record of one CAN frame in per-message mode.
+// Signal units are left empty; they are part of the MessageDefinition metadata record.
+type MessageSample struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Signals       []*SignalValue         `protobuf:"bytes,2,rep,name=signals,proto3" json:"signals,omitempty"`
+	LengthOutcome LengthOutcome          `protobuf:"varint,3,opt,name=length_outcome,json=lengthOutcome,proto3,enum=candecode.proto.v1.LengthOutcome" json:"length_outcome,omitempty"`
+	Validity      *Validity              `protobuf:"bytes,4,opt,name=validity,proto3" json:"validity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageSample) Reset() {
+	*x = MessageSample{}
+	mi := &file_pkg_proto_dbc_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageSample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageSample) ProtoMessage() {}
+
+func (x *MessageSample) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_dbc_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageSample.ProtoReflect.Descriptor instead.
+func (*MessageSample) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MessageSample) GetTimestamp() *timestamppb.Timestamp {
 	if x != nil {
 		return x.Timestamp
 	}
 	return nil
 }
 
-func (x *DecodedSignal) GetCanId() uint32 {
+func (x *MessageSample) GetSignals() []*SignalValue {
 	if x != nil {
-		return x.CanId
+		return x.Signals
 	}
-	return 0
+	return nil
 }
 
-func (x *DecodedSignal) GetIsExtended() bool {
+func (x *MessageSample) GetLengthOutcome() LengthOutcome {
 	if x != nil {
-		return x.IsExtended
+		return x.LengthOutcome
 	}
-	return false
+	return LengthOutcome_LENGTH_OUTCOME_MATCH
 }
 
-func (x *DecodedSignal) GetFrameBytes() []byte {
+func (x *MessageSample) GetValidity() *Validity {
 	if x != nil {
-		return x.FrameBytes
+		return x.Validity
 	}
 	return nil
 }
 
-type isDecodedSignal_Raw interface {
-	isDecodedSignal_Raw()
+// MessageDefinition is the static DBC definition of a message as seen on one bus.
+type MessageDefinition struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	CanId          uint32                 `protobuf:"varint,2,opt,name=can_id,json=canId,proto3" json:"can_id,omitempty"`
+	IsExtended     bool                   `protobuf:"varint,3,opt,name=is_extended,json=isExtended,proto3" json:"is_extended,omitempty"`
+	Length         uint32                 `protobuf:"varint,4,opt,name=length,proto3" json:"length,omitempty"`
+	SenderNode     string                 `protobuf:"bytes,5,opt,name=sender_node,json=senderNode,proto3" json:"sender_node,omitempty"`
+	Description    string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	Bus            string                 `protobuf:"bytes,7,opt,name=bus,proto3" json:"bus,omitempty"`
+	InterfaceIndex uint32                 `protobuf:"varint,8,opt,name=interface_index,json=interfaceIndex,proto3" json:"interface_index,omitempty"`
+	Signals        []*Signal              `protobuf:"bytes,9,rep,name=signals,proto3" json:"signals,omitempty"`
+	SourceFile     string                 `protobuf:"bytes,10,opt,name=source_file,json=sourceFile,proto3" json:"source_file,omitempty"`
+	// attributes are the DBC attribute values (BA_) of the message, defaults (BA_DEF_DEF_) included.
+	Attributes    map[string]string `protobuf:"bytes,11,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-type DecodedSignal_RawU struct {
-	RawU uint64 `protobuf:"varint,3,opt,name=raw_u,json=rawU,proto3,oneof"`
+func (x *MessageDefinition) Reset() {
+	*x = MessageDefinition{}
+	mi := &file_pkg_proto_dbc_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type DecodedSignal_RawS struct {
-	RawS int64 `protobuf:"zigzag64,4,opt,name=raw_s,json=rawS,proto3,oneof"`
+func (x *MessageDefinition) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-type DecodedSignal_RawF struct {
-	RawF float64 `protobuf:"fixed64,5,opt,name=raw_f,json=rawF,proto3,oneof"`
+func (*MessageDefinition) ProtoMessage() {}
+
+func (x *MessageDefinition) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_dbc_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-type DecodedSignal_RawB struct {
-	RawB bool `protobuf:"varint,6,opt,name=raw_b,json=rawB,proto3,oneof"`
+// Deprecated: Use MessageDefinition.ProtoReflect.Descriptor instead.
+func (*MessageDefinition) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{7}
 }
 
-type DecodedSignal_RawBytes struct {
-	RawBytes []byte `protobuf:"bytes,7,opt,name=raw_bytes,json=rawBytes,proto3,oneof"`
+func (x *MessageDefinition) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
 }
 
-func (*DecodedSignal_RawU) isDecodedSignal_Raw() {}
+func (x *MessageDefinition) GetCanId() uint32 {
+	if x != nil {
+		return x.CanId
+	}
+	return 0
+}
 
-func (*DecodedSignal_RawS) isDecodedSignal_Raw() {}
+func (x *MessageDefinition) GetIsExtended() bool {
+	if x != nil {
+		return x.IsExtended
+	}
+	return false
+}
 
-func (*DecodedSignal_RawF) isDecodedSignal_Raw() {}
+func (x *MessageDefinition) GetLength() uint32 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
 
-func (*DecodedSignal_RawB) isDecodedSignal_Raw() {}
+func (x *MessageDefinition) GetSenderNode() string {
+	if x != nil {
+		return x.SenderNode
+	}
+	return ""
+}
 
-func (*DecodedSignal_RawBytes) isDecodedSignal_Raw() {}
+func (x *MessageDefinition) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *MessageDefinition) GetBus() string {
+	if x != nil {
+		return x.Bus
+	}
+	return ""
+}
+
+func (x *MessageDefinition) GetInterfaceIndex() uint32 {
+	if x != nil {
+		return x.InterfaceIndex
+	}
+	return 0
+}
+
+func (x *MessageDefinition) GetSignals() []*Signal {
+	if x != nil {
+		return x.Signals
+	}
+	return nil
+}
+
+func (x *MessageDefinition) GetSourceFile() string {
+	if x != nil {
+		return x.SourceFile
+	}
+	return ""
+}
+
+func (x *MessageDefinition) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
 
 type Signal struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
@@ -245,13 +1565,15 @@ type Signal struct {
 	ReceiverNodes     []string               `protobuf:"bytes,17,rep,name=receiver_nodes,json=receiverNodes,proto3" json:"receiver_nodes,omitempty"`
 	DefaultValue      int32                  `protobuf:"varint,18,opt,name=default_value,json=defaultValue,proto3" json:"default_value,omitempty"`
 	SourceFile        string                 `protobuf:"bytes,19,opt,name=source_file,json=sourceFile,proto3" json:"source_file,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+	// attributes are the DBC attribute values (BA_) of the signal, defaults (BA_DEF_DEF_) included.
+	Attributes    map[string]string `protobuf:"bytes,20,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Signal) Reset() {
 	*x = Signal{}
-	mi := &file_pkg_proto_dbc_proto_msgTypes[1]
+	mi := &file_pkg_proto_dbc_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -263,7 +1585,7 @@ func (x *Signal) String() string {
 func (*Signal) ProtoMessage() {}
 
 func (x *Signal) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_dbc_proto_msgTypes[1]
+	mi := &file_pkg_proto_dbc_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -276,7 +1598,7 @@ func (x *Signal) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Signal.ProtoReflect.Descriptor instead.
 func (*Signal) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{1}
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *Signal) GetName() string {
@@ -412,6 +1734,84 @@ func (x *Signal) GetSourceFile() string {
 	return ""
 }
 
+func (x *Signal) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+// Validity is the result of the checksum and rolling counter checks of a frame.
+type Validity struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// valid is false when the checksum is wrong or the counter skipped or repeated.
+	Valid    bool           `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Checksum ChecksumStatus `protobuf:"varint,2,opt,name=checksum,proto3,enum=candecode.proto.v1.ChecksumStatus" json:"checksum,omitempty"`
+	Counter  CounterStatus  `protobuf:"varint,3,opt,name=counter,proto3,enum=candecode.proto.v1.CounterStatus" json:"counter,omitempty"`
+	// skipped is the number of counter values missing before the frame (COUNTER_STATUS_SKIP).
+	Skipped       uint64 `protobuf:"varint,4,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Validity) Reset() {
+	*x = Validity{}
+	mi := &file_pkg_proto_dbc_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Validity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Validity) ProtoMessage() {}
+
+func (x *Validity) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_dbc_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Validity.ProtoReflect.Descriptor instead.
+func (*Validity) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Validity) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *Validity) GetChecksum() ChecksumStatus {
+	if x != nil {
+		return x.Checksum
+	}
+	return ChecksumStatus_CHECKSUM_STATUS_UNCHECKED
+}
+
+func (x *Validity) GetCounter() CounterStatus {
+	if x != nil {
+		return x.Counter
+	}
+	return CounterStatus_COUNTER_STATUS_UNCHECKED
+}
+
+func (x *Validity) GetSkipped() uint64 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
 type ValueDescription struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Value         int64                  `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
@@ -422,7 +1822,7 @@ type ValueDescription struct {
 
 func (x *ValueDescription) Reset() {
 	*x = ValueDescription{}
-	mi := &file_pkg_proto_dbc_proto_msgTypes[2]
+	mi := &file_pkg_proto_dbc_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -434,7 +1834,7 @@ func (x *ValueDescription) String() string {
 func (*ValueDescription) ProtoMessage() {}
 
 func (x *ValueDescription) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_dbc_proto_msgTypes[2]
+	mi := &file_pkg_proto_dbc_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -447,7 +1847,7 @@ func (x *ValueDescription) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ValueDescription.ProtoReflect.Descriptor instead.
 func (*ValueDescription) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{2}
+	return file_pkg_proto_dbc_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *ValueDescription) GetValue() int64 {
@@ -468,7 +1868,7 @@ var File_pkg_proto_dbc_proto protoreflect.FileDescriptor
 
 const file_pkg_proto_dbc_proto_rawDesc = "" +
 	"\n" +
-	"\x13pkg/proto/dbc.proto\x12\x12candecode.proto.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xdf\x03\n" +
+	"\x13pkg/proto/dbc.proto\x12\x12candecode.proto.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xdd\x06\n" +
 	"\rDecodedSignal\x12!\n" +
 	"\fmessage_name\x18\x01 \x01(\tR\vmessageName\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x15\n" +
@@ -486,9 +1886,124 @@ const file_pkg_proto_dbc_proto_rawDesc = "" +
 	"\vis_extended\x18\r \x01(\bR\n" +
 	"isExtended\x12\x1f\n" +
 	"\vframe_bytes\x18\x0e \x01(\fR\n" +
-	"frameBytesB\x05\n" +
+	"frameBytes\x12\x13\n" +
+	"\x05is_fd\x18\x0f \x01(\bR\x04isFd\x12\x10\n" +
+	"\x03brs\x18\x10 \x01(\bR\x03brs\x12\x10\n" +
+	"\x03esi\x18\x11 \x01(\bR\x03esi\x12\x10\n" +
+	"\x03bus\x18\x12 \x01(\tR\x03bus\x12'\n" +
+	"\x0finterface_index\x18\x13 \x01(\rR\x0einterfaceIndex\x12H\n" +
+	"\x0elength_outcome\x18\x14 \x01(\x0e2!.candecode.proto.v1.LengthOutcomeR\rlengthOutcome\x128\n" +
+	"\bvalidity\x18\x15 \x01(\v2\x1c.candecode.proto.v1.ValidityR\bvalidity\x12 \n" +
+	"\fout_of_range\x18\x16 \x01(\bR\n" +
+	"outOfRange\x12%\n" +
+	"\x0eundefined_enum\x18\x17 \x01(\bR\rundefinedEnum\x12;\n" +
+	"\tdirection\x18\x18 \x01(\x0e2\x1d.candecode.proto.v1.DirectionR\tdirectionB\x05\n" +
+	"\x03rawB\v\n" +
+	"\t_physical\"\xa7\x04\n" +
+	"\x0eDecodedMessage\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x15\n" +
+	"\x06can_id\x18\x03 \x01(\rR\x05canId\x12\x1f\n" +
+	"\vis_extended\x18\x04 \x01(\bR\n" +
+	"isExtended\x12\x1f\n" +
+	"\vframe_bytes\x18\x05 \x01(\fR\n" +
+	"frameBytes\x12\x13\n" +
+	"\x05is_fd\x18\x06 \x01(\bR\x04isFd\x12\x10\n" +
+	"\x03brs\x18\a \x01(\bR\x03brs\x12\x10\n" +
+	"\x03esi\x18\b \x01(\bR\x03esi\x12\x10\n" +
+	"\x03bus\x18\t \x01(\tR\x03bus\x12'\n" +
+	"\x0finterface_index\x18\n" +
+	" \x01(\rR\x0einterfaceIndex\x129\n" +
+	"\asignals\x18\v \x03(\v2\x1f.candecode.proto.v1.SignalValueR\asignals\x12H\n" +
+	"\x0elength_outcome\x18\f \x01(\x0e2!.candecode.proto.v1.LengthOutcomeR\rlengthOutcome\x128\n" +
+	"\bvalidity\x18\r \x01(\v2\x1c.candecode.proto.v1.ValidityR\bvalidity\x12;\n" +
+	"\tdirection\x18\x0e \x01(\x0e2\x1d.candecode.proto.v1.DirectionR\tdirection\"\xf0\x02\n" +
+	"\bRawFrame\x128\n" +
+	"\ttimestamp\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x15\n" +
+	"\x06can_id\x18\x02 \x01(\rR\x05canId\x12\x1f\n" +
+	"\vis_extended\x18\x03 \x01(\bR\n" +
+	"isExtended\x12\x1b\n" +
+	"\tis_remote\x18\x04 \x01(\bR\bisRemote\x12\x13\n" +
+	"\x05is_fd\x18\x05 \x01(\bR\x04isFd\x12\x10\n" +
+	"\x03brs\x18\x06 \x01(\bR\x03brs\x12\x10\n" +
+	"\x03esi\x18\a \x01(\bR\x03esi\x12\x10\n" +
+	"\x03dlc\x18\b \x01(\rR\x03dlc\x12\x12\n" +
+	"\x04data\x18\t \x01(\fR\x04data\x12\x10\n" +
+	"\x03bus\x18\n" +
+	" \x01(\tR\x03bus\x12'\n" +
+	"\x0finterface_index\x18\v \x01(\rR\x0einterfaceIndex\x12;\n" +
+	"\tdirection\x18\f \x01(\x0e2\x1d.candecode.proto.v1.DirectionR\tdirection\"\xd0\x02\n" +
+	"\x0fTimingViolation\x128\n" +
+	"\ttimestamp\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12;\n" +
+	"\x04kind\x18\x02 \x01(\x0e2'.candecode.proto.v1.TimingViolationKindR\x04kind\x12\x10\n" +
+	"\x03bus\x18\x03 \x01(\tR\x03bus\x12'\n" +
+	"\x0finterface_index\x18\x04 \x01(\rR\x0einterfaceIndex\x12\x15\n" +
+	"\x06can_id\x18\x05 \x01(\rR\x05canId\x12\x1f\n" +
+	"\vis_extended\x18\x06 \x01(\bR\n" +
+	"isExtended\x12\x18\n" +
+	"\amessage\x18\a \x01(\tR\amessage\x12\"\n" +
+	"\rcycle_time_ms\x18\b \x01(\x01R\vcycleTimeMs\x12\x15\n" +
+	"\x06gap_ms\x18\t \x01(\x01R\x05gapMs\"\xea\x02\n" +
+	"\vSignalValue\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x15\n" +
+	"\x05raw_u\x18\x02 \x01(\x04H\x00R\x04rawU\x12\x15\n" +
+	"\x05raw_s\x18\x03 \x01(\x12H\x00R\x04rawS\x12\x15\n" +
+	"\x05raw_f\x18\x04 \x01(\x01H\x00R\x04rawF\x12\x15\n" +
+	"\x05raw_b\x18\x05 \x01(\bH\x00R\x04rawB\x12\x1d\n" +
+	"\traw_bytes\x18\x06 \x01(\fH\x00R\brawBytes\x12\x1f\n" +
+	"\bphysical\x18\a \x01(\x01H\x01R\bphysical\x88\x01\x01\x12 \n" +
+	"\vdescription\x18\b \x01(\tR\vdescription\x12\x12\n" +
+	"\x04unit\x18\t \x01(\tR\x04unit\x12\x18\n" +
+	"\amissing\x18\n" +
+	" \x01(\bR\amissing\x12 \n" +
+	"\fout_of_range\x18\v \x01(\bR\n" +
+	"outOfRange\x12%\n" +
+	"\x0eundefined_enum\x18\f \x01(\bR\rundefinedEnumB\x05\n" +
+	"\x03rawB\v\n" +
+	"\t_physical\"\xe7\x03\n" +
+	"\fSignalSample\x128\n" +
+	"\ttimestamp\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x15\n" +
+	"\x05raw_u\x18\x02 \x01(\x04H\x00R\x04rawU\x12\x15\n" +
+	"\x05raw_s\x18\x03 \x01(\x12H\x00R\x04rawS\x12\x15\n" +
+	"\x05raw_f\x18\x04 \x01(\x01H\x00R\x04rawF\x12\x15\n" +
+	"\x05raw_b\x18\x05 \x01(\bH\x00R\x04rawB\x12\x1d\n" +
+	"\traw_bytes\x18\x06 \x01(\fH\x00R\brawBytes\x12\x1f\n" +
+	"\bphysical\x18\a \x01(\x01H\x01R\bphysical\x88\x01\x01\x12 \n" +
+	"\vdescription\x18\b \x01(\tR\vdescription\x12H\n" +
+	"\x0elength_outcome\x18\t \x01(\x0e2!.candecode.proto.v1.LengthOutcomeR\rlengthOutcome\x128\n" +
+	"\bvalidity\x18\n" +
+	" \x01(\v2\x1c.candecode.proto.v1.ValidityR\bvalidity\x12 \n" +
+	"\fout_of_range\x18\v \x01(\bR\n" +
+	"outOfRange\x12%\n" +
+	"\x0eundefined_enum\x18\f \x01(\bR\rundefinedEnumB\x05\n" +
 	"\x03rawB\v\n" +
-	"\t_physical\"\xeb\x04\n" +
+	"\t_physical\"\x88\x02\n" +
+	"\rMessageSample\x128\n" +
+	"\ttimestamp\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x129\n" +
+	"\asignals\x18\x02 \x03(\v2\x1f.candecode.proto.v1.SignalValueR\asignals\x12H\n" +
+	"\x0elength_outcome\x18\x03 \x01(\x0e2!.candecode.proto.v1.LengthOutcomeR\rlengthOutcome\x128\n" +
+	"\bvalidity\x18\x04 \x01(\v2\x1c.candecode.proto.v1.ValidityR\bvalidity\"\xe2\x03\n" +
+	"\x11MessageDefinition\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x15\n" +
+	"\x06can_id\x18\x02 \x01(\rR\x05canId\x12\x1f\n" +
+	"\vis_extended\x18\x03 \x01(\bR\n" +
+	"isExtended\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\rR\x06length\x12\x1f\n" +
+	"\vsender_node\x18\x05 \x01(\tR\n" +
+	"senderNode\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\x12\x10\n" +
+	"\x03bus\x18\a \x01(\tR\x03bus\x12'\n" +
+	"\x0finterface_index\x18\b \x01(\rR\x0einterfaceIndex\x124\n" +
+	"\asignals\x18\t \x03(\v2\x1a.candecode.proto.v1.SignalR\asignals\x12\x1f\n" +
+	"\vsource_file\x18\n" +
+	" \x01(\tR\n" +
+	"sourceFile\x12U\n" +
+	"\n" +
+	"attributes\x18\v \x03(\v25.candecode.proto.v1.MessageDefinition.AttributesEntryR\n" +
+	"attributes\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xf6\x05\n" +
 	"\x06Signal\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
 	"\x05start\x18\x02 \x01(\rR\x05start\x12\x16\n" +
@@ -510,10 +2025,44 @@ const file_pkg_proto_dbc_proto_rawDesc = "" +
 	"\x0ereceiver_nodes\x18\x11 \x03(\tR\rreceiverNodes\x12#\n" +
 	"\rdefault_value\x18\x12 \x01(\x05R\fdefaultValue\x12\x1f\n" +
 	"\vsource_file\x18\x13 \x01(\tR\n" +
-	"sourceFile\"J\n" +
+	"sourceFile\x12J\n" +
+	"\n" +
+	"attributes\x18\x14 \x03(\v2*.candecode.proto.v1.Signal.AttributesEntryR\n" +
+	"attributes\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb7\x01\n" +
+	"\bValidity\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12>\n" +
+	"\bchecksum\x18\x02 \x01(\x0e2\".candecode.proto.v1.ChecksumStatusR\bchecksum\x12;\n" +
+	"\acounter\x18\x03 \x01(\x0e2!.candecode.proto.v1.CounterStatusR\acounter\x12\x18\n" +
+	"\askipped\x18\x04 \x01(\x04R\askipped\"J\n" +
 	"\x10ValueDescription\x12\x14\n" +
 	"\x05value\x18\x01 \x01(\x03R\x05value\x12 \n" +
-	"\vdescription\x18\x02 \x01(\tR\vdescriptionB.Z,github.com/BIwashi/candecode/pkg/proto;protob\x06proto3"
+	"\vdescription\x18\x02 \x01(\tR\vdescription*\xa4\x01\n" +
+	"\x13TimingViolationKind\x12%\n" +
+	"!TIMING_VIOLATION_KIND_UNSPECIFIED\x10\x00\x12!\n" +
+	"\x1dTIMING_VIOLATION_KIND_TIMEOUT\x10\x01\x12 \n" +
+	"\x1cTIMING_VIOLATION_KIND_JITTER\x10\x02\x12!\n" +
+	"\x1dTIMING_VIOLATION_KIND_MISSING\x10\x03*~\n" +
+	"\rLengthOutcome\x12\x18\n" +
+	"\x14LENGTH_OUTCOME_MATCH\x10\x00\x12\x1c\n" +
+	"\x18LENGTH_OUTCOME_TRUNCATED\x10\x01\x12\x19\n" +
+	"\x15LENGTH_OUTCOME_PADDED\x10\x02\x12\x1a\n" +
+	"\x16LENGTH_OUTCOME_IGNORED\x10\x03*g\n" +
+	"\x0eChecksumStatus\x12\x1d\n" +
+	"\x19CHECKSUM_STATUS_UNCHECKED\x10\x00\x12\x19\n" +
+	"\x15CHECKSUM_STATUS_VALID\x10\x01\x12\x1b\n" +
+	"\x17CHECKSUM_STATUS_INVALID\x10\x02*x\n" +
+	"\rCounterStatus\x12\x1c\n" +
+	"\x18COUNTER_STATUS_UNCHECKED\x10\x00\x12\x15\n" +
+	"\x11COUNTER_STATUS_OK\x10\x01\x12\x17\n" +
+	"\x13COUNTER_STATUS_SKIP\x10\x02\x12\x19\n" +
+	"\x15COUNTER_STATUS_REPEAT\x10\x03*J\n" +
+	"\tDirection\x12\x19\n" +
+	"\x15DIRECTION_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fDIRECTION_RX\x10\x01\x12\x10\n" +
+	"\fDIRECTION_TX\x10\x02B.Z,github.com/BIwashi/candecode/pkg/proto;protob\x06proto3"
 
 var (
 	file_pkg_proto_dbc_proto_rawDescOnce sync.Once
@@ -527,22 +2076,62 @@ func file_pkg_proto_dbc_proto_rawDescGZIP() []byte {
 	return file_pkg_proto_dbc_proto_rawDescData
 }
 
-var file_pkg_proto_dbc_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_pkg_proto_dbc_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_pkg_proto_dbc_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_pkg_proto_dbc_proto_goTypes = []any{
-	(*DecodedSignal)(nil),         // 0: candecode.proto.v1.DecodedSignal
-	(*Signal)(nil),                // 1: candecode.proto.v1.Signal
-	(*ValueDescription)(nil),      // 2: candecode.proto.v1.ValueDescription
-	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+	(TimingViolationKind)(0),      // 0: candecode.proto.v1.TimingViolationKind
+	(LengthOutcome)(0),            // 1: candecode.proto.v1.LengthOutcome
+	(ChecksumStatus)(0),           // 2: candecode.proto.v1.ChecksumStatus
+	(CounterStatus)(0),            // 3: candecode.proto.v1.CounterStatus
+	(Direction)(0),                // 4: candecode.proto.v1.Direction
+	(*DecodedSignal)(nil),         // 5: candecode.proto.v1.DecodedSignal
+	(*DecodedMessage)(nil),        // 6: candecode.proto.v1.DecodedMessage
+	(*RawFrame)(nil),              // 7: candecode.proto.v1.RawFrame
+	(*TimingViolation)(nil),       // 8: candecode.proto.v1.TimingViolation
+	(*SignalValue)(nil),           // 9: candecode.proto.v1.SignalValue
+	(*SignalSample)(nil),          // 10: candecode.proto.v1.SignalSample
+	(*MessageSample)(nil),         // 11: candecode.proto.v1.MessageSample
+	(*MessageDefinition)(nil),     // 12: candecode.proto.v1.MessageDefinition
+	(*Signal)(nil),                // 13: candecode.proto.v1.Signal
+	(*Validity)(nil),              // 14: candecode.proto.v1.Validity
+	(*ValueDescription)(nil),      // 15: candecode.proto.v1.ValueDescription
+	nil,                           // 16: candecode.proto.v1.MessageDefinition.AttributesEntry
+	nil,                           // 17: candecode.proto.v1.Signal.AttributesEntry
+	(*timestamppb.Timestamp)(nil), // 18: google.protobuf.Timestamp
 }
 var file_pkg_proto_dbc_proto_depIdxs = []int32{
-	1, // 0: candecode.proto.v1.DecodedSignal.signal:type_name -> candecode.proto.v1.Signal
-	3, // 1: candecode.proto.v1.DecodedSignal.timestamp:type_name -> google.protobuf.Timestamp
-	2, // 2: candecode.proto.v1.Signal.value_descriptions:type_name -> candecode.proto.v1.ValueDescription
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	13, // 0: candecode.proto.v1.DecodedSignal.signal:type_name -> candecode.proto.v1.Signal
+	18, // 1: candecode.proto.v1.DecodedSignal.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 2: candecode.proto.v1.DecodedSignal.length_outcome:type_name -> candecode.proto.v1.LengthOutcome
+	14, // 3: candecode.proto.v1.DecodedSignal.validity:type_name -> candecode.proto.v1.Validity
+	4,  // 4: candecode.proto.v1.DecodedSignal.direction:type_name -> candecode.proto.v1.Direction
+	18, // 5: candecode.proto.v1.DecodedMessage.timestamp:type_name -> google.protobuf.Timestamp
+	9,  // 6: candecode.proto.v1.DecodedMessage.signals:type_name -> candecode.proto.v1.SignalValue
+	1,  // 7: candecode.proto.v1.DecodedMessage.length_outcome:type_name -> candecode.proto.v1.LengthOutcome
+	14, // 8: candecode.proto.v1.DecodedMessage.validity:type_name -> candecode.proto.v1.Validity
+	4,  // 9: candecode.proto.v1.DecodedMessage.direction:type_name -> candecode.proto.v1.Direction
+	18, // 10: candecode.proto.v1.RawFrame.timestamp:type_name -> google.protobuf.Timestamp
+	4,  // 11: candecode.proto.v1.RawFrame.direction:type_name -> candecode.proto.v1.Direction
+	18, // 12: candecode.proto.v1.TimingViolation.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 13: candecode.proto.v1.TimingViolation.kind:type_name -> candecode.proto.v1.TimingViolationKind
+	18, // 14: candecode.proto.v1.SignalSample.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 15: candecode.proto.v1.SignalSample.length_outcome:type_name -> candecode.proto.v1.LengthOutcome
+	14, // 16: candecode.proto.v1.SignalSample.validity:type_name -> candecode.proto.v1.Validity
+	18, // 17: candecode.proto.v1.MessageSample.timestamp:type_name -> google.protobuf.Timestamp
+	9,  // 18: candecode.proto.v1.MessageSample.signals:type_name -> candecode.proto.v1.SignalValue
+	1,  // 19: candecode.proto.v1.MessageSample.length_outcome:type_name -> candecode.proto.v1.LengthOutcome
+	14, // 20: candecode.proto.v1.MessageSample.validity:type_name -> candecode.proto.v1.Validity
+	13, // 21: candecode.proto.v1.MessageDefinition.signals:type_name -> candecode.proto.v1.Signal
+	16, // 22: candecode.proto.v1.MessageDefinition.attributes:type_name -> candecode.proto.v1.MessageDefinition.AttributesEntry
+	15, // 23: candecode.proto.v1.Signal.value_descriptions:type_name -> candecode.proto.v1.ValueDescription
+	17, // 24: candecode.proto.v1.Signal.attributes:type_name -> candecode.proto.v1.Signal.AttributesEntry
+	2,  // 25: candecode.proto.v1.Validity.checksum:type_name -> candecode.proto.v1.ChecksumStatus
+	3,  // 26: candecode.proto.v1.Validity.counter:type_name -> candecode.proto.v1.CounterStatus
+	27, // [27:27] is the sub-list for method output_type
+	27, // [27:27] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
 }
 
 func init() { file_pkg_proto_dbc_proto_init() }
@@ -557,18 +2146,33 @@ func file_pkg_proto_dbc_proto_init() {
 		(*DecodedSignal_RawB)(nil),
 		(*DecodedSignal_RawBytes)(nil),
 	}
+	file_pkg_proto_dbc_proto_msgTypes[4].OneofWrappers = []any{
+		(*SignalValue_RawU)(nil),
+		(*SignalValue_RawS)(nil),
+		(*SignalValue_RawF)(nil),
+		(*SignalValue_RawB)(nil),
+		(*SignalValue_RawBytes)(nil),
+	}
+	file_pkg_proto_dbc_proto_msgTypes[5].OneofWrappers = []any{
+		(*SignalSample_RawU)(nil),
+		(*SignalSample_RawS)(nil),
+		(*SignalSample_RawF)(nil),
+		(*SignalSample_RawB)(nil),
+		(*SignalSample_RawBytes)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_proto_dbc_proto_rawDesc), len(file_pkg_proto_dbc_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      5,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_pkg_proto_dbc_proto_goTypes,
 		DependencyIndexes: file_pkg_proto_dbc_proto_depIdxs,
+		EnumInfos:         file_pkg_proto_dbc_proto_enumTypes,
 		MessageInfos:      file_pkg_proto_dbc_proto_msgTypes,
 	}.Build()
 	File_pkg_proto_dbc_proto = out.File
@@ -0,0 +1,319 @@
+package timing
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"go.einride.tech/can/pkg/descriptor"
+
+	"github.com/BIwashi/candecode/pkg/can"
+	"github.com/BIwashi/candecode/pkg/dbc"
+)
+
+// Kind classifies a Violation. The values mirror candecode.proto.v1.TimingViolationKind.
+type Kind int
+
+const (
+	// KindTimeout is a gap between two frames (or after the last frame) longer than
+	// the timeout factor times the cycle time.
+	KindTimeout Kind = iota + 1
+	// KindJitter is an interval that deviates from the cycle time by more than the jitter tolerance.
+	KindJitter
+	// KindMissing is a cyclic message that never appears on a bus mapped to its DBC
+	// (see Checker).
+	KindMissing
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindTimeout:
+		return "timeout"
+	case KindJitter:
+		return "jitter"
+	case KindMissing:
+		return "missing"
+	default:
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+}
+
+// Violation is a deviation of a message from its DBC cycle time (GenMsgCycleTime).
+type Violation struct {
+	Kind           Kind
+	Bus            string
+	InterfaceIndex int
+	Message        *descriptor.Message
+	// Timestamp is the capture time the violation was detected at: the frame ending the gap,
+	// the end of the capture for trailing timeouts and the first frame of the bus for missing messages.
+	Timestamp time.Time
+	// Gap is the observed interval; zero for missing messages.
+	Gap time.Duration
+}
+
+// MessageStats summarizes the intervals of one cyclic message on one bus.
+type MessageStats struct {
+	Bus            string
+	InterfaceIndex int
+	Message        *descriptor.Message
+	Count          uint64
+	MinGap         time.Duration
+	MaxGap         time.Duration
+	MeanGap        time.Duration
+	Timeouts       uint64
+	JitterOutliers uint64
+}
+
+type Option interface {
+	apply(*Checker)
+}
+
+type timeoutFactorOption float64
+
+func (o timeoutFactorOption) apply(c *Checker) {
+	c.timeoutFactor = float64(o)
+}
+
+// WithTimeoutFactor reports gaps longer than factor times the cycle time as timeouts (3 by default).
+func WithTimeoutFactor(factor float64) Option {
+	return timeoutFactorOption(factor)
+}
+
+type jitterToleranceOption float64
+
+func (o jitterToleranceOption) apply(c *Checker) {
+	c.jitterTolerance = float64(o)
+}
+
+// WithJitterTolerance reports intervals deviating from the cycle time by more than tolerance
+// times the cycle time as jitter outliers (0.5 by default).
+func WithJitterTolerance(tolerance float64) Option {
+	return jitterToleranceOption(tolerance)
+}
+
+// Checker compares the observed inter-arrival times of cyclic DBC messages with their cycle time.
+//
+// Frames are fed in capture order with Observe. Messages are checked when their DBC cycle time is
+// set and their send type is not event driven. Every cyclic message of the DBC of a bus is expected
+// when the bus is mapped to that DBC explicitly (dbc.WithBusCompiler) or has frames of it. A default
+// DBC shared by all buses is not expected in full on buses that only carry other traffic.
+type Checker struct {
+	decoder         *dbc.Decoder
+	timeoutFactor   float64
+	jitterTolerance float64
+	buses           map[string]*busState
+	busOrder        []string
+}
+
+type busState struct {
+	interfaceIndex int
+	start          time.Time
+	end            time.Time
+	expected       []*descriptor.Message
+	tracks         map[*descriptor.Message]*track
+	// dbcBus is set when the bus is mapped to its DBC explicitly or a frame of the DBC was seen,
+	// so messages that never appear are reported missing.
+	dbcBus bool
+}
+
+type track struct {
+	stats   MessageStats
+	last    time.Time
+	gapSum  time.Duration
+	gapSeen uint64
+}
+
+// NewChecker creates a checker resolving the DBC messages of each bus with decoder.
+func NewChecker(decoder *dbc.Decoder, opts ...Option) *Checker {
+	c := &Checker{
+		decoder:         decoder,
+		timeoutFactor:   3,
+		jitterTolerance: 0.5,
+		buses:           make(map[string]*busState),
+	}
+	for _, o := range opts {
+		o.apply(c)
+	}
+	return c
+}
+
+// IsCyclic reports whether m is checked: it has a cycle time and is not event driven.
+func IsCyclic(m *descriptor.Message) bool {
+	return m.CycleTime > 0 && m.SendType != descriptor.SendTypeEvent
+}
+
+// Observe accounts frame and returns the timeout and jitter violations ending at it.
+// Remote and error frames are not transmissions of the message and are ignored.
+func (c *Checker) Observe(frame *can.TimedFrame) []Violation {
+	if frame.IsRemote || frame.IsError {
+		return nil
+	}
+
+	bus := frame.Interface
+	b, ok := c.buses[bus]
+	if !ok {
+		b = &busState{
+			interfaceIndex: frame.InterfaceIndex,
+			tracks:         make(map[*descriptor.Message]*track),
+			dbcBus:         c.decoder.HasBusCompiler(bus),
+		}
+		if compiler, ok := c.decoder.Compiler(bus); ok {
+			for _, m := range compiler.Messages() {
+				if IsCyclic(m) {
+					b.expected = append(b.expected, m)
+				}
+			}
+		}
+		c.buses[bus] = b
+		c.busOrder = append(c.busOrder, bus)
+	}
+	ts := frame.Timestamp
+	if b.start.IsZero() || ts.Before(b.start) {
+		b.start = ts
+	}
+	if ts.After(b.end) {
+		b.end = ts
+	}
+
+	m, ok := c.message(frame)
+	if !ok {
+		return nil
+	}
+	b.dbcBus = true
+	if !IsCyclic(m) {
+		return nil
+	}
+
+	t, ok := b.tracks[m]
+	if !ok {
+		t = &track{stats: MessageStats{Bus: bus, InterfaceIndex: b.interfaceIndex, Message: m}}
+		b.tracks[m] = t
+	}
+	t.stats.Count++
+	if t.stats.Count == 1 {
+		t.last = ts
+		return nil
+	}
+
+	gap := ts.Sub(t.last)
+	t.last = ts
+	t.addGap(gap)
+
+	if v, ok := c.classify(gap, m.CycleTime); ok {
+		t.count(v)
+		return []Violation{{
+			Kind:           v,
+			Bus:            bus,
+			InterfaceIndex: b.interfaceIndex,
+			Message:        m,
+			Timestamp:      ts,
+			Gap:            gap,
+		}}
+	}
+	return nil
+}
+
+// Finish reports the trailing timeouts (no frame until the end of the capture of the bus) and the
+// missing messages. It must be called once after the last Observe.
+func (c *Checker) Finish() []Violation {
+	var violations []Violation
+	for _, bus := range c.busOrder {
+		b := c.buses[bus]
+		for _, m := range b.expected {
+			t, ok := b.tracks[m]
+			if !ok {
+				if !b.dbcBus {
+					continue
+				}
+				violations = append(violations, Violation{
+					Kind:           KindMissing,
+					Bus:            bus,
+					InterfaceIndex: b.interfaceIndex,
+					Message:        m,
+					Timestamp:      b.start,
+				})
+				b.tracks[m] = &track{stats: MessageStats{Bus: bus, InterfaceIndex: b.interfaceIndex, Message: m}}
+				continue
+			}
+			if gap := b.end.Sub(t.last); gap > c.timeout(m.CycleTime) {
+				t.count(KindTimeout)
+				violations = append(violations, Violation{
+					Kind:           KindTimeout,
+					Bus:            bus,
+					InterfaceIndex: b.interfaceIndex,
+					Message:        m,
+					Timestamp:      b.end,
+					Gap:            gap,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// Stats returns the interval statistics of every checked message, ordered by bus and CAN ID.
+func (c *Checker) Stats() []MessageStats {
+	var stats []MessageStats
+	for _, bus := range c.busOrder {
+		for _, t := range c.buses[bus].tracks {
+			stats = append(stats, t.stats)
+		}
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Bus != stats[j].Bus {
+			return stats[i].Bus < stats[j].Bus
+		}
+		return stats[i].Message.ID < stats[j].Message.ID
+	})
+	return stats
+}
+
+// message resolves the DBC message of frame on its bus.
+func (c *Checker) message(frame *can.TimedFrame) (*descriptor.Message, bool) {
+	compiler, ok := c.decoder.Compiler(frame.Interface)
+	if !ok {
+		return nil, false
+	}
+	m, ok := compiler.Message(frame.ID)
+	if !ok || m.IsExtended != frame.IsExtended {
+		return nil, false
+	}
+	return m, true
+}
+
+func (c *Checker) timeout(cycle time.Duration) time.Duration {
+	return time.Duration(float64(cycle) * c.timeoutFactor)
+}
+
+// classify returns the violation kind of an interval, if any.
+func (c *Checker) classify(gap, cycle time.Duration) (Kind, bool) {
+	if gap > c.timeout(cycle) {
+		return KindTimeout, true
+	}
+	if math.Abs(float64(gap-cycle)) > float64(cycle)*c.jitterTolerance {
+		return KindJitter, true
+	}
+	return 0, false
+}
+
+func (t *track) addGap(gap time.Duration) {
+	if t.gapSeen == 0 || gap < t.stats.MinGap {
+		t.stats.MinGap = gap
+	}
+	if gap > t.stats.MaxGap {
+		t.stats.MaxGap = gap
+	}
+	t.gapSeen++
+	t.gapSum += gap
+	t.stats.MeanGap = t.gapSum / time.Duration(t.gapSeen)
+}
+
+func (t *track) count(kind Kind) {
+	switch kind {
+	case KindTimeout:
+		t.stats.Timeouts++
+	case KindJitter:
+		t.stats.JitterOutliers++
+	}
+}
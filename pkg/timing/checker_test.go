@@ -0,0 +1,227 @@
+package timing
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/BIwashi/candecode/pkg/can"
+	"github.com/BIwashi/candecode/pkg/dbc"
+)
+
+// testDBC has two cyclic messages (ENGINE, BRAKE) and an event driven one (DOOR), all 100 ms.
+const testDBC = `VERSION ""
+
+BU_: ECU
+
+BO_ 256 ENGINE: 8 ECU
+ SG_ RPM : 0|16@1+ (1,0) [0|65535] "rpm" Vector__XXX
+
+BO_ 512 BRAKE: 8 ECU
+ SG_ PRESSURE : 0|8@1+ (1,0) [0|255] "bar" Vector__XXX
+
+BO_ 768 DOOR: 8 ECU
+ SG_ OPEN : 0|1@1+ (1,0) [0|1] "" Vector__XXX
+
+BA_DEF_ BO_ "GenMsgCycleTime" INT 0 65535;
+BA_DEF_ BO_ "GenMsgSendType" ENUM "Cyclic","Event";
+BA_DEF_DEF_ "GenMsgCycleTime" 0;
+BA_DEF_DEF_ "GenMsgSendType" "Cyclic";
+BA_ "GenMsgCycleTime" BO_ 256 100;
+BA_ "GenMsgCycleTime" BO_ 512 100;
+BA_ "GenMsgCycleTime" BO_ 768 100;
+BA_ "GenMsgSendType" BO_ 768 1;
+`
+
+const (
+	engineID  = 0x100
+	brakeID   = 0x200
+	doorID    = 0x300
+	unknownID = 0x7FF
+)
+
+type testFrame struct {
+	bus    string
+	id     uint32
+	at     int // ms since the start of the capture
+	remote bool
+}
+
+// every returns frames of id on bus at each of the times (ms).
+func every(bus string, id uint32, at ...int) []testFrame {
+	frames := make([]testFrame, 0, len(at))
+	for _, ms := range at {
+		frames = append(frames, testFrame{bus: bus, id: id, at: ms})
+	}
+	return frames
+}
+
+type testViolation struct {
+	kind    Kind
+	bus     string
+	message string
+	at      time.Duration
+	gap     time.Duration
+}
+
+func TestChecker(t *testing.T) {
+	dbcFile := filepath.Join(t.TempDir(), "test.dbc")
+	if err := os.WriteFile(dbcFile, []byte(testDBC), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		defaultFile string
+		mappings    []string
+		opts        []Option
+		frames      [][]testFrame
+		wantObserve []testViolation
+		wantFinish  []testViolation
+	}{
+		{
+			name:        "regular",
+			defaultFile: dbcFile,
+			frames: [][]testFrame{
+				every("can0", engineID, 0, 100, 200, 300),
+				every("can0", brakeID, 10, 110, 210, 310),
+				// event driven, not checked
+				every("can0", doorID, 0, 290),
+			},
+		},
+		{
+			name:        "timeout",
+			defaultFile: dbcFile,
+			frames: [][]testFrame{
+				every("can0", engineID, 0, 100, 500),
+				every("can0", brakeID, 0, 100, 200, 300, 400, 500),
+			},
+			wantObserve: []testViolation{
+				{kind: KindTimeout, bus: "can0", message: "ENGINE", at: 500 * time.Millisecond, gap: 400 * time.Millisecond},
+			},
+		},
+		{
+			name:        "jitter",
+			defaultFile: dbcFile,
+			frames: [][]testFrame{
+				every("can0", engineID, 0, 100, 260, 300),
+				every("can0", brakeID, 0, 100, 200, 300),
+			},
+			wantObserve: []testViolation{
+				{kind: KindJitter, bus: "can0", message: "ENGINE", at: 260 * time.Millisecond, gap: 160 * time.Millisecond},
+				{kind: KindJitter, bus: "can0", message: "ENGINE", at: 300 * time.Millisecond, gap: 40 * time.Millisecond},
+			},
+		},
+		{
+			name:        "jitter tolerance and timeout factor",
+			defaultFile: dbcFile,
+			opts:        []Option{WithJitterTolerance(0.2), WithTimeoutFactor(1.5)},
+			frames: [][]testFrame{
+				every("can0", engineID, 0, 130, 290),
+				every("can0", brakeID, 0, 100, 200, 290),
+			},
+			wantObserve: []testViolation{
+				{kind: KindJitter, bus: "can0", message: "ENGINE", at: 130 * time.Millisecond, gap: 130 * time.Millisecond},
+				{kind: KindTimeout, bus: "can0", message: "ENGINE", at: 290 * time.Millisecond, gap: 160 * time.Millisecond},
+			},
+		},
+		{
+			name:        "remote frames are ignored",
+			defaultFile: dbcFile,
+			frames: [][]testFrame{
+				every("can0", engineID, 0, 100, 200),
+				{{bus: "can0", id: engineID, at: 150, remote: true}},
+				every("can0", brakeID, 0, 100, 200),
+			},
+		},
+		{
+			name:        "trailing timeout",
+			defaultFile: dbcFile,
+			frames: [][]testFrame{
+				every("can0", engineID, 0, 100),
+				every("can0", brakeID, 0, 100, 200, 300, 400, 500),
+			},
+			wantFinish: []testViolation{
+				{kind: KindTimeout, bus: "can0", message: "ENGINE", at: 500 * time.Millisecond, gap: 400 * time.Millisecond},
+			},
+		},
+		{
+			name:        "missing",
+			defaultFile: dbcFile,
+			frames: [][]testFrame{
+				every("can0", engineID, 0, 100, 200),
+			},
+			wantFinish: []testViolation{
+				{kind: KindMissing, bus: "can0", message: "BRAKE"},
+			},
+		},
+		{
+			name:        "default DBC is not expected on a bus without its frames",
+			defaultFile: dbcFile,
+			frames: [][]testFrame{
+				every("can0", engineID, 0, 100, 200),
+				every("can1", unknownID, 50, 150),
+			},
+			wantFinish: []testViolation{
+				{kind: KindMissing, bus: "can0", message: "BRAKE"},
+			},
+		},
+		{
+			name:     "explicitly mapped bus",
+			mappings: []string{"can1=" + dbcFile},
+			frames: [][]testFrame{
+				every("can0", engineID, 0, 100, 200),
+				every("can1", unknownID, 50, 150),
+			},
+			wantFinish: []testViolation{
+				{kind: KindMissing, bus: "can1", message: "ENGINE", at: 50 * time.Millisecond},
+				{kind: KindMissing, bus: "can1", message: "BRAKE", at: 50 * time.Millisecond},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder, _, err := dbc.LoadDecoder(tt.defaultFile, tt.mappings)
+			if err != nil {
+				t.Fatalf("LoadDecoder() error = %v", err)
+			}
+			var (
+				c      = NewChecker(decoder, tt.opts...)
+				start  = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+				frames = slices.Concat(tt.frames...)
+			)
+			slices.SortStableFunc(frames, func(a, b testFrame) int { return a.at - b.at })
+
+			var observed []Violation
+			for _, f := range frames {
+				observed = append(observed, c.Observe(&can.TimedFrame{
+					Frame:     can.Frame{ID: f.id, Length: 8, IsRemote: f.remote},
+					Timestamp: start.Add(time.Duration(f.at) * time.Millisecond),
+					Interface: f.bus,
+				})...)
+			}
+			if got := summarize(start, observed); !slices.Equal(got, tt.wantObserve) {
+				t.Errorf("Observe() violations = %+v, want %+v", got, tt.wantObserve)
+			}
+			if got := summarize(start, c.Finish()); !slices.Equal(got, tt.wantFinish) {
+				t.Errorf("Finish() violations = %+v, want %+v", got, tt.wantFinish)
+			}
+		})
+	}
+}
+
+func summarize(start time.Time, violations []Violation) []testViolation {
+	var summary []testViolation
+	for _, v := range violations {
+		summary = append(summary, testViolation{
+			kind:    v.Kind,
+			bus:     v.Bus,
+			message: v.Message.Name,
+			at:      v.Timestamp.Sub(start),
+			gap:     v.Gap,
+		})
+	}
+	return summary
+}
@@ -0,0 +1,19 @@
+package version
+
+import "runtime/debug"
+
+// Version is the candecode version, set at build time with
+// -ldflags "-X github.com/BIwashi/candecode/pkg/version.Version=<version>".
+var Version = ""
+
+// String returns the build time version, falling back to the module version recorded by
+// go install, or "dev" for local builds.
+func String() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}